@@ -0,0 +1,17 @@
+// Package main contains actions for building and running the remote agent
+// which runs the commands of the scripts hub on its host.
+package main
+
+import (
+	"github.com/pavlegich/scripts-hub/internal/app"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"go.uber.org/zap"
+)
+
+func main() {
+	if err := app.RunAgent(); err != nil {
+		logger.Log.Error("main: run agent failed",
+			zap.Error(err))
+	}
+	logger.Log.Info("quit")
+}
@@ -0,0 +1,22 @@
+// Package main contains the supervisor of the detached command run,
+// it is started by the server with the spool directory of the run.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pavlegich/scripts-hub/internal/infra/supervisor"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s <spool directory>\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	if err := supervisor.Run(os.Args[1]); err != nil {
+		fmt.Fprintf(os.Stderr, "main: supervise run failed %s\n", err)
+		os.Exit(1)
+	}
+}
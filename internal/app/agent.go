@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/infra/runner"
+	"go.uber.org/zap"
+)
+
+// RunAgent initializes the remote agent components and runs the agent
+// until the shutdown signal.
+func RunAgent() error {
+	// Context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt,
+		syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer stop()
+
+	// Logger
+	err := logger.Init(ctx, "Info")
+	if err != nil {
+		return fmt.Errorf("RunAgent: logger initialization failed %w", err)
+	}
+	defer logger.Log.Sync()
+
+	// Configuration
+	cfg := config.NewAgentConfig(ctx)
+	err = cfg.ParseFlags(ctx)
+	if err != nil {
+		return fmt.Errorf("RunAgent: parse flags failed %w", err)
+	}
+
+	logger.Log.Info("running agent", zap.String("hub_url", cfg.HubURL),
+		zap.String("agent_name", cfg.Name), zap.Strings("labels", cfg.Labels), zap.Int("capacity", cfg.Capacity))
+
+	err = runner.NewRunner(ctx, cfg).Run(ctx)
+	if err != nil {
+		return fmt.Errorf("RunAgent: %w", err)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -13,11 +14,12 @@ import (
 	_ "github.com/golang/mock/mockgen/model"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
-	"github.com/pavlegich/scripts-hub/internal/entities"
 	"github.com/pavlegich/scripts-hub/internal/infra/config"
 	"github.com/pavlegich/scripts-hub/internal/infra/database"
 	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/infra/tracing"
 	"github.com/pavlegich/scripts-hub/internal/repository"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
 	_ "go.uber.org/automaxprocs"
 	"go.uber.org/zap"
 )
@@ -43,6 +45,19 @@ func Run() error {
 		return fmt.Errorf("Run: parse flags failed %w", err)
 	}
 
+	// Tracing
+	err = tracing.Init(ctx, cfg.TraceEndpoint, cfg.TraceFile, cfg.TraceInterval)
+	if err != nil {
+		return fmt.Errorf("Run: tracing initialization failed %w", err)
+	}
+	defer func() {
+		err := tracing.Shutdown(context.Background())
+		if err != nil {
+			logger.Log.Error("Run: export remaining spans failed",
+				zap.Error(err))
+		}
+	}()
+
 	// Database
 	db, err := database.Init(ctx, cfg.DSN)
 	if err != nil {
@@ -52,10 +67,10 @@ func Run() error {
 
 	// Router
 	ctrl := handlers.NewController(ctx, cfg)
-	repo := repository.NewCommandRepository(ctx, db)
-	runCmdChan := make(chan entities.Command)
+	repo := repository.NewTracedRepository(repository.NewCommandRepository(ctx, db))
+	queues := queue.NewManager(ctx, cfg)
 
-	router, err := ctrl.BuildRoute(ctx, repo, runCmdChan)
+	router, err := ctrl.BuildRoute(ctx, repo, queues)
 	if err != nil {
 		return fmt.Errorf("Run: build server route failed %w", err)
 	}
@@ -67,26 +82,44 @@ func Run() error {
 	}
 
 	// Server graceful shutdown
+	stopped := make(chan struct{})
 	go func() {
+		defer close(stopped)
+
 		<-ctx.Done()
 		if ctx.Err() != nil {
-			ctxShutdown, cancelShutdown := context.WithTimeout(ctx, 5*time.Second)
-			defer cancelShutdown()
-
 			logger.Log.Info("shutting down gracefully...",
 				zap.Error(ctx.Err()))
 
-			close(runCmdChan)
+			ctxDrain, cancelDrain := context.WithTimeout(context.Background(), cfg.DrainTimeout)
+			defer cancelDrain()
+
+			// Readiness fails first, so the load balancers drain the traffic
+			// while the server still serves the requests. The new runs are refused
+			// and the waiting ones are kept for the next start.
+			ctrl.Drain(ctx)
+			time.Sleep(cfg.ShutdownDelay)
+
+			ctxShutdown, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancelShutdown()
 
 			err := srv.Shutdown(ctxShutdown)
 			if err != nil {
 				logger.Log.Error("server shutdown failed",
 					zap.Error(err))
 			}
+
+			ctrl.Stop(ctxDrain)
 		}
 	}()
 
 	logger.Log.Info("running server", zap.String("addr", srv.Addr))
 
-	return srv.ListenAndServe()
+	err = srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		<-stopped
+		return nil
+	}
+
+	return err
 }
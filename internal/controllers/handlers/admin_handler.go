@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"go.uber.org/zap"
+)
+
+// adminTokenHeader is the header of the admin request containing the admin token.
+const adminTokenHeader = "X-Admin-Token"
+
+// AdminHandler contains objects for work with the runtime settings handlers.
+type AdminHandler struct {
+	commands *CommandHandler
+	Config   *config.Config
+}
+
+// adminActivate activates handler for the runtime settings of the server.
+func adminActivate(ctx context.Context, r *http.ServeMux, cfg *config.Config, commands *CommandHandler) {
+	h := &AdminHandler{
+		commands: commands,
+		Config:   cfg,
+	}
+
+	r.HandleFunc("/admin", h.HandleSettings)
+	r.HandleFunc("/admin/log-level", h.HandleLogLevel)
+	r.HandleFunc("/admin/workers", h.HandleWorkers)
+	r.HandleFunc("/admin/maintenance", h.HandleMaintenance)
+}
+
+// HandleSettings handles request to get the current runtime settings of the server.
+func (h *AdminHandler) HandleSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandleSettings: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	h.writeSettings(w)
+}
+
+// HandleLogLevel handles request to change the level of the server logger.
+func (h *AdminHandler) HandleLogLevel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		logger.Log.Error("HandleLogLevel: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	var req entities.LogLevel
+	err := decodeAdminRequest(r, &req)
+	if err != nil {
+		logger.Log.Error("HandleLogLevel: read request failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = logger.SetLevel(req.Level)
+	if err != nil {
+		logger.Log.Error("HandleLogLevel: incorrect level",
+			zap.Error(err), zap.String("level", req.Level))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	logger.Log.Info("HandleLogLevel: log level changed",
+		zap.String("level", logger.Level().String()))
+
+	h.writeSettings(w)
+}
+
+// HandleWorkers handles request to change the number of the workers
+// of the queue specified by name.
+func (h *AdminHandler) HandleWorkers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		logger.Log.Error("HandleWorkers: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	name, err := queryValue(r, "name", true)
+	if err != nil {
+		logger.Log.Error("HandleWorkers: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var req entities.WorkersLimit
+	err = decodeAdminRequest(r, &req)
+	if err != nil {
+		logger.Log.Error("HandleWorkers: read request failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = h.commands.ResizeWorkers(name, req.Workers)
+	if err != nil {
+		logger.Log.Error("HandleWorkers: resize workers failed",
+			zap.Error(err), zap.String("queue", name), zap.Int("workers", req.Workers))
+
+		switch {
+		case errors.Is(err, errs.ErrQueueNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, errs.ErrWorkersIncorrect):
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+	logger.Log.Info("HandleWorkers: queue workers changed",
+		zap.String("queue", name), zap.Int("workers", req.Workers))
+
+	h.writeSettings(w)
+}
+
+// HandleMaintenance handles request to turn the maintenance mode on or off.
+func (h *AdminHandler) HandleMaintenance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		logger.Log.Error("HandleMaintenance: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	var req entities.MaintenanceMode
+	err := decodeAdminRequest(r, &req)
+	if err != nil {
+		logger.Log.Error("HandleMaintenance: read request failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	h.commands.SetMaintenance(req.Enabled)
+	logger.Log.Info("HandleMaintenance: maintenance mode changed",
+		zap.Bool("enabled", req.Enabled))
+
+	h.writeSettings(w)
+}
+
+// authorize checks the admin token of the request and writes the error
+// response if the request is not allowed. The admin API is disabled
+// without the configured token.
+func (h *AdminHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if h.Config.AdminToken == "" {
+		logger.Log.Error("authorize: admin request refused",
+			zap.Error(errs.ErrAdminDisabled))
+
+		w.WriteHeader(http.StatusForbidden)
+		return false
+	}
+
+	token := r.Header.Get(adminTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.Config.AdminToken)) != 1 {
+		logger.Log.Error("authorize: admin request refused",
+			zap.Error(errs.ErrAdminUnauthorized))
+
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+
+	return true
+}
+
+// writeSettings writes the response with the current runtime settings.
+func (h *AdminHandler) writeSettings(w http.ResponseWriter) {
+	settings := entities.AdminSettings{
+		LogLevel:    logger.Level().String(),
+		Maintenance: h.commands.Maintenance(),
+		Workers:     make(map[string]int),
+	}
+	if h.commands.queues != nil {
+		for _, s := range h.commands.queues.Stats() {
+			settings.Workers[s.Name] = s.Workers
+		}
+	}
+
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		logger.Log.Error("writeSettings: marshal settings failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(settingsJSON)
+}
+
+// decodeAdminRequest reads the body of the admin request into the value.
+func decodeAdminRequest(r *http.Request, v any) error {
+	var buf bytes.Buffer
+
+	_, err := buf.ReadFrom(r.Body)
+	if err != nil {
+		return fmt.Errorf("decodeAdminRequest: read request body failed %w", err)
+	}
+	defer r.Body.Close()
+
+	err = json.Unmarshal(buf.Bytes(), v)
+	if err != nil {
+		return fmt.Errorf("decodeAdminRequest: request unmarshal failed %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer logger.SetLevel("info")
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:    `localhost:8080`,
+		RateLimit:  1,
+		AdminToken: `secret`,
+	}
+
+	// Controller
+	ctrl := handlers.NewController(ctx, cfg)
+	queues := queue.NewManager(ctx, cfg)
+	mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+	require.NoError(t, err)
+
+	q, _ := queues.Get(queue.DefaultQueue)
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		token       string
+		reqBody     string
+		wantCode    int
+		wantBody    string
+		wantWorkers int
+	}{
+		{
+			name:     "settings",
+			method:   http.MethodGet,
+			target:   `/admin`,
+			token:    `secret`,
+			wantCode: http.StatusOK,
+			wantBody: `{"log_level": "info", "maintenance": false, "workers": {"default": 1}}`,
+		},
+		{
+			name:     "unauthorized",
+			method:   http.MethodGet,
+			target:   `/admin`,
+			token:    `wrong`,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "incorrect_method",
+			method:   http.MethodPost,
+			target:   `/admin/maintenance`,
+			token:    `secret`,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:     "log_level",
+			method:   http.MethodPut,
+			target:   `/admin/log-level`,
+			token:    `secret`,
+			reqBody:  `{"level": "debug"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"log_level": "debug", "maintenance": false, "workers": {"default": 1}}`,
+		},
+		{
+			name:     "incorrect_log_level",
+			method:   http.MethodPut,
+			target:   `/admin/log-level`,
+			token:    `secret`,
+			reqBody:  `{"level": "verbose"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:        "grow_workers",
+			method:      http.MethodPut,
+			target:      `/admin/workers?name=default`,
+			token:       `secret`,
+			reqBody:     `{"workers": 3}`,
+			wantCode:    http.StatusOK,
+			wantBody:    `{"log_level": "debug", "maintenance": false, "workers": {"default": 3}}`,
+			wantWorkers: 3,
+		},
+		{
+			name:        "shrink_workers",
+			method:      http.MethodPut,
+			target:      `/admin/workers?name=default`,
+			token:       `secret`,
+			reqBody:     `{"workers": 2}`,
+			wantCode:    http.StatusOK,
+			wantBody:    `{"log_level": "debug", "maintenance": false, "workers": {"default": 2}}`,
+			wantWorkers: 2,
+		},
+		{
+			name:     "incorrect_workers",
+			method:   http.MethodPut,
+			target:   `/admin/workers?name=default`,
+			token:    `secret`,
+			reqBody:  `{"workers": 0}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "queue_not_found",
+			method:   http.MethodPut,
+			target:   `/admin/workers?name=heavy`,
+			token:    `secret`,
+			reqBody:  `{"workers": 2}`,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "maintenance",
+			method:   http.MethodPut,
+			target:   `/admin/maintenance`,
+			token:    `secret`,
+			reqBody:  `{"enabled": true}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"log_level": "debug", "maintenance": true, "workers": {"default": 2}}`,
+		},
+		{
+			name:     "maintenance_refuses_runs",
+			method:   http.MethodPost,
+			target:   `/command`,
+			reqBody:  `{"name": "pwd", "script": "pwd"}`,
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:     "maintenance_keeps_reads",
+			method:   http.MethodGet,
+			target:   `/queues`,
+			wantCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, `http://`+cfg.Address+tt.target, bytes.NewBufferString(tt.reqBody))
+			if tt.token != "" {
+				r.Header.Set("X-Admin-Token", tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				require.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+			if tt.wantWorkers > 0 {
+				require.Eventually(t, func() bool {
+					return q.Attached() == tt.wantWorkers
+				}, time.Second, 10*time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_Disabled(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 1,
+	}
+
+	// Controller
+	ctrl := handlers.NewController(ctx, cfg)
+	mh, err := ctrl.BuildRoute(ctx, nil, nil)
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPut, `http://`+cfg.Address+`/admin/maintenance`, bytes.NewBufferString(`{"enabled": true}`))
+	r.Header.Set("X-Admin-Token", "")
+	w := httptest.NewRecorder()
+
+	mh.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/service/agent"
+	"go.uber.org/zap"
+)
+
+// maxAgentOutput is the maximum size of the output chunk sent by the agent.
+const maxAgentOutput = 1 << 20
+
+// AgentHandler contains objects for work with agent handlers.
+type AgentHandler struct {
+	Config  *config.Config
+	Service agent.Service
+}
+
+// agentsActivate activates handler for agent object and returns
+// the agent service dispatching the command runs to the agents.
+func agentsActivate(ctx context.Context, r *http.ServeMux, cfg *config.Config) agent.Service {
+	s := agent.NewAgentService(ctx, cfg.AgentToken, cfg.AgentTimeout)
+	if cfg.AgentToken == "" {
+		logger.Log.Warn("agentsActivate: remote agents are disabled, agent token is not set")
+	}
+	newAgentHandler(ctx, r, cfg, s)
+	return s
+}
+
+// newAgentHandler initializes handler for agent object and starts
+// removing the lost agents if the agent timeout is set.
+func newAgentHandler(ctx context.Context, r *http.ServeMux, cfg *config.Config, s agent.Service) {
+	h := &AgentHandler{
+		Config:  cfg,
+		Service: s,
+	}
+
+	r.HandleFunc("/agents", h.HandleAgents)
+	r.HandleFunc("/agents/poll", h.HandlePollAgent)
+	r.HandleFunc("/agents/output", h.HandleAgentOutput)
+	r.HandleFunc("/agents/exit", h.HandleAgentExit)
+
+	if cfg.AgentTimeout > 0 {
+		go s.RunExpiry(ctx, cfg.AgentTimeout/2)
+	}
+}
+
+// HandleAgents handles request to register the agent or get the agents list.
+func (h *AgentHandler) HandleAgents(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.HandleRegisterAgent(w, r)
+	case http.MethodGet:
+		h.HandleListAgents(w, r)
+	default:
+		logger.Log.Error("HandleAgents: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleRegisterAgent handles request to register new agent with its labels
+// and capacity, the response contains the identifier of the agent.
+func (h *AgentHandler) HandleRegisterAgent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	err := h.Service.Authorize(r.Header.Get(agent.TokenHeader))
+	if err != nil {
+		logger.Log.Error("HandleRegisterAgent: authorize agent failed",
+			zap.Error(err))
+
+		writeUnauthorizedAgent(w, err)
+		return
+	}
+
+	var req entities.Agent
+	var buf bytes.Buffer
+
+	_, err = buf.ReadFrom(r.Body)
+	if err != nil {
+		logger.Log.Error("HandleRegisterAgent: read request body failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = json.Unmarshal(buf.Bytes(), &req)
+	if err != nil {
+		logger.Log.Error("HandleRegisterAgent: request unmarshal failed",
+			zap.String("body", buf.String()),
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	a, err := h.Service.Register(ctx, &req)
+	if err != nil {
+		logger.Log.Error("HandleRegisterAgent: register agent failed",
+			zap.Error(err), zap.String("agent_name", req.Name))
+
+		if errors.Is(err, errs.ErrAgentIncorrect) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	agentJSON, err := json.Marshal(a)
+	if err != nil {
+		logger.Log.Error("HandleRegisterAgent: marshal agent failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(agentJSON)
+}
+
+// HandleListAgents handles request to get the registered agents.
+func (h *AgentHandler) HandleListAgents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	agents := h.Service.List(ctx)
+
+	agentsJSON, err := json.Marshal(agents)
+	if err != nil {
+		logger.Log.Error("HandleListAgents: marshal agents failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(agentsJSON)
+}
+
+// HandlePollAgent handles request of the agent waiting for the jobs and the signals,
+// the request without them is responded with 204 after the poll wait.
+// The unknown agent is responded with 404 and has to register again.
+func (h *AgentHandler) HandlePollAgent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandlePollAgent: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	id, ok := h.authorize(w, r, "HandlePollAgent")
+	if !ok {
+		return
+	}
+
+	poll, err := h.Service.Poll(ctx, id, agent.PollWait)
+	if err != nil {
+		logger.Log.Error("HandlePollAgent: poll agent failed",
+			zap.Error(err), zap.String("agent_id", id))
+
+		if errors.Is(err, errs.ErrAgentNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if len(poll.Jobs) == 0 && len(poll.Signals) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	pollJSON, err := json.Marshal(poll)
+	if err != nil {
+		logger.Log.Error("HandlePollAgent: marshal poll failed",
+			zap.Error(err), zap.String("agent_id", id))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(pollJSON)
+}
+
+// HandleAgentOutput handles request of the agent appending the output chunk
+// of the command to the run by its identifier.
+func (h *AgentHandler) HandleAgentOutput(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logger.Log.Error("HandleAgentOutput: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := h.authorize(w, r, "HandleAgentOutput")
+	if !ok {
+		return
+	}
+
+	runID, err := queryID(r)
+	if err != nil {
+		logger.Log.Error("HandleAgentOutput: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAgentOutput))
+	if err != nil {
+		logger.Log.Error("HandleAgentOutput: read request body failed",
+			zap.Error(err), zap.Int("run_id", runID))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = h.Service.Output(id, runID, data)
+	if err != nil {
+		logger.Log.Error("HandleAgentOutput: append output failed",
+			zap.Error(err), zap.String("agent_id", id), zap.Int("run_id", runID))
+
+		if errors.Is(err, errs.ErrRunNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleAgentExit handles request of the agent reporting the exit status
+// of the command of the run.
+func (h *AgentHandler) HandleAgentExit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logger.Log.Error("HandleAgentExit: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := h.authorize(w, r, "HandleAgentExit")
+	if !ok {
+		return
+	}
+
+	var req entities.AgentExit
+	var buf bytes.Buffer
+
+	_, err := buf.ReadFrom(r.Body)
+	if err != nil {
+		logger.Log.Error("HandleAgentExit: read request body failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = json.Unmarshal(buf.Bytes(), &req)
+	if err != nil {
+		logger.Log.Error("HandleAgentExit: request unmarshal failed",
+			zap.String("body", buf.String()),
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = h.Service.Exit(id, &req)
+	if err != nil {
+		logger.Log.Error("HandleAgentExit: complete run failed",
+			zap.Error(err), zap.String("agent_id", id), zap.Int("run_id", req.RunID))
+
+		if errors.Is(err, errs.ErrRunNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// authorize checks the token of the agent request and returns the agent identifier.
+// It writes the error response and returns false if the request is not authorized.
+func (h *AgentHandler) authorize(w http.ResponseWriter, r *http.Request, handler string) (string, bool) {
+	err := h.Service.Authorize(r.Header.Get(agent.TokenHeader))
+	if err != nil {
+		logger.Log.Error(handler+": authorize agent failed",
+			zap.Error(err))
+
+		writeUnauthorizedAgent(w, err)
+		return "", false
+	}
+
+	id := r.Header.Get(agent.IDHeader)
+	if id == "" {
+		logger.Log.Error(handler + ": agent id header not found")
+
+		w.WriteHeader(http.StatusBadRequest)
+		return "", false
+	}
+
+	return id, true
+}
+
+// writeUnauthorizedAgent writes the response for the agent request which is not authorized.
+func writeUnauthorizedAgent(w http.ResponseWriter, err error) {
+	if errors.Is(err, errs.ErrAgentsDisabled) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	w.WriteHeader(http.StatusUnauthorized)
+}
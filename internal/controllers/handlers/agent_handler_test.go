@@ -0,0 +1,248 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/runner"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/agent"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentHandler_HandleRegisterAgent(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:      `localhost:8080`,
+		RateLimit:    1,
+		AgentToken:   "secret",
+		AgentTimeout: time.Minute,
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		disabled bool
+		reqBody  string
+		wantCode int
+	}{
+		{
+			name:     "success",
+			token:    "secret",
+			reqBody:  `{"name": "build-1", "labels": ["linux"], "capacity": 2}`,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "incorrect_token",
+			token:    "wrong",
+			reqBody:  `{"name": "build-1", "labels": ["linux"], "capacity": 2}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "agents_disabled",
+			disabled: true,
+			reqBody:  `{"name": "build-1", "labels": ["linux"], "capacity": 2}`,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "incorrect_body",
+			token:    "secret",
+			reqBody:  `{{"name": "build-1"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "zero_capacity",
+			token:    "secret",
+			reqBody:  `{"name": "build-1"}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Controller without the agent token refuses the agents
+			cfg := *cfg
+			if tt.disabled {
+				cfg.AgentToken = ""
+			}
+			ctrl := handlers.NewController(ctx, &cfg)
+			queues := queue.NewManager(ctx, &cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/agents`
+
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(tt.reqBody))
+			r.Header.Set(agent.TokenHeader, tt.token)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Check status code
+			resp := w.Result()
+			defer resp.Body.Close()
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if tt.wantCode != http.StatusCreated {
+				return
+			}
+
+			// Check the registered agent
+			var got entities.Agent
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+			require.NotEmpty(t, got.ID)
+			require.Equal(t, "build-1", got.Name)
+			require.Equal(t, []string{"linux"}, got.Labels)
+			require.Equal(t, 2, got.Capacity)
+		})
+	}
+}
+
+func TestAgentHandler_RemoteRun(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:      `localhost:8080`,
+		RateLimit:    1,
+		AgentToken:   "secret",
+		AgentTimeout: time.Minute,
+	}
+
+	tests := []struct {
+		name       string
+		reqBody    string
+		cancel     bool
+		wantStatus string
+		wantOutput string
+	}{
+		{
+			name:       "succeeded",
+			reqBody:    `{"name": "remote", "script": "echo remote", "labels": ["local"]}`,
+			wantStatus: entities.RunSucceeded,
+			wantOutput: "remote\n",
+		},
+		{
+			name:       "cancelled",
+			reqBody:    `{"name": "remote", "script": "sleep 10", "labels": ["local"]}`,
+			cancel:     true,
+			wantStatus: entities.RunCancelled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var output string
+			started := make(chan struct{}, 1)
+			finished := make(chan *entities.Run, 1)
+
+			// Mocks expected response
+			mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, c *entities.Command) (*entities.Command, error) {
+					require.Equal(t, []string{"local"}, c.Labels)
+					c.ID = 1
+					return c, nil
+				}).Times(1)
+			mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+				Return(&entities.Run{ID: 1, CommandID: 1, Name: "remote", Status: entities.RunQueued}, nil).Times(1)
+			mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+					started <- struct{}{}
+					return nil
+				}).Times(1)
+			mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+					mu.Lock()
+					defer mu.Unlock()
+					output += run.Output
+					return nil
+				}).AnyTimes()
+			mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+					finished <- run
+					return nil
+				}).Times(1)
+			mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil, nil).AnyTimes()
+
+			// Controller served on localhost
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			srv := httptest.NewServer(mh)
+			defer srv.Close()
+
+			// Agent connected to the server
+			ctxAgent, stopAgent := context.WithCancel(ctx)
+			stopped := make(chan struct{})
+			go func() {
+				runner.NewRunner(ctxAgent, &config.AgentConfig{
+					HubURL:   srv.URL,
+					Name:     "local",
+					Labels:   config.LabelList{"local"},
+					Capacity: 1,
+					Token:    "secret",
+				}).Run(ctxAgent)
+				close(stopped)
+			}()
+			defer func() {
+				stopAgent()
+				<-stopped
+			}()
+
+			// Submit the command
+			resp, err := http.Post(srv.URL+"/command", "application/json", bytes.NewBufferString(tt.reqBody))
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			if tt.cancel {
+				select {
+				case <-started:
+				case <-time.After(5 * time.Second):
+					t.Fatal("run is not started")
+				}
+
+				req, err := http.NewRequest(http.MethodDelete, srv.URL+"/run?id=1", nil)
+				require.NoError(t, err)
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				resp.Body.Close()
+				require.Equal(t, http.StatusNoContent, resp.StatusCode)
+			}
+
+			// Check the status and the output sent by the agent
+			select {
+			case run := <-finished:
+				require.Equal(t, tt.wantStatus, run.Status)
+			case <-time.After(5 * time.Second):
+				t.Fatal("run is not finished")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			require.Equal(t, tt.wantOutput, output)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+)
+
+// workerPool contains the stop functions of the running workers of every queue,
+// so the number of the workers is changed without the server restart.
+type workerPool struct {
+	ctx   context.Context
+	mu    sync.Mutex
+	stops map[string][]context.CancelFunc
+}
+
+// newWorkerPool returns new pool of the workers stopped when the context is done.
+func newWorkerPool(ctx context.Context) *workerPool {
+	return &workerPool{
+		ctx:   ctx,
+		stops: make(map[string][]context.CancelFunc),
+	}
+}
+
+// startWorkers starts the specified number of the workers of the queue,
+// the pool mutex must be held.
+func (h *CommandHandler) startWorkers(q *queue.Queue, n int) {
+	for w := 0; w < n; w++ {
+		stop, cancel := context.WithCancel(h.pool.ctx)
+		h.pool.stops[q.Name()] = append(h.pool.stops[q.Name()], cancel)
+		go h.runWorker(h.pool.ctx, stop, q)
+	}
+}
+
+// ResizeWorkers changes the number of the workers serving the queue.
+// The added workers take the waiting jobs at once, the removed ones
+// stop after finishing their current jobs.
+func (h *CommandHandler) ResizeWorkers(name string, workers int) error {
+	if workers < 1 {
+		return fmt.Errorf("ResizeWorkers: %d %w", workers, errs.ErrWorkersIncorrect)
+	}
+
+	if h.queues == nil {
+		return fmt.Errorf("ResizeWorkers: queue %s %w", name, errs.ErrQueueNotFound)
+	}
+	q, ok := h.queues.Get(name)
+	if !ok {
+		return fmt.Errorf("ResizeWorkers: queue %s %w", name, errs.ErrQueueNotFound)
+	}
+
+	h.pool.mu.Lock()
+	defer h.pool.mu.Unlock()
+
+	stops := h.pool.stops[name]
+	switch {
+	case workers > len(stops):
+		h.startWorkers(q, workers-len(stops))
+	case workers < len(stops):
+		for _, stop := range stops[workers:] {
+			stop()
+		}
+		h.pool.stops[name] = stops[:workers]
+	}
+	q.SetWorkers(workers)
+
+	return nil
+}
+
+// SetMaintenance turns the maintenance mode on or off. In the maintenance mode
+// the new runs are refused and the due delayed runs wait, the active runs
+// are not affected and the reads are available.
+func (h *CommandHandler) SetMaintenance(on bool) {
+	h.maintenance.Store(on)
+}
+
+// Maintenance reports whether the server is in the maintenance mode.
+func (h *CommandHandler) Maintenance() bool {
+	return h.maintenance.Load()
+}
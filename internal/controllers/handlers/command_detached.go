@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/infra/supervisor"
+	"github.com/pavlegich/scripts-hub/internal/infra/tracing"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"go.uber.org/zap"
+)
+
+// detachedInterval is the interval of copying the output of the detached command
+// from its spool file into the storage.
+const detachedInterval = 200 * time.Millisecond
+
+// runDetached starts the command of the run under the supervisor and follows
+// its output until the command exits. When the context is done, only the following
+// is stopped, the command keeps running and the next server start attaches to it.
+func (h *CommandHandler) runDetached(ctx context.Context, ar *activeRun, j *queue.Job, span *tracing.Span, bashCmd []string) {
+	env := append(os.Environ(), ar.env...)
+	spec := &supervisor.Spec{
+		RunID: ar.run.ID,
+		Path:  bashCmd[0],
+		Args:  bashCmd[1:],
+		Env:   append(env, "TRACEPARENT="+span.TraceParent()),
+		Input: ar.input,
+	}
+
+	shim, err := h.startDetached(ctx, ar, spec)
+	span.SetAttributes(tracing.Int("attempt", ar.run.Attempt))
+	if err != nil {
+		span.SetError(err)
+		runLogger(ar.run).Error("runDetached: start run failed",
+			zap.Error(err), zap.String("cmd", j.Command.Script))
+
+		status := entities.RunFailed
+		if errors.Is(err, errs.ErrRunCancelled) {
+			status = entities.RunCancelled
+		}
+		h.finishRun(context.Background(), ar, status, nil)
+		return
+	}
+
+	h.followDetached(ctx, ar, j, shim, 0, span)
+}
+
+// startDetached marks the run as running its next attempt and starts
+// the supervisor of the command unless the run has been already cancelled.
+func (h *CommandHandler) startDetached(ctx context.Context, ar *activeRun, spec *supervisor.Spec) (*supervisor.Handle, error) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if ar.cancelled {
+		return nil, fmt.Errorf("startDetached: %w", errs.ErrRunCancelled)
+	}
+
+	err := h.Service.StartRun(ctx, ar.run)
+	if err != nil {
+		return nil, fmt.Errorf("startDetached: mark run as running failed %w", err)
+	}
+
+	path := h.Config.ShimPath
+	if path == "" {
+		path = supervisor.DefaultPath()
+	}
+
+	shim, err := supervisor.Start(path, h.spoolDir(ar.run.ID), spec)
+	if err != nil {
+		return nil, fmt.Errorf("startDetached: %w", err)
+	}
+	ar.shim = shim
+
+	h.notify(ar.run, ar.hooks)
+
+	return shim, nil
+}
+
+// followDetached copies the output of the detached command written after the offset
+// into the storage until the command exits, then removes its spool directory
+// and stores the result of the attempt.
+func (h *CommandHandler) followDetached(ctx context.Context, ar *activeRun, j *queue.Job,
+	shim *supervisor.Handle, offset int64, span *tracing.Span) {
+	exit, err := shim.Follow(ctx, offset, NewCommandWriter(ctx, ar.run, h.Service), detachedInterval)
+	if err != nil {
+		if ctx.Err() != nil {
+			runLogger(ar.run).Info("followDetached: server is stopping, detached command keeps running")
+			return
+		}
+
+		runLogger(ar.run).Error("followDetached: follow detached command failed",
+			zap.Error(err))
+
+		// The spool directory of the lost supervisor is not attached again.
+		if errors.Is(err, supervisor.ErrSupervisorLost) {
+			rerr := shim.Remove()
+			if rerr != nil {
+				runLogger(ar.run).Error("followDetached: remove spool directory failed",
+					zap.Error(rerr))
+			}
+		}
+
+		span.SetError(err)
+		h.finishRun(context.Background(), ar, entities.RunFailed, nil)
+		return
+	}
+
+	err = shim.Remove()
+	if err != nil {
+		runLogger(ar.run).Error("followDetached: remove spool directory failed",
+			zap.Error(err))
+	}
+
+	var exitErr error
+	if exit.ExitCode != 0 {
+		exitErr = fmt.Errorf("followDetached: exit status %d", exit.ExitCode)
+	}
+	span.SetAttributes(tracing.Int("exit_code", exit.ExitCode))
+	span.SetError(exitErr)
+
+	h.completeRun(ar, j, exit.ExitCode, exitErr)
+}
+
+// Reattach attaches to the detached commands which supervisors were started
+// by the previous server and follows them until the commands exit.
+// The output written while the server was stopped is copied from the spool files.
+func (h *CommandHandler) Reattach(ctx context.Context) {
+	entries, err := os.ReadDir(h.Config.SpoolDir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Log.Error("Reattach: read spool directory failed",
+				zap.Error(err), zap.String("dir", h.Config.SpoolDir))
+		}
+		return
+	}
+
+	for _, e := range entries {
+		id, err := strconv.Atoi(e.Name())
+		if err != nil || !e.IsDir() {
+			continue
+		}
+
+		err = h.reattachRun(ctx, id)
+		if err != nil {
+			logger.Log.Error("Reattach: attach detached run failed",
+				zap.Error(err), zap.Int("run_id", id))
+		}
+	}
+}
+
+// reattachRun makes the detached run active again and starts following its command.
+func (h *CommandHandler) reattachRun(ctx context.Context, id int) error {
+	run, err := h.Service.UnloadRun(ctx, id)
+	if err != nil {
+		return fmt.Errorf("reattachRun: get run failed %w", err)
+	}
+	if run.Status != entities.RunRunning {
+		return fmt.Errorf("reattachRun: run is %s %w", run.Status, errs.ErrRunNotFound)
+	}
+
+	shim, err := supervisor.Attach(h.spoolDir(id))
+	if err != nil {
+		return fmt.Errorf("reattachRun: %w", err)
+	}
+
+	c, err := h.Service.Unload(ctx, run.Name)
+	if err != nil {
+		runLogger(run).Warn("reattachRun: command not found, retry policy and webhooks are not applied",
+			zap.Error(err))
+
+		c = &entities.Command{ID: run.CommandID, Name: run.Name, Detached: true}
+	}
+
+	ar := &activeRun{
+		run:        run,
+		done:       make(chan struct{}),
+		hooks:      c.Webhooks,
+		restorable: true,
+		shim:       shim,
+	}
+	h.procs.Store(run.ID, ar)
+
+	if shim.Lost() {
+		runLogger(run).Warn("reattachRun: supervisor of detached run is lost, run fails after its output is copied")
+	} else {
+		runLogger(run).Info("reattachRun: detached run attached",
+			zap.Int("offset", len(run.Output)))
+	}
+
+	go func() {
+		ctx, span := tracing.Start(ctx, "run "+c.Name, tracing.KindInternal,
+			tracing.String("cmd_name", c.Name), tracing.Int("run_id", run.ID), tracing.Int("attempt", run.Attempt))
+		defer span.End()
+
+		h.followDetached(ctx, ar, &queue.Job{ID: run.ID, Command: *c}, shim, int64(len(run.Output)), span)
+	}()
+
+	return nil
+}
+
+// spoolDir returns the spool directory of the detached run.
+func (h *CommandHandler) spoolDir(id int) string {
+	return filepath.Join(h.Config.SpoolDir, strconv.Itoa(id))
+}
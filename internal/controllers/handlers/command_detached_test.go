@@ -0,0 +1,252 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/supervisor"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+// shimEnv is set for the test binary started as the supervisor of the detached command.
+const shimEnv = "SCRIPTS_HUB_TEST_SHIM"
+
+// TestMain runs the test binary as the supervisor when it is started by the handler.
+func TestMain(m *testing.M) {
+	if os.Getenv(shimEnv) == "1" && len(os.Args) == 2 {
+		if err := supervisor.Run(os.Args[1]); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestCommandHandler_HandleCreateCommandDetached(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv(shimEnv, "1")
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 1,
+		SpoolDir:  t.TempDir(),
+		ShimPath:  os.Args[0],
+	}
+
+	var mu sync.Mutex
+	var output string
+	finished := make(chan *entities.Run, 1)
+
+	// Mocks expected response
+	mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+		Return(&entities.Command{ID: 1, Name: "detached", Script: "echo detached", Detached: true}, nil).Times(1)
+	mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+		Return(&entities.Run{ID: 1, CommandID: 1, Name: "detached", Status: entities.RunQueued}, nil).Times(1)
+	mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+			mu.Lock()
+			defer mu.Unlock()
+			output += run.Output
+			return nil
+		}).AnyTimes()
+	mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+			finished <- run
+			return nil
+		}).Times(1)
+	mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil).AnyTimes()
+
+	// Controller
+	ctrl := handlers.NewController(ctx, cfg)
+	queues := queue.NewManager(ctx, cfg)
+	mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+	require.NoError(t, err)
+
+	// Form new request
+	url := `http://` + cfg.Address + `/command`
+	body := `{"name": "detached", "script": "echo detached", "detached": true}`
+
+	r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	mh.ServeHTTP(w, r)
+
+	// Check status code
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// Check the output copied from the spool and the removed spool directory
+	select {
+	case run := <-finished:
+		require.Equal(t, entities.RunSucceeded, run.Status)
+		require.NotNil(t, run.ExitCode)
+		require.Equal(t, 0, *run.ExitCode)
+	case <-time.After(5 * time.Second):
+		t.Fatal("run is not finished")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, "detached\n", output)
+	require.NoDirExists(t, filepath.Join(cfg.SpoolDir, "1"))
+}
+
+func TestCommandHandler_Reattach(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv(shimEnv, "1")
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 1,
+		SpoolDir:  t.TempDir(),
+	}
+
+	// The command started by the previous server
+	dir := filepath.Join(cfg.SpoolDir, "7")
+	_, err := supervisor.Start(os.Args[0], dir, &supervisor.Spec{
+		RunID: 7,
+		Path:  "/bin/sh",
+		Args:  []string{"-c", "echo before; sleep 0.5; echo after"},
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		data, _ := os.ReadFile(filepath.Join(dir, "output"))
+		return strings.HasPrefix(string(data), "before\n")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var mu sync.Mutex
+	var output string
+	finished := make(chan *entities.Run, 1)
+
+	// Mocks expected response
+	mockRepo.EXPECT().GetRunByID(gomock.Any(), 7).
+		Return(&entities.Run{ID: 7, CommandID: 3, Name: "long", Status: entities.RunRunning, Attempt: 1, Output: "before\n"}, nil).Times(1)
+	mockRepo.EXPECT().GetRunAttempts(gomock.Any(), 7).Return(nil, nil).Times(1)
+	mockRepo.EXPECT().GetCommandByName(gomock.Any(), "long").
+		Return(&entities.Command{ID: 3, Name: "long", Script: "long.sh", Detached: true}, nil).Times(1)
+	mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+			mu.Lock()
+			defer mu.Unlock()
+			output += run.Output
+			return nil
+		}).AnyTimes()
+	mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+			finished <- run
+			return nil
+		}).Times(1)
+	mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil).AnyTimes()
+
+	// Controller of the restarted server
+	ctrl := handlers.NewController(ctx, cfg)
+	queues := queue.NewManager(ctx, cfg)
+	_, err = ctrl.BuildRoute(ctx, mockRepo, queues)
+	require.NoError(t, err)
+
+	// Only the output written after the stored one is appended
+	select {
+	case run := <-finished:
+		require.Equal(t, entities.RunSucceeded, run.Status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("run is not finished")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, "after\n", output)
+	require.NoDirExists(t, dir)
+}
+
+func TestCommandHandler_DetachedSupervisorLost(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv(shimEnv, "1")
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 1,
+		SpoolDir:  t.TempDir(),
+		ShimPath:  os.Args[0],
+	}
+
+	finished := make(chan *entities.Run, 1)
+
+	// Mocks expected response
+	mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+		Return(&entities.Command{ID: 1, Name: "detached", Script: "sleep 10", Detached: true}, nil).Times(1)
+	mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+		Return(&entities.Run{ID: 1, CommandID: 1, Name: "detached", Status: entities.RunQueued}, nil).Times(1)
+	mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+			finished <- run
+			return nil
+		}).Times(1)
+	mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil).AnyTimes()
+
+	// Controller
+	ctrl := handlers.NewController(ctx, cfg)
+	queues := queue.NewManager(ctx, cfg)
+	mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, `http://`+cfg.Address+`/command`,
+		bytes.NewBufferString(`{"name": "detached", "script": "sleep 10", "detached": true}`))
+	w := httptest.NewRecorder()
+	mh.ServeHTTP(w, r)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// Kill the supervisor, so the exit status is never stored
+	dir := filepath.Join(cfg.SpoolDir, "1")
+	var st supervisor.State
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(filepath.Join(dir, "state.json"))
+		return err == nil && json.Unmarshal(data, &st) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	p, err := os.FindProcess(st.Supervisor)
+	require.NoError(t, err)
+	require.NoError(t, p.Kill())
+
+	// The run fails instead of being followed forever
+	select {
+	case run := <-finished:
+		require.Equal(t, entities.RunFailed, run.Status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("run is not finished")
+	}
+	require.NoDirExists(t, dir)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"go.uber.org/zap"
+)
+
+// Drain stops accepting the new runs and closes the queues, so the workers
+// stop after their current runs. The runs waiting in the queues or for their
+// next attempt are returned into the pending runs and queued again by the next
+// server start, the waiting pipelines are interrupted.
+func (h *CommandHandler) Drain(ctx context.Context) {
+	if h.draining.Swap(true) || h.queues == nil {
+		return
+	}
+	h.queues.Close()
+
+	for _, j := range h.queues.Drain() {
+		if val, ok := h.pipelines.Load(j.ID); ok {
+			h.interruptPipeline(val.(*activePipeline))
+			continue
+		}
+		if val, ok := h.procs.Load(j.ID); ok {
+			h.keepRun(val.(*activeRun))
+		}
+	}
+
+	h.procs.Range(func(key, val any) bool {
+		ar := val.(*activeRun)
+
+		ar.mu.Lock()
+		stopped := ar.retry != nil && ar.retry.Stop()
+		if stopped {
+			ar.retry = nil
+		}
+		ar.mu.Unlock()
+
+		if stopped {
+			h.keepRun(ar)
+		}
+		return true
+	})
+}
+
+// Stop waits for the active runs to finish until the context is done.
+// Then the running commands are interrupted with SIGTERM and killed
+// if they do not exit within the kill timeout. The detached commands
+// keep running and are attached again by the next server start.
+func (h *CommandHandler) Stop(ctx context.Context) {
+	active := make([]*activeRun, 0)
+	h.procs.Range(func(key, val any) bool {
+		ar := val.(*activeRun)
+
+		ar.mu.Lock()
+		detached := ar.shim != nil
+		ar.mu.Unlock()
+
+		if !detached {
+			active = append(active, ar)
+		}
+		return true
+	})
+
+	if waitRuns(ctx, active) {
+		return
+	}
+
+	logger.Log.Warn("Stop: drain timeout reached, interrupting running commands",
+		zap.Int("active", len(active)))
+
+	for _, ar := range active {
+		h.interruptRun(ar)
+	}
+
+	ctxKill, cancelKill := context.WithTimeout(context.Background(), h.Config.KillTimeout+time.Second)
+	defer cancelKill()
+
+	if !waitRuns(ctxKill, active) {
+		logger.Log.Error("Stop: interrupted commands are not finished")
+	}
+}
+
+// keepRun returns the run which has not been started before the shutdown into
+// the pending runs. The run submitted with the input, parameters, environment
+// variables or arguments cannot be restored from the storage,
+// so it is marked as interrupted instead.
+func (h *CommandHandler) keepRun(ar *activeRun) {
+	h.groups.Forget(runHolder(ar.run.ID, ar.run.Name))
+
+	if !ar.restorable {
+		h.finishRun(context.Background(), ar, entities.RunInterrupted, ar.run.ExitCode)
+		return
+	}
+
+	err := h.Service.SuspendRun(context.Background(), ar.run)
+	if err != nil {
+		runLogger(ar.run).Error("keepRun: return run into pending runs failed",
+			zap.Error(err))
+	} else {
+		runLogger(ar.run).Info("keepRun: run is kept for the next start")
+		h.notify(ar.run, ar.hooks)
+	}
+
+	ar.queued.End()
+	h.procs.Delete(ar.run.ID)
+	close(ar.done)
+}
+
+// interruptRun sends SIGTERM to the running command of the run
+// and kills it after the kill timeout.
+func (h *CommandHandler) interruptRun(ar *activeRun) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if ar.remote != nil && !ar.cancelled {
+		ar.interrupted = true
+		h.interruptRemote(ar)
+		return
+	}
+
+	if ar.proc == nil || ar.cancelled {
+		return
+	}
+	ar.interrupted = true
+
+	proc := ar.proc
+	err := proc.Signal(syscall.SIGTERM)
+	if err != nil {
+		if !errors.Is(err, os.ErrProcessDone) {
+			runLogger(ar.run).Error("interruptRun: terminate command failed",
+				zap.Error(err))
+		}
+		return
+	}
+
+	time.AfterFunc(h.Config.KillTimeout, func() {
+		proc.Kill()
+	})
+}
+
+// interruptRemote requests the agent to send SIGTERM to the command of the run
+// and to kill it after the kill timeout. It is called with the run locked.
+func (h *CommandHandler) interruptRemote(ar *activeRun) {
+	id := ar.run.ID
+	err := h.agents.Signal(id, int(syscall.SIGTERM))
+	if err != nil {
+		if !errors.Is(err, errs.ErrRunNotFound) {
+			runLogger(ar.run).Error("interruptRemote: terminate remote command failed",
+				zap.Error(err))
+		}
+		return
+	}
+
+	time.AfterFunc(h.Config.KillTimeout, func() {
+		h.agents.Signal(id, int(syscall.SIGKILL))
+	})
+}
+
+// waitRuns waits for the completion of the runs until the context is done.
+// It returns false if some runs are not finished.
+func waitRuns(ctx context.Context, runs []*activeRun) bool {
+	for _, ar := range runs {
+		select {
+		case <-ar.done:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/executor"
+	"github.com/pavlegich/scripts-hub/internal/infra/tracing"
+)
+
+// executorFor returns the executor starting the process of the command,
+// the SSH host of the command or the executor selected by its name.
+// The command without both is run by the local executor.
+func (h *CommandHandler) executorFor(c *entities.Command) (executor.Executor, error) {
+	if c.Host != "" {
+		if !h.hosts.Has(c.Host) {
+			return nil, fmt.Errorf("executorFor: host %s %w", c.Host, errs.ErrHostNotFound)
+		}
+		return h.hosts.Host(c.Host), nil
+	}
+
+	name := c.Executor
+	if name == "" {
+		name = executor.Local
+	}
+
+	e, ok := h.executors[name]
+	if !ok {
+		return nil, fmt.Errorf("executorFor: executor %s %w", name, errs.ErrExecutorNotFound)
+	}
+	return e, nil
+}
+
+// lookPath checks that the command path can be started by the executor of the command.
+func (h *CommandHandler) lookPath(c *entities.Command, path string) error {
+	e, err := h.executorFor(c)
+	if err != nil {
+		return fmt.Errorf("lookPath: %w", err)
+	}
+
+	err = e.LookPath(path)
+	if err != nil {
+		return fmt.Errorf("lookPath: %w", err)
+	}
+	return nil
+}
+
+// usageAttributes returns the span attributes of the resources used by the process,
+// the values which are not reported by the executor are skipped.
+func usageAttributes(u executor.Usage) []tracing.Attribute {
+	attrs := make([]tracing.Attribute, 0, 3)
+	if u.UserTime > 0 || u.SystemTime > 0 {
+		attrs = append(attrs,
+			tracing.Int("cpu_user_ms", int(u.UserTime.Milliseconds())),
+			tracing.Int("cpu_system_ms", int(u.SystemTime.Milliseconds())))
+	}
+	if u.MaxRSS > 0 {
+		attrs = append(attrs, tracing.Int("max_rss_bytes", int(u.MaxRSS)))
+	}
+	return attrs
+}
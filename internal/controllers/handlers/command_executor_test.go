@@ -0,0 +1,161 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/executor"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandHandler_HandleCreateCommandExecutor(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 1,
+	}
+
+	// Commands of the fake executor
+	fake := executor.NewFake()
+	fake.Handle("greet", func(ctx context.Context, fio *executor.FakeIO) int {
+		fmt.Fprintf(fio.Stdout, "hello %s\n", fio.Args[0])
+		fio.Usage = executor.Usage{UserTime: time.Millisecond, MaxRSS: 1024}
+		return 0
+	})
+	fake.Handle("fail", func(ctx context.Context, fio *executor.FakeIO) int {
+		io.WriteString(fio.Stderr, "oops\n")
+		return 3
+	})
+	fake.Handle("block", func(ctx context.Context, fio *executor.FakeIO) int {
+		<-ctx.Done()
+		return 0
+	})
+
+	tests := []struct {
+		name       string
+		reqBody    string
+		cancel     bool
+		wantStatus string
+		wantCode   int
+		wantOutput string
+	}{
+		{
+			name:       "succeeded",
+			reqBody:    `{"name": "greet", "script": "greet world", "executor": "fake"}`,
+			wantStatus: entities.RunSucceeded,
+			wantOutput: "hello world\n",
+		},
+		{
+			name:       "failed",
+			reqBody:    `{"name": "fail", "script": "fail", "executor": "fake"}`,
+			wantStatus: entities.RunFailed,
+			wantCode:   3,
+			wantOutput: "oops\n",
+		},
+		{
+			name:       "cancelled",
+			reqBody:    `{"name": "block", "script": "block", "executor": "fake"}`,
+			cancel:     true,
+			wantStatus: entities.RunCancelled,
+			wantCode:   -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var output string
+			started := make(chan struct{}, 1)
+			finished := make(chan *entities.Run, 1)
+
+			// Initialize mock repository per case, so the output is appended
+			// by the expectations of the case
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockRepo := mocks.NewMockRepository(mockCtrl)
+
+			// Mocks expected response
+			mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, c *entities.Command) (*entities.Command, error) {
+					require.Equal(t, "fake", c.Executor)
+					c.ID = 1
+					return c, nil
+				}).Times(1)
+			mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+				Return(&entities.Run{ID: 1, CommandID: 1, Name: "fake", Status: entities.RunQueued}, nil).Times(1)
+			mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+					started <- struct{}{}
+					return nil
+				}).Times(1)
+			mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+					mu.Lock()
+					defer mu.Unlock()
+					output += run.Output
+					return nil
+				}).AnyTimes()
+			mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+					finished <- run
+					return nil
+				}).Times(1)
+			mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil, nil).AnyTimes()
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			ctrl.AddExecutor("fake", fake)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Submit the command
+			r := httptest.NewRequest(http.MethodPost, `http://`+cfg.Address+`/command`, bytes.NewBufferString(tt.reqBody))
+			w := httptest.NewRecorder()
+			mh.ServeHTTP(w, r)
+			require.Equal(t, http.StatusCreated, w.Code)
+
+			if tt.cancel {
+				select {
+				case <-started:
+				case <-time.After(5 * time.Second):
+					t.Fatal("run is not started")
+				}
+
+				r := httptest.NewRequest(http.MethodDelete, `http://`+cfg.Address+`/run?id=1`, nil)
+				w := httptest.NewRecorder()
+				mh.ServeHTTP(w, r)
+				require.Equal(t, http.StatusNoContent, w.Code)
+			}
+
+			// Check the status and the output written by the fake command
+			select {
+			case run := <-finished:
+				require.Equal(t, tt.wantStatus, run.Status)
+				require.NotNil(t, run.ExitCode)
+				require.Equal(t, tt.wantCode, *run.ExitCode)
+			case <-time.After(5 * time.Second):
+				t.Fatal("run is not finished")
+			}
+			if tt.wantOutput != "" {
+				mu.Lock()
+				defer mu.Unlock()
+				require.Equal(t, tt.wantOutput, output)
+			}
+		})
+	}
+}
@@ -5,50 +5,106 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-	"os"
-	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/pavlegich/scripts-hub/internal/entities"
 	errs "github.com/pavlegich/scripts-hub/internal/errors"
 	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/executor"
 	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/infra/sshexec"
 	"github.com/pavlegich/scripts-hub/internal/repository"
+	"github.com/pavlegich/scripts-hub/internal/service/agent"
 	"github.com/pavlegich/scripts-hub/internal/service/command"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/pavlegich/scripts-hub/internal/service/rule"
+	"github.com/pavlegich/scripts-hub/internal/service/webhook"
 	"go.uber.org/zap"
 )
 
 // CommandHandler contains objects for work with command handlers.
 type CommandHandler struct {
-	jobs    chan entities.Command
-	procs   sync.Map
-	Config  *config.Config
-	Service command.Service
+	queues      *queue.Manager
+	groups      *queue.Groups
+	procs       sync.Map
+	pipelines   sync.Map
+	webhooks    webhook.Service
+	rules       rule.Service
+	agents      agent.Service
+	hosts       *sshexec.Executor
+	executors   map[string]executor.Executor
+	pool        *workerPool
+	draining    atomic.Bool
+	maintenance atomic.Bool
+	Config      *config.Config
+	Service     command.Service
 }
 
 // commandsActivate activates handler for command object.
-func commandsActivate(ctx context.Context, r *http.ServeMux, repo repository.Repository, cfg *config.Config, runCmdChan chan entities.Command) {
+func commandsActivate(ctx context.Context, r *http.ServeMux, repo repository.Repository, cfg *config.Config,
+	queues *queue.Manager, hooks webhook.Service, rules rule.Service, agents agent.Service, hosts *sshexec.Executor,
+	executors map[string]executor.Executor) *CommandHandler {
 	s := command.NewCommandService(ctx, repo)
-	newHandler(ctx, r, cfg, s, runCmdChan)
+	return newHandler(ctx, r, cfg, s, queues, hooks, rules, agents, hosts, executors)
 }
 
 // newHandler initializes handler for command object.
-func newHandler(ctx context.Context, r *http.ServeMux, cfg *config.Config, s command.Service, runCmdChan chan entities.Command) {
+func newHandler(ctx context.Context, r *http.ServeMux, cfg *config.Config, s command.Service,
+	queues *queue.Manager, hooks webhook.Service, rules rule.Service, agents agent.Service, hosts *sshexec.Executor,
+	executors map[string]executor.Executor) *CommandHandler {
 	h := &CommandHandler{
-		jobs:    runCmdChan,
-		procs:   sync.Map{},
-		Config:  cfg,
-		Service: s,
+		queues:    queues,
+		groups:    queue.NewGroups(ctx),
+		procs:     sync.Map{},
+		webhooks:  hooks,
+		rules:     rules,
+		agents:    agents,
+		hosts:     hosts,
+		executors: executors,
+		pool:      newWorkerPool(ctx),
+		Config:    cfg,
+		Service:   s,
 	}
 
 	r.HandleFunc("/command", h.HandleCommand)
 	r.HandleFunc("/commands", h.HandleCommands)
+	r.HandleFunc("/queues", h.HandleQueues)
+	r.HandleFunc("/groups", h.HandleGroups)
+	r.HandleFunc("/queues/pause", h.HandlePauseQueues)
+	r.HandleFunc("/queues/resume", h.HandleResumeQueues)
+	r.HandleFunc("/jobs", h.HandleJobs)
+	r.HandleFunc("/job", h.HandleCancelJob)
+	r.HandleFunc("/job/front", h.HandleMoveJobToFront)
+	r.HandleFunc("/runs", h.HandleRuns)
+	r.HandleFunc("/run", h.HandleRun)
+	r.HandleFunc("/runs/pending", h.HandlePendingRuns)
+	r.HandleFunc("/pipeline", h.HandlePipeline)
+
+	// The detached runs are attached before the workers start,
+	// so the spool directories of the new runs are not taken for them.
+	if cfg.SpoolDir != "" {
+		h.Reattach(ctx)
+	}
 
-	for w := 1; w <= cfg.RateLimit; w++ {
-		go h.RunCommand(ctx)
+	if queues == nil {
+		return h
+	}
+	h.pool.mu.Lock()
+	for _, q := range queues.Queues() {
+		h.startWorkers(q, q.Workers())
 	}
+	h.pool.mu.Unlock()
+
+	if cfg.ScheduleInterval > 0 {
+		go h.RunDelayed(ctx, cfg.ScheduleInterval)
+	}
+
+	return h
 }
 
 // HandleCommand handles request to create or get the command.
@@ -95,6 +151,14 @@ func (h *CommandHandler) HandleCreateCommand(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if h.maintenance.Load() {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: new runs are refused",
+			zap.Error(errs.ErrMaintenance))
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
 	if req.Name == "" || req.Script == "" {
 		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: command name or script empty",
 			zap.Error(err), zap.String("cmd", req.Script))
@@ -103,19 +167,155 @@ func (h *CommandHandler) HandleCreateCommand(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	bashCmd := strings.Split(req.Script, " ")
+	if req.Priority < queue.MinPriority || req.Priority > queue.MaxPriority {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: command priority out of range",
+			zap.Int("priority", req.Priority))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if req.Queue == "" {
+		req.Queue = queue.DefaultQueue
+	}
+	q, ok := h.queues.Get(req.Queue)
+	if !ok {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: queue not found",
+			zap.String("queue", req.Queue))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for _, group := range req.Groups {
+		_, _, err = queue.ParseGroup(group)
+		if err != nil {
+			logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: incorrect concurrency group",
+				zap.Error(err), zap.String("group", group))
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	if req.Detached && h.Config.SpoolDir == "" {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: detached commands are disabled, spool directory is not set")
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for _, label := range req.Labels {
+		if label == "" || strings.Contains(label, ",") {
+			logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: incorrect agent label",
+				zap.String("label", label))
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	if len(req.Labels) > 0 && h.Config.AgentToken == "" {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: command run by agents while they are disabled",
+			zap.Error(errs.ErrAgentsDisabled))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if len(req.Labels) > 0 && req.Detached {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: command run by agents cannot be detached")
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if req.Host != "" && !h.hosts.Has(req.Host) {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: ssh host not found",
+			zap.String("host", req.Host))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if req.Host != "" && (len(req.Labels) > 0 || req.Detached) {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: command run on ssh host cannot be detached or run by agents")
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if req.Executor != "" && (req.Host != "" || len(req.Labels) > 0 || req.Detached) {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: command with executor cannot be detached, run by agents or on ssh host",
+			zap.String("executor", req.Executor))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 
-	_, err = exec.LookPath(bashCmd[0])
+	err = command.ValidateRetry(req.Retry)
 	if err != nil {
-		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: look command path failed",
-			zap.Error(err), zap.String("cmd", req.Script))
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: incorrect retry policy",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for _, u := range req.Webhooks {
+		err = webhook.ValidateURL(u)
+		if err != nil {
+			logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: incorrect webhook",
+				zap.Error(err), zap.String("url", u))
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	if len(req.Webhooks) > 0 && h.Config.WebhookSecret == "" {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: webhooks require the webhook secret")
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// The command run by the agents is looked up on their hosts.
+	if len(req.Labels) == 0 {
+		bashCmd := strings.Split(req.Script, " ")
+
+		err = h.lookPath(&req, bashCmd[0])
+		if err != nil {
+			logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: look command path failed",
+				zap.Error(err), zap.String("cmd", req.Script), zap.String("executor", req.Executor))
+
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	runAt, err := delayedRunAt(&req)
+	if err != nil {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: incorrect delayed run time",
+			zap.Error(err), zap.String("delay", req.Delay))
 
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	if runAt != nil {
+		h.HandleCreateDelayedCommand(w, r, &req, *runAt)
+		return
+	}
+
+	caller := callerAddr(r, h.Config.TrustedProxies)
+	err = q.Reserve(caller)
+	if err != nil {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: queue rejected command",
+			zap.Error(err), zap.String("queue", q.Name()), zap.String("caller", caller))
+
+		writeQueueRejected(w, q, err)
+		return
+	}
 
-	commandID, err := h.Service.Create(ctx, &req)
+	req.ID, err = h.Service.Create(ctx, &req)
 	if err != nil {
+		q.CancelReservation(caller)
+
 		logger.Log.Error("HandleCreateCommand: create command failed",
 			zap.Error(err))
 
@@ -128,13 +328,86 @@ func (h *CommandHandler) HandleCreateCommand(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	go func() {
-		h.jobs <- req
-	}()
+	_, err = h.enqueue(ctx, q, &req, entities.SubmitOptions{Trigger: entities.TriggerAPI}, caller, true, nil)
+	if err != nil {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateCommand: enqueue command run failed",
+			zap.Error(err), zap.String("queue", q.Name()))
+
+		if errors.Is(err, errs.ErrQueueClosed) {
+			writeQueueRejected(w, q, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"command_id": req.ID})
+}
+
+// HandleCreateDelayedCommand handles request to create new command
+// and execute it once at the specified time.
+func (h *CommandHandler) HandleCreateDelayedCommand(w http.ResponseWriter, r *http.Request, req *entities.Command, runAt time.Time) {
+	ctx := r.Context()
+
+	var err error
+	req.ID, err = h.Service.Create(ctx, req)
+	if err != nil {
+		logger.Log.Error("HandleCreateDelayedCommand: create command failed",
+			zap.Error(err))
+
+		if errors.Is(err, errs.ErrCmdAlreadyExists) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	run, err := h.Service.CreateRun(ctx, &entities.Run{
+		CommandID: req.ID,
+		Name:      req.Name,
+		Trigger:   entities.TriggerAPI,
+		Status:    entities.RunPending,
+		RunAt:     &runAt,
+	})
+	if err != nil {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateDelayedCommand: create delayed run failed",
+			zap.Error(err), zap.Time("run_at", runAt))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]int{"command_id": commandID})
+	json.NewEncoder(w).Encode(map[string]int{"command_id": req.ID, "run_id": run.ID})
+}
+
+// delayedRunAt returns the time of the delayed run of the submitted command
+// or nil if the command should be run immediately.
+func delayedRunAt(c *entities.Command) (*time.Time, error) {
+	if c.RunAt != nil && c.Delay != "" {
+		return nil, fmt.Errorf("delayedRunAt: both run_at and delay specified")
+	}
+
+	if c.Delay != "" {
+		delay, err := time.ParseDuration(c.Delay)
+		if err != nil {
+			return nil, fmt.Errorf("delayedRunAt: parse delay failed %w", err)
+		}
+		if delay < 0 {
+			return nil, fmt.Errorf("delayedRunAt: negative delay %s", c.Delay)
+		}
+
+		runAt := time.Now().Add(delay)
+		return &runAt, nil
+	}
+
+	return c.RunAt, nil
 }
 
 // HandleGetCommand handles request to get the requested command.
@@ -293,31 +566,24 @@ func (h *CommandHandler) HandleDeleteCommand(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	val, loaded := h.procs.LoadAndDelete(cmdName)
-	if !loaded {
+	active := h.activeRuns(cmdName)
+	if len(active) == 0 {
 		logger.Log.With(zap.String("cmd_name", cmdName)).
-			Info("HandleDeleteCommand: command in map not found")
+			Info("HandleDeleteCommand: active runs of command not found")
 
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	cmd, ok := val.(*exec.Cmd)
-	if !ok {
-		logger.Log.With(zap.String("cmd_name", cmdName)).
-			Error("HandleDeleteCommand: asserting command failed", zap.Error(err))
-
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	err = cmd.Cancel()
-	if err != nil && !errors.Is(err, os.ErrProcessDone) {
-		logger.Log.With(zap.String("cmd_name", cmdName)).
-			Error("HandleDeleteCommand: cancel command failed", zap.Error(err))
+	for _, ar := range active {
+		err = h.cancelRun(ctx, ar)
+		if err != nil {
+			logger.Log.With(zap.String("cmd_name", cmdName)).
+				Error("HandleDeleteCommand: cancel command run failed", zap.Error(err))
 
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
 	}
 
 	w.WriteHeader(http.StatusNoContent)
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	errs "github.com/pavlegich/scripts-hub/internal/errors"
 	"github.com/pavlegich/scripts-hub/internal/infra/config"
 	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
 	"github.com/stretchr/testify/require"
 )
 
@@ -145,6 +147,96 @@ func TestCommandHandler_HandleCreateCommand(t *testing.T) {
 			wantCode: http.StatusBadRequest,
 			wantBody: ``,
 		},
+		{
+			name: "priority_out_of_range",
+			args: args{
+				reqBody: `{"name": "pwd", "script": "pwd", "priority": 101}`,
+			},
+			expected: expected{},
+			wantCode: http.StatusBadRequest,
+			wantBody: ``,
+		},
+		{
+			name: "queue_not_found",
+			args: args{
+				reqBody: `{"name": "pwd", "script": "pwd", "queue": "unknown"}`,
+			},
+			expected: expected{},
+			wantCode: http.StatusBadRequest,
+			wantBody: ``,
+		},
+		{
+			name: "incorrect_group",
+			args: args{
+				reqBody: `{"name": "pwd", "script": "pwd", "groups": ["db-migrations"]}`,
+			},
+			expected: expected{},
+			wantCode: http.StatusBadRequest,
+			wantBody: ``,
+		},
+		{
+			name: "detached_without_spool_dir",
+			args: args{
+				reqBody: `{"name": "pwd", "script": "pwd", "detached": true}`,
+			},
+			expected: expected{},
+			wantCode: http.StatusBadRequest,
+			wantBody: ``,
+		},
+		{
+			name: "incorrect_label",
+			args: args{
+				reqBody: `{"name": "pwd", "script": "pwd", "labels": ["linux,gpu"]}`,
+			},
+			expected: expected{},
+			wantCode: http.StatusBadRequest,
+			wantBody: ``,
+		},
+		{
+			name: "agents_disabled",
+			args: args{
+				reqBody: `{"name": "pwd", "script": "pwd", "labels": ["linux"]}`,
+			},
+			expected: expected{},
+			wantCode: http.StatusBadRequest,
+			wantBody: ``,
+		},
+		{
+			name: "unknown_ssh_host",
+			args: args{
+				reqBody: `{"name": "pwd", "script": "pwd", "host": "db"}`,
+			},
+			expected: expected{},
+			wantCode: http.StatusBadRequest,
+			wantBody: ``,
+		},
+		{
+			name: "unknown_executor",
+			args: args{
+				reqBody: `{"name": "pwd", "script": "pwd", "executor": "docker"}`,
+			},
+			expected: expected{},
+			wantCode: http.StatusBadRequest,
+			wantBody: ``,
+		},
+		{
+			name: "incorrect_webhook",
+			args: args{
+				reqBody: `{"name": "pwd", "script": "pwd", "webhooks": ["ci.local/hook"]}`,
+			},
+			expected: expected{},
+			wantCode: http.StatusBadRequest,
+			wantBody: ``,
+		},
+		{
+			name: "webhook_without_secret",
+			args: args{
+				reqBody: `{"name": "pwd", "script": "pwd", "webhooks": ["https://ci.local/hook"]}`,
+			},
+			expected: expected{},
+			wantCode: http.StatusBadRequest,
+			wantBody: ``,
+		},
 		{
 			name: "unknown_command",
 			args: args{
@@ -178,14 +270,22 @@ func TestCommandHandler_HandleCreateCommand(t *testing.T) {
 					Return(tt.expected.create.cmd, tt.expected.create.err).Times(1)
 			}
 			if tt.expected.append.want {
-				mockRepo.EXPECT().AppendCommandOutputByName(gomock.Any(), gomock.Any()).
+				mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+					Return(&entities.Run{ID: 1, CommandID: 1, Name: "pwd", Status: entities.RunQueued}, nil).Times(1)
+				mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+				mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).
 					Return(tt.expected.append.err).Times(1)
+				mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+				mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, nil).AnyTimes()
 			}
 
 			// Controller
 			ctrl := handlers.NewController(ctx, cfg)
-			ch := make(chan entities.Command)
-			mh, err := ctrl.BuildRoute(ctx, mockRepo, ch)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
 			require.NoError(t, err)
 
 			// Form new request
@@ -212,6 +312,230 @@ func TestCommandHandler_HandleCreateCommand(t *testing.T) {
 	}
 }
 
+func TestCommandHandler_HandleCreateCommandRejected(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	type args struct {
+		cfg *config.Config
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantCode int
+	}{
+		{
+			name: "queue_full",
+			args: args{
+				cfg: &config.Config{
+					Address:       `localhost:8080`,
+					QueueMaxDepth: 1,
+				},
+			},
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name: "caller_limit",
+			args: args{
+				cfg: &config.Config{
+					Address:          `localhost:8080`,
+					QueueCallerLimit: 1,
+				},
+			},
+			wantCode: http.StatusTooManyRequests,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Only the first command is created
+			mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+				Return(&entities.Command{ID: 1}, nil).Times(1)
+			mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+				Return(&entities.Run{ID: 1, CommandID: 1, Status: entities.RunQueued}, nil).Times(1)
+
+			// Controller with queues without started workers
+			ctrl := handlers.NewController(ctx, tt.args.cfg)
+			queues := queue.NewManager(ctx, tt.args.cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new requests
+			url := `http://` + tt.args.cfg.Address + `/command`
+
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"name": "first", "script": "pwd"}`))
+			w := httptest.NewRecorder()
+			mh.ServeHTTP(w, r)
+			require.Equal(t, http.StatusCreated, w.Code)
+
+			r = httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"name": "second", "script": "pwd"}`))
+			w = httptest.NewRecorder()
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			// Check status code and retry header
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			require.Equal(t, "1", resp.Header.Get("Retry-After"))
+		})
+	}
+}
+
+func TestCommandHandler_HandleCreateCommandForwarded(t *testing.T) {
+	ctx := context.Background()
+
+	// The requests of httptest come from 192.0.2.1
+	type args struct {
+		proxies string
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantCode int
+	}{
+		{
+			name: "trusted_proxy",
+			args: args{
+				proxies: "192.0.2.0/24",
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name: "untrusted_proxy",
+			args: args{
+				proxies: "10.0.0.1",
+			},
+			wantCode: http.StatusTooManyRequests,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Initialize mock repository
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockRepo := mocks.NewMockRepository(mockCtrl)
+
+			cfg := &config.Config{
+				Address:          `localhost:8080`,
+				QueueCallerLimit: 1,
+			}
+			require.NoError(t, cfg.TrustedProxies.Set(tt.args.proxies))
+
+			created := 1
+			if tt.wantCode == http.StatusCreated {
+				created = 2
+			}
+			mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+				Return(&entities.Command{ID: 1}, nil).Times(created)
+			mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+				Return(&entities.Run{ID: 1, CommandID: 1, Status: entities.RunQueued}, nil).Times(created)
+
+			// Controller with queues without started workers
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new requests of the different clients behind the proxy
+			url := `http://` + cfg.Address + `/command`
+
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"name": "first", "script": "pwd"}`))
+			r.Header.Set("X-Forwarded-For", "203.0.113.1")
+			w := httptest.NewRecorder()
+			mh.ServeHTTP(w, r)
+			require.Equal(t, http.StatusCreated, w.Code)
+
+			r = httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{"name": "second", "script": "pwd"}`))
+			r.Header.Set("X-Forwarded-For", "203.0.113.2")
+			w = httptest.NewRecorder()
+			mh.ServeHTTP(w, r)
+
+			// Check status code
+			resp := w.Result()
+			defer resp.Body.Close()
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestCommandHandler_HandleCreateCommandGroups(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 2,
+	}
+
+	var mu sync.Mutex
+	ids := 0
+	finished := make(chan string, 3)
+
+	// Mocks expected response
+	mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, c *entities.Command) (*entities.Command, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			ids++
+			c.ID = ids
+			return c, nil
+		}).Times(3)
+	mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, run *entities.Run) (*entities.Run, error) {
+			run.ID = run.CommandID
+			return run, nil
+		}).Times(3)
+	mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+	mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+			finished <- run.Name
+			return nil
+		}).Times(3)
+	mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil).AnyTimes()
+
+	// Controller
+	ctrl := handlers.NewController(ctx, cfg)
+	queues := queue.NewManager(ctx, cfg)
+	mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+	require.NoError(t, err)
+
+	// The first command holds the group, the second one waits for it
+	// and the command without the group is run by the free worker at once
+	url := `http://` + cfg.Address + `/command`
+	for _, body := range []string{
+		`{"name": "first", "script": "sleep 1", "groups": ["x:1"]}`,
+		`{"name": "second", "script": "sleep 1", "groups": ["x:1"]}`,
+		`{"name": "free", "script": "pwd"}`,
+	} {
+		r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		mh.ServeHTTP(w, r)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	got := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		select {
+		case name := <-finished:
+			got = append(got, name)
+		case <-time.After(5 * time.Second):
+			t.Fatal("runs are not finished")
+		}
+	}
+	require.Equal(t, []string{"free", "first", "second"}, got)
+}
+
 func TestCommandHandler_HandleGetCommand(t *testing.T) {
 	ctx := context.Background()
 
@@ -614,8 +938,16 @@ func TestCommandHandler_HandleDeleteCommand(t *testing.T) {
 					Return(tt.expected.create.cmd, tt.expected.create.err).Times(1)
 			}
 			if tt.expected.append.want {
-				mockRepo.EXPECT().AppendCommandOutputByName(gomock.Any(), gomock.Any()).
+				mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+					Return(&entities.Run{ID: 1, CommandID: 1, Name: "pwd", Status: entities.RunQueued}, nil).Times(1)
+				mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+				mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).
 					Return(tt.expected.append.err).Times(1)
+				mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+				mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, nil).AnyTimes()
 			}
 			if tt.expected.delete.want {
 				mockRepo.EXPECT().DeleteCommandByName(gomock.Any(), gomock.Any()).
@@ -624,8 +956,8 @@ func TestCommandHandler_HandleDeleteCommand(t *testing.T) {
 
 			// Controller
 			ctrl := handlers.NewController(ctx, cfg)
-			ch := make(chan entities.Command)
-			mh, err := ctrl.BuildRoute(ctx, mockRepo, ch)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
 			require.NoError(t, err)
 
 			// CREATE COMMAND
@@ -672,3 +1004,273 @@ func TestCommandHandler_HandleDeleteCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandHandler_HandleCreateDelayedCommand(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 1,
+	}
+
+	tests := []struct {
+		name      string
+		reqBody   string
+		wantRunAt time.Time
+		wantCode  int
+		wantBody  string
+	}{
+		{
+			name:      "run_at",
+			reqBody:   `{"name": "pwd", "script": "pwd", "run_at": "2030-01-02T03:04:05Z"}`,
+			wantRunAt: time.Date(2030, time.January, 2, 3, 4, 5, 0, time.UTC),
+			wantCode:  http.StatusCreated,
+			wantBody:  `{"command_id": 1, "run_id": 5}`,
+		},
+		{
+			name:      "delay",
+			reqBody:   `{"name": "pwd", "script": "pwd", "delay": "2h"}`,
+			wantRunAt: time.Now().Add(2 * time.Hour),
+			wantCode:  http.StatusCreated,
+			wantBody:  `{"command_id": 1, "run_id": 5}`,
+		},
+		{
+			name:     "both_run_at_and_delay",
+			reqBody:  `{"name": "pwd", "script": "pwd", "run_at": "2030-01-02T03:04:05Z", "delay": "2h"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "incorrect_delay",
+			reqBody:  `{"name": "pwd", "script": "pwd", "delay": "tomorrow"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "negative_delay",
+			reqBody:  `{"name": "pwd", "script": "pwd", "delay": "-1m"}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response, the delayed command is not queued
+			if tt.wantCode == http.StatusCreated {
+				mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+					Return(&entities.Command{ID: 1, Name: "pwd", Script: "pwd"}, nil).Times(1)
+				mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, run *entities.Run) (*entities.Run, error) {
+						require.Equal(t, entities.RunPending, run.Status)
+						require.NotNil(t, run.RunAt)
+						require.WithinDuration(t, tt.wantRunAt, *run.RunAt, time.Minute)
+						run.ID = 5
+						return run, nil
+					}).Times(1)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/command`
+
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(tt.reqBody))
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code and that nothing is queued
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+			require.Empty(t, queues.Jobs())
+		})
+	}
+}
+
+func TestCommandHandler_HandleCreateCommandRetry(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 1,
+	}
+
+	tests := []struct {
+		name       string
+		reqBody    string
+		wantCode   int
+		wantStarts int
+		wantStatus string
+	}{
+		{
+			name:       "retried_until_last_attempt",
+			reqBody:    `{"name": "flaky", "script": "false", "retry": {"max_attempts": 3, "backoff": "10ms", "max_backoff": "20ms"}}`,
+			wantCode:   http.StatusCreated,
+			wantStarts: 3,
+			wantStatus: entities.RunFailed,
+		},
+		{
+			name:       "exit_code_not_retryable",
+			reqBody:    `{"name": "flaky", "script": "false", "retry": {"max_attempts": 3, "backoff": "10ms", "exit_codes": [7]}}`,
+			wantCode:   http.StatusCreated,
+			wantStarts: 1,
+			wantStatus: entities.RunFailed,
+		},
+		{
+			name:     "incorrect_retry",
+			reqBody:  `{"name": "flaky", "script": "false", "retry": {"max_attempts": 0}}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			finished := make(chan *entities.Run, 1)
+			if tt.wantCode == http.StatusCreated {
+				mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+					Return(&entities.Command{ID: 1, Name: "flaky", Script: "false"}, nil).Times(1)
+				mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+					Return(&entities.Run{ID: 1, CommandID: 1, Name: "flaky", Status: entities.RunQueued}, nil).Times(1)
+				mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).
+					Return(nil).Times(tt.wantStarts)
+				mockRepo.EXPECT().RetryRun(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+						require.Equal(t, entities.RunRetrying, run.Status)
+						require.NotNil(t, run.FinishedAt)
+						return nil
+					}).Times(tt.wantStarts - 1)
+				mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+						finished <- run
+						return nil
+					}).Times(1)
+				mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, nil).AnyTimes()
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/command`
+
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(tt.reqBody))
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Check status code
+			resp := w.Result()
+			defer resp.Body.Close()
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+
+			if tt.wantCode != http.StatusCreated {
+				return
+			}
+
+			// The final status reflects the last attempt
+			select {
+			case run := <-finished:
+				require.Equal(t, tt.wantStatus, run.Status)
+				require.Equal(t, tt.wantStarts, run.Attempt)
+				require.NotNil(t, run.ExitCode)
+				require.Equal(t, 1, *run.ExitCode)
+			case <-time.After(5 * time.Second):
+				t.Fatal("run is not finished")
+			}
+		})
+	}
+}
+
+func TestCommandHandler_HandleCreateCommandTraceParent(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 1,
+	}
+
+	var mu sync.Mutex
+	var output string
+	finished := make(chan struct{})
+
+	// Mocks expected response
+	mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+		Return(&entities.Command{ID: 1, Name: "trace", Script: "printenv TRACEPARENT"}, nil).Times(1)
+	mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+		Return(&entities.Run{ID: 1, CommandID: 1, Name: "trace", Status: entities.RunQueued}, nil).Times(1)
+	mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+			mu.Lock()
+			defer mu.Unlock()
+			output += run.Output
+			return nil
+		}).AnyTimes()
+	mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+			close(finished)
+			return nil
+		}).Times(1)
+	mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil).AnyTimes()
+
+	// Controller
+	ctrl := handlers.NewController(ctx, cfg)
+	queues := queue.NewManager(ctx, cfg)
+	mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+	require.NoError(t, err)
+
+	// Form new request
+	url := `http://` + cfg.Address + `/command`
+	body := `{"name": "trace", "script": "printenv TRACEPARENT"}`
+
+	r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+
+	mh.ServeHTTP(w, r)
+
+	// Check status code
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// Check the trace context passed to the command
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run is not finished")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	require.Regexp(t, `^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-01\n$`, output)
+	require.NotContains(t, output, "00f067aa0ba902b7")
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/tracing"
+	"github.com/pavlegich/scripts-hub/internal/service/agent"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"go.uber.org/zap"
+)
+
+// runRemote waits for the agent having the labels of the command, passes
+// the run to it and waits for the exit status reported by the agent.
+// The output of the command is appended by the agent requests.
+func (h *CommandHandler) runRemote(ctx context.Context, ar *activeRun, j *queue.Job, span *tracing.Span, bashCmd []string) {
+	c := j.Command
+
+	id, err := h.agents.Acquire(ctx, c.Labels)
+	if err != nil {
+		runLogger(ar.run).Warn("runRemote: wait for agent interrupted, run is kept",
+			zap.Error(err), zap.Strings("labels", c.Labels))
+
+		h.keepRun(ar)
+		return
+	}
+	span.SetAttributes(tracing.String("agent_id", id))
+
+	env := append([]string{}, ar.env...)
+	job := &entities.AgentJob{
+		RunID: ar.run.ID,
+		Path:  bashCmd[0],
+		Args:  bashCmd[1:],
+		Env:   append(env, "TRACEPARENT="+span.TraceParent()),
+		Input: ar.input,
+	}
+
+	task, err := h.startRemote(ctx, ar, id, job)
+	span.SetAttributes(tracing.Int("attempt", ar.run.Attempt))
+	if err != nil {
+		span.SetError(err)
+		runLogger(ar.run).Error("runRemote: start run failed",
+			zap.Error(err), zap.String("cmd", c.Script), zap.String("agent_id", id))
+
+		status := entities.RunFailed
+		if errors.Is(err, errs.ErrRunCancelled) {
+			status = entities.RunCancelled
+		}
+		h.finishRun(context.Background(), ar, status, nil)
+		return
+	}
+
+	// The shutdown signal does not stop the remote command,
+	// it is interrupted by the drain after the drain timeout.
+	exit := <-task.Done()
+
+	var exitErr error
+	switch {
+	case exit.Error != "":
+		exitErr = fmt.Errorf("runRemote: %s", exit.Error)
+	case exit.ExitCode != 0:
+		exitErr = fmt.Errorf("runRemote: exit status %d", exit.ExitCode)
+	}
+	span.SetAttributes(tracing.Int("exit_code", exit.ExitCode))
+	span.SetError(exitErr)
+
+	h.completeRun(ar, j, exit.ExitCode, exitErr)
+}
+
+// startRemote marks the run as running its next attempt and passes it
+// into the slot reserved on the agent unless the run has been already cancelled.
+func (h *CommandHandler) startRemote(ctx context.Context, ar *activeRun, id string, job *entities.AgentJob) (*agent.Task, error) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if ar.cancelled {
+		h.agents.Release(id)
+		return nil, fmt.Errorf("startRemote: %w", errs.ErrRunCancelled)
+	}
+
+	err := h.Service.StartRun(ctx, ar.run)
+	if err != nil {
+		h.agents.Release(id)
+		return nil, fmt.Errorf("startRemote: mark run as running failed %w", err)
+	}
+
+	task, err := h.agents.Start(id, job, NewCommandWriter(ctx, ar.run, h.Service))
+	if err != nil {
+		return nil, fmt.Errorf("startRemote: %w", err)
+	}
+	ar.remote = task
+
+	h.notify(ar.run, ar.hooks)
+
+	return task, nil
+}
@@ -1,28 +1,45 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"os"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/executor"
 	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/infra/metrics"
+	"github.com/pavlegich/scripts-hub/internal/infra/supervisor"
+	"github.com/pavlegich/scripts-hub/internal/infra/tracing"
+	"github.com/pavlegich/scripts-hub/internal/service/agent"
 	"github.com/pavlegich/scripts-hub/internal/service/command"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/pavlegich/scripts-hub/internal/service/rule"
 	"go.uber.org/zap"
 )
 
 // CommandWriter contains data for writing the command.
 type CommandWriter struct {
-	cmd     *entities.Command
+	mu      sync.Mutex
+	run     *entities.Run
 	service command.Service
 }
 
 // NewCommandWriter returns new CommandWriter object.
-func NewCommandWriter(ctx context.Context, name string, service command.Service) *CommandWriter {
+func NewCommandWriter(ctx context.Context, run *entities.Run, service command.Service) *CommandWriter {
 	return &CommandWriter{
-		cmd: &entities.Command{
-			Name: name,
+		run: &entities.Run{
+			ID:        run.ID,
+			CommandID: run.CommandID,
+			Name:      run.Name,
+			Attempt:   run.Attempt,
 		},
 		service: service,
 	}
@@ -30,60 +47,779 @@ func NewCommandWriter(ctx context.Context, name string, service command.Service)
 
 // Write implements writing the data into the storage.
 func (w *CommandWriter) Write(d []byte) (int, error) {
-	w.cmd.Output = string(d)
+	w.mu.Lock()
+	defer w.mu.Unlock()
 
-	err := w.service.AppendOutput(context.Background(), w.cmd)
+	w.run.Output = string(d)
+
+	start := time.Now()
+	err := w.service.AppendRunOutput(context.Background(), w.run)
+	metrics.AppendDuration.Observe(time.Since(start).Seconds())
 	if err != nil {
-		return -1, fmt.Errorf("Write: append command output failed %w", err)
+		return -1, fmt.Errorf("Write: append run output failed %w", err)
 	}
+	metrics.OutputBytes.Add(float64(len(d)))
 
 	return len(d), nil
 }
 
-// RunCommand executes the command and stores the output.
-func (h *CommandHandler) RunCommand(ctx context.Context) {
+// activeRun contains the queued or running run of the command.
+type activeRun struct {
+	run  *entities.Run
+	done chan struct{}
+
+	hooks []string
+	input []byte
+	env   []string
+	args  []string
+	depth int
+
+	// restorable is false for the run submitted with the options
+	// which are not kept in the storage.
+	restorable bool
+
+	trace  tracing.SpanContext
+	queued *tracing.Span
+
+	mu          sync.Mutex
+	proc        executor.Process
+	shim        *supervisor.Handle
+	remote      *agent.Task
+	retry       *time.Timer
+	cancelled   bool
+	interrupted bool
+}
+
+// Submit creates new run of the saved command and puts it into the command queue.
+// If the previous run of the command is still active, the overlap policy
+// defines whether the new run is skipped, waits for the previous one,
+// cancels it or runs alongside it. The parameters of the options are substituted into the script,
+// the input is passed to the standard input of the command and the environment
+// variables and arguments are added to the ones of the command.
+func (h *CommandHandler) Submit(ctx context.Context, name string, opts entities.SubmitOptions) (*entities.Run, error) {
+	if h.draining.Load() {
+		return nil, fmt.Errorf("Submit: server is draining %w", errs.ErrQueueClosed)
+	}
+	if h.maintenance.Load() {
+		return nil, fmt.Errorf("Submit: %w", errs.ErrMaintenance)
+	}
+
+	c, err := h.Service.Unload(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("Submit: get command failed %w", err)
+	}
+
+	if opts.Params != nil {
+		c.Script, err = command.ApplyParams(c.Script, opts.Params)
+		if err != nil {
+			return nil, fmt.Errorf("Submit: %w", err)
+		}
+	}
+
+	q, ok := h.queues.Get(c.Queue)
+	if !ok {
+		return nil, fmt.Errorf("Submit: queue %s %w", c.Queue, errs.ErrQueueNotFound)
+	}
+
+	active := h.activeRuns(name)
+	if len(active) > 0 {
+		switch opts.Overlap {
+		case entities.OverlapSkip:
+			run, err := h.Service.CreateRun(ctx, &entities.Run{
+				CommandID: c.ID,
+				Name:      c.Name,
+				Trigger:   opts.Trigger,
+				TriggerID: opts.TriggerID,
+				Status:    entities.RunSkipped,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("Submit: create skipped run failed %w", err)
+			}
+			metrics.ObserveRun(run)
+			h.notify(run, c.Webhooks)
+			return run, nil
+		case entities.OverlapCancel:
+			for _, ar := range active {
+				err = h.cancelRun(ctx, ar)
+				if err != nil {
+					return nil, fmt.Errorf("Submit: cancel previous run failed %w", err)
+				}
+			}
+			active = nil
+		case entities.OverlapParallel:
+			active = nil
+		}
+	}
+
+	run, err := h.enqueue(ctx, q, c, opts, "", false, active)
+	if err != nil {
+		return nil, fmt.Errorf("Submit: %w", err)
+	}
+
+	return run, nil
+}
+
+// Wait waits for the completion of the run and returns the finished run.
+func (h *CommandHandler) Wait(ctx context.Context, id int) (*entities.Run, error) {
+	val, ok := h.procs.Load(id)
+	if !ok {
+		run, err := h.Service.UnloadRun(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("Wait: get finished run failed %w", err)
+		}
+		return run, nil
+	}
+	ar := val.(*activeRun)
+
+	select {
+	case <-ar.done:
+		return ar.run, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("Wait: %w", ctx.Err())
+	}
+}
+
+// enqueue creates new queued run of the command and puts it into the queue
+// after the specified runs are finished. The place in the queue can be
+// reserved by the caller beforehand.
+func (h *CommandHandler) enqueue(ctx context.Context, q *queue.Queue, c *entities.Command,
+	opts entities.SubmitOptions, caller string, reserved bool, after []*activeRun) (*entities.Run, error) {
+	run, err := h.Service.CreateRun(ctx, &entities.Run{
+		CommandID: c.ID,
+		Name:      c.Name,
+		Trigger:   opts.Trigger,
+		TriggerID: opts.TriggerID,
+		Status:    entities.RunQueued,
+	})
+	if err != nil {
+		if reserved {
+			q.CancelReservation(caller)
+		}
+		return nil, fmt.Errorf("enqueue: create run failed %w", err)
+	}
+
+	err = h.pushRun(ctx, q, c, run, opts, caller, reserved, after)
+	if err != nil {
+		return nil, fmt.Errorf("enqueue: %w", err)
+	}
+
+	return run, nil
+}
+
+// pushRun puts the created run of the command into the queue after
+// the specified runs are finished. The input, environment variables and
+// arguments of the options are passed to every attempt of the run.
+// The time spent in the queue is recorded as the span of the trace of the context.
+func (h *CommandHandler) pushRun(ctx context.Context, q *queue.Queue, c *entities.Command, run *entities.Run,
+	opts entities.SubmitOptions, caller string, reserved bool, after []*activeRun) error {
+	ar := &activeRun{
+		run:   run,
+		done:  make(chan struct{}),
+		hooks: c.Webhooks,
+		input: opts.Input,
+		env:   opts.Env,
+		args:  opts.Args,
+		depth: opts.Depth,
+
+		restorable: len(opts.Input) == 0 && len(opts.Params) == 0 && len(opts.Env) == 0 && len(opts.Args) == 0,
+	}
+	ar.trace, ar.queued = startQueued(ctx, q, run)
+	h.procs.Store(run.ID, ar)
+
+	j := &queue.Job{
+		ID:       run.ID,
+		Caller:   caller,
+		Reserved: reserved,
+		Command:  *c,
+	}
+
+	if len(after) == 0 {
+		err := q.PushJob(j)
+		if err != nil {
+			h.finishRun(context.Background(), ar, entities.RunCancelled, nil)
+			return fmt.Errorf("pushRun: push run into queue failed %w", err)
+		}
+		return nil
+	}
+
+	go func() {
+		for _, prev := range after {
+			<-prev.done
+		}
+
+		err := q.PushJob(j)
+		if errors.Is(err, errs.ErrQueueClosed) {
+			h.keepRun(ar)
+			return
+		}
+		if err != nil {
+			runLogger(run).Error("pushRun: push waiting run into queue failed",
+				zap.Error(err))
+
+			h.finishRun(context.Background(), ar, entities.RunCancelled, nil)
+		}
+	}()
+
+	return nil
+}
+
+// RunDelayed checks the delayed runs every interval and queues the due ones
+// until the context is done.
+func (h *CommandHandler) RunDelayed(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case c, ok := <-h.jobs:
-			if !ok {
-				logger.Log.Error("RunCommand: channel is closed")
-				return
-			}
+		case now := <-ticker.C:
+			h.FireDelayed(ctx, now)
+		}
+	}
+}
 
-			bashCmd := strings.Split(c.Script, " ")
+// FireDelayed puts the delayed runs which run time has come at now into the queues.
+func (h *CommandHandler) FireDelayed(ctx context.Context, now time.Time) {
+	if h.draining.Load() || h.maintenance.Load() {
+		return
+	}
 
-			cmd := exec.CommandContext(ctx, bashCmd[0], bashCmd[1:]...)
-			if cmd.Err != nil {
-				logger.Log.With(zap.String("cmd_name", c.Name)).Error("RunCommand: set command failed",
-					zap.Error(cmd.Err), zap.String("cmd", c.Script))
+	runs, err := h.Service.DueRuns(ctx, now)
+	if err != nil {
+		logger.Log.Error("FireDelayed: get due runs failed",
+			zap.Error(err))
+		return
+	}
 
-				continue
-			}
+	for _, run := range runs {
+		ok, err := h.Service.ClaimRun(ctx, run)
+		if err != nil {
+			runLogger(run).Error("FireDelayed: claim run failed",
+				zap.Error(err))
+			continue
+		}
+		if !ok {
+			continue
+		}
 
-			cmdWriter := NewCommandWriter(ctx, c.Name, h.Service)
+		err = h.pushDelayed(ctx, run)
+		if err != nil {
+			runLogger(run).Error("FireDelayed: queue delayed run failed",
+				zap.Error(err))
+			continue
+		}
 
-			cmd.Stdout = cmdWriter
-			cmd.Stderr = cmdWriter
+		runLogger(run).Info("FireDelayed: delayed run queued")
+	}
+}
 
-			err := cmd.Start()
-			if err != nil {
-				logger.Log.With(zap.String("cmd_name", c.Name)).Error("RunCommand: start command failed",
-					zap.Error(err), zap.String("cmd", c.Script))
+// pushDelayed puts the claimed delayed run into the queue of its command.
+// The run is marked as failed if its command cannot be queued.
+func (h *CommandHandler) pushDelayed(ctx context.Context, run *entities.Run) error {
+	c, err := h.Service.Unload(ctx, run.Name)
+	if err != nil {
+		h.failRun(run)
+		return fmt.Errorf("pushDelayed: get command failed %w", err)
+	}
 
-				continue
-			}
+	q, ok := h.queues.Get(c.Queue)
+	if !ok {
+		h.failRun(run)
+		return fmt.Errorf("pushDelayed: queue %s %w", c.Queue, errs.ErrQueueNotFound)
+	}
+
+	err = h.pushRun(ctx, q, c, run, entities.SubmitOptions{}, "", false, nil)
+	if err != nil {
+		return fmt.Errorf("pushDelayed: %w", err)
+	}
 
-			h.procs.Store(c.Name, cmd)
+	return nil
+}
 
-			err = cmd.Wait()
-			if err != nil {
-				logger.Log.With(zap.String("cmd_name", c.Name)).Error("RunCommand: wait command failed",
-					zap.Error(err), zap.String("cmd", c.Script))
+// failRun stores the failed status of the run which is not active.
+func (h *CommandHandler) failRun(run *entities.Run) {
+	run.Status = entities.RunFailed
 
-				continue
-			}
+	err := h.Service.FinishRun(context.Background(), run)
+	if err != nil {
+		runLogger(run).Error("failRun: finish run failed",
+			zap.Error(err))
+		return
+	}
+	metrics.ObserveRun(run)
+
+	h.notify(run, nil)
+}
+
+// runWorker takes the commands from the queue, executes them and stores the output
+// until the stop context is done, the command taken before the stop is run to the end.
+func (h *CommandHandler) runWorker(ctx context.Context, stop context.Context, q *queue.Queue) {
+	q.Attach()
+	defer q.Detach()
+
+	for stop.Err() == nil {
+		j, ok := q.PopFunc(stop, h.takeJob, h.groups.Changed)
+		if !ok {
+			break
+		}
+
+		h.runJob(ctx, j)
+		q.Done(j)
+	}
+
+	logger.Log.Info("runWorker: queue is closed or worker is stopped",
+		zap.String("queue", q.Name()))
+}
+
+// takeJob takes the concurrency groups of the queued job for the worker.
+// The job which groups have no free slots is refused and keeps waiting
+// in the queue, so it does not hold the worker from the other jobs.
+// The job with the incorrect groups is taken to be cancelled by the worker.
+func (h *CommandHandler) takeJob(j *queue.Job) bool {
+	holder, groups := h.jobGroups(j)
+
+	ok, err := h.groups.TryAcquire(holder, groups)
+	if err != nil {
+		return true
+	}
+	return ok
+}
+
+// jobGroups returns the holder of the concurrency groups of the queued job
+// and the groups declared by the command or the commands of the pipeline.
+func (h *CommandHandler) jobGroups(j *queue.Job) (queue.GroupHolder, []string) {
+	if val, ok := h.pipelines.Load(j.ID); ok {
+		ap := val.(*activePipeline)
+		return pipelineHolder(ap), pipelineGroups(ap.commands)
+	}
+	return runHolder(j.ID, j.Command.Name), j.Command.Groups
+}
+
+// runHolder returns the holder of the concurrency groups of the run.
+func runHolder(id int, name string) queue.GroupHolder {
+	return queue.GroupHolder{ID: fmt.Sprintf("run:%d", id), Name: name}
+}
+
+// runJob executes the queued run of the command and waits for its completion.
+// The concurrency groups of the job are taken by the worker beforehand.
+func (h *CommandHandler) runJob(ctx context.Context, j *queue.Job) {
+	c := j.Command
+
+	holder, groups := h.jobGroups(j)
+	defer h.groups.Release(holder, groups)
+
+	if val, ok := h.pipelines.Load(j.ID); ok {
+		h.runPipeline(ctx, val.(*activePipeline))
+		return
+	}
+
+	val, ok := h.procs.Load(j.ID)
+	if !ok {
+		logger.Log.With(zap.String("cmd_name", c.Name)).Error("runJob: run is not active",
+			zap.Int("run_id", j.ID))
+
+		return
+	}
+	ar := val.(*activeRun)
+
+	for _, group := range c.Groups {
+		_, _, err := queue.ParseGroup(group)
+		if err != nil {
+			runLogger(ar.run).Error("runJob: acquire concurrency groups failed",
+				zap.Error(err), zap.Strings("groups", c.Groups))
+
+			h.finishRun(context.Background(), ar, entities.RunCancelled, nil)
+			return
 		}
 	}
+	if h.draining.Load() {
+		h.keepRun(ar)
+		return
+	}
+	ar.queued.End()
+
+	ctx, span := tracing.Start(tracing.ContextWithRemote(ctx, ar.trace), "run "+c.Name, tracing.KindInternal,
+		tracing.String("cmd_name", c.Name), tracing.Int("run_id", ar.run.ID))
+	defer span.End()
+
+	bashCmd := append(strings.Split(c.Script, " "), ar.args...)
+
+	if len(c.Labels) > 0 {
+		h.runRemote(ctx, ar, j, span, bashCmd)
+		return
+	}
+
+	// The detached command is run attached when the spool directory
+	// is not set on the server started after its creation.
+	if c.Detached && h.Config.SpoolDir != "" {
+		h.runDetached(ctx, ar, j, span, bashCmd)
+		return
+	}
+
+	// The shutdown signal does not stop the running command,
+	// it is interrupted by the drain after the drain timeout.
+	ctx = context.WithoutCancel(ctx)
+
+	exe, err := h.executorFor(&c)
+	if err == nil {
+		err = exe.LookPath(bashCmd[0])
+	}
+	if err != nil {
+		runLogger(ar.run).Error("runJob: set command failed",
+			zap.Error(err), zap.String("cmd", c.Script))
+
+		span.SetError(err)
+		h.finishRun(context.Background(), ar, entities.RunFailed, nil)
+		return
+	}
+	if c.Host != "" {
+		span.SetAttributes(tracing.String("ssh_host", c.Host))
+	}
+
+	spec := &executor.Spec{
+		Path: bashCmd[0],
+		Args: bashCmd[1:],
+		Env:  append(append([]string{}, ar.env...), "TRACEPARENT="+span.TraceParent()),
+	}
+	if ar.input != nil {
+		spec.Stdin = bytes.NewReader(ar.input)
+	}
+
+	_, proc, err := h.startRun(ctx, ar, exe, spec, nil)
+	span.SetAttributes(tracing.Int("attempt", ar.run.Attempt))
+	if err != nil {
+		span.SetError(err)
+		runLogger(ar.run).Error("runJob: start run failed",
+			zap.Error(err), zap.String("cmd", c.Script))
+
+		status := entities.RunFailed
+		if errors.Is(err, errs.ErrRunCancelled) {
+			status = entities.RunCancelled
+		}
+		h.finishRun(context.Background(), ar, status, nil)
+		return
+	}
+
+	exitCode, err := proc.Wait()
+	span.SetAttributes(tracing.Int("exit_code", exitCode))
+	span.SetAttributes(usageAttributes(proc.Usage())...)
+	span.SetError(err)
+
+	h.completeRun(ar, j, exitCode, err)
+}
+
+// completeRun stores the result of the finished attempt of the run
+// or retries the failed attempt according to the command retry policy.
+func (h *CommandHandler) completeRun(ar *activeRun, j *queue.Job, exitCode int, err error) {
+	c := j.Command
+
+	ar.mu.Lock()
+	cancelled, interrupted := ar.cancelled, ar.interrupted
+	ar.mu.Unlock()
+
+	switch {
+	case cancelled:
+		h.finishRun(context.Background(), ar, entities.RunCancelled, &exitCode)
+	case interrupted:
+		h.finishRun(context.Background(), ar, entities.RunInterrupted, &exitCode)
+	case err != nil && command.Retryable(c.Retry, ar.run.Attempt, exitCode):
+		runLogger(ar.run).Warn("completeRun: command attempt failed, retrying",
+			zap.Error(err), zap.String("cmd", c.Script), zap.Int("attempt", ar.run.Attempt))
+
+		h.retryRun(ar, j, exitCode)
+	case err != nil:
+		runLogger(ar.run).Error("completeRun: command failed",
+			zap.Error(err), zap.String("cmd", c.Script))
+
+		h.finishRun(context.Background(), ar, entities.RunFailed, &exitCode)
+	default:
+		h.finishRun(context.Background(), ar, entities.RunSucceeded, &exitCode)
+	}
+}
+
+// startRun marks the run as running its next attempt and starts the command
+// process by the executor unless the run has been already cancelled. If the stdout
+// file is specified, the standard output of the command is written into it instead
+// of the run output. It returns the writer of the run output and the started process.
+func (h *CommandHandler) startRun(ctx context.Context, ar *activeRun, exe executor.Executor,
+	spec *executor.Spec, stdout *os.File) (*CommandWriter, executor.Process, error) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if ar.cancelled {
+		return nil, nil, fmt.Errorf("startRun: %w", errs.ErrRunCancelled)
+	}
+
+	err := h.Service.StartRun(ctx, ar.run)
+	if err != nil {
+		return nil, nil, fmt.Errorf("startRun: mark run as running failed %w", err)
+	}
+
+	cmdWriter := NewCommandWriter(ctx, ar.run, h.Service)
+
+	spec.Stdout = cmdWriter
+	spec.Stderr = cmdWriter
+	if stdout != nil {
+		spec.Stdout = stdout
+	}
+
+	proc, err := exe.Start(ctx, spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("startRun: start command failed %w", err)
+	}
+	ar.proc = proc
+
+	h.notify(ar.run, ar.hooks)
+
+	return cmdWriter, proc, nil
+}
+
+// retryRun stores the failed attempt of the run and puts the run back
+// into its queue after the backoff delay of the command retry policy.
+func (h *CommandHandler) retryRun(ar *activeRun, j *queue.Job, exitCode int) {
+	ar.run.ExitCode = &exitCode
+
+	err := h.Service.RetryRun(context.Background(), ar.run)
+	if err != nil {
+		runLogger(ar.run).Error("retryRun: store failed attempt failed",
+			zap.Error(err))
+
+		h.finishRun(context.Background(), ar, entities.RunFailed, &exitCode)
+		return
+	}
+	h.notify(ar.run, ar.hooks)
+
+	if h.draining.Load() {
+		h.keepRun(ar)
+		return
+	}
+
+	delay := command.RetryDelay(j.Command.Retry, ar.run.Attempt)
+
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if ar.cancelled {
+		h.finishRun(context.Background(), ar, entities.RunCancelled, &exitCode)
+		return
+	}
+
+	ar.proc = nil
+	ar.shim = nil
+	ar.remote = nil
+	ar.retry = time.AfterFunc(delay, func() {
+		q, ok := h.queues.Get(j.Command.Queue)
+
+		ar.mu.Lock()
+		ar.retry = nil
+		if ok {
+			_, ar.queued = startQueued(tracing.ContextWithRemote(context.Background(), ar.trace), q, ar.run)
+		}
+		ar.mu.Unlock()
+
+		if !ok {
+			h.finishRun(context.Background(), ar, entities.RunFailed, &exitCode)
+			return
+		}
+
+		err := q.PushJob(&queue.Job{
+			ID:      j.ID,
+			Command: j.Command,
+		})
+		if errors.Is(err, errs.ErrQueueClosed) {
+			h.keepRun(ar)
+			return
+		}
+		if err != nil {
+			runLogger(ar.run).Error("retryRun: push run into queue failed",
+				zap.Error(err))
+
+			h.finishRun(context.Background(), ar, entities.RunFailed, &exitCode)
+		}
+	})
+}
+
+// finishRun stores the final status of the run, queues the commands
+// of the matching rules and removes the run from the active runs.
+func (h *CommandHandler) finishRun(ctx context.Context, ar *activeRun, status string, exitCode *int) {
+	ar.run.Status = status
+	ar.run.ExitCode = exitCode
+	h.groups.Forget(runHolder(ar.run.ID, ar.run.Name))
+
+	err := h.Service.FinishRun(ctx, ar.run)
+	if err != nil {
+		runLogger(ar.run).Error("finishRun: finish run failed",
+			zap.Error(err))
+	} else {
+		metrics.ObserveRun(ar.run)
+		h.notify(ar.run, ar.hooks)
+		go h.chain(*ar.run, ar.depth)
+	}
+
+	ar.queued.End()
+	h.procs.Delete(ar.run.ID)
+	close(ar.done)
+}
+
+// startQueued starts the span of the time the run spends in the queue.
+// It returns the span context which the spans of the run execution continue,
+// the context of the trace or of the started span if the context has no trace.
+func startQueued(ctx context.Context, q *queue.Queue, run *entities.Run) (tracing.SpanContext, *tracing.Span) {
+	trace := tracing.SpanContextFrom(ctx)
+
+	qctx, span := tracing.Start(ctx, "queue "+q.Name(), tracing.KindInternal,
+		tracing.String("cmd_name", run.Name), tracing.Int("run_id", run.ID))
+	if !trace.IsValid() {
+		trace = tracing.SpanContextFrom(qctx)
+	}
+
+	return trace, span
+}
+
+// notify stores the event of the run state change for the webhooks of the command
+// and the global webhooks, the failure to store the event does not affect the run.
+func (h *CommandHandler) notify(run *entities.Run, hooks []string) {
+	if h.webhooks == nil {
+		return
+	}
+
+	err := h.webhooks.Notify(context.Background(), run, hooks)
+	if err != nil {
+		runLogger(run).Error("notify: store run event failed",
+			zap.Error(err), zap.String("status", run.Status))
+	}
+}
+
+// chain queues the target commands of the enabled rules matching the finished run.
+// The chain of the queued runs is stopped when its depth exceeds the maximum.
+func (h *CommandHandler) chain(run entities.Run, depth int) {
+	if h.rules == nil {
+		return
+	}
+
+	rules, err := h.rules.Match(context.Background(), &run)
+	if err != nil {
+		if !errors.Is(err, errs.ErrRuleNotFound) {
+			runLogger(&run).Error("chain: match rules failed",
+				zap.Error(err))
+		}
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	if depth+1 > rule.MaxChainDepth {
+		runLogger(&run).Warn("chain: maximum chain depth reached, rules skipped",
+			zap.Int("depth", depth))
+		return
+	}
+
+	ctx := logger.WithRequestID(context.Background(), run.RequestID)
+	for _, r := range rules {
+		next, err := h.Submit(ctx, r.Target, entities.SubmitOptions{
+			Trigger: entities.TriggerRule,
+			Overlap: entities.OverlapQueue,
+			Depth:   depth + 1,
+		})
+		if err != nil {
+			runLogger(&run).Error("chain: queue rule target failed",
+				zap.Error(err), zap.Int("rule_id", r.ID), zap.String("target", r.Target))
+			continue
+		}
+
+		runLogger(next).Info("chain: rule target queued",
+			zap.Int("rule_id", r.ID), zap.Int("after_run_id", run.ID))
+	}
+}
+
+// runLogger returns the logger of the run entries with the command name,
+// the run identifier and the identifier of the request which created the run.
+func runLogger(run *entities.Run) *zap.Logger {
+	return logger.Log.With(zap.String("cmd_name", run.Name), zap.Int("run_id", run.ID),
+		zap.String("request_id", run.RequestID))
+}
+
+// cancelRun removes the queued run from its queue or stops the running one.
+func (h *CommandHandler) cancelRun(ctx context.Context, ar *activeRun) error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if ar.cancelled {
+		return nil
+	}
+	ar.cancelled = true
+
+	if ar.remote != nil {
+		err := h.agents.Signal(ar.run.ID, int(syscall.SIGKILL))
+		if err != nil && !errors.Is(err, errs.ErrRunNotFound) {
+			return fmt.Errorf("cancelRun: cancel remote command failed %w", err)
+		}
+		return nil
+	}
+
+	if ar.shim != nil {
+		err := ar.shim.Signal(syscall.SIGKILL)
+		if err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return fmt.Errorf("cancelRun: cancel detached command failed %w", err)
+		}
+		return nil
+	}
+
+	if ar.proc == nil {
+		if ar.retry != nil && ar.retry.Stop() {
+			h.finishRun(ctx, ar, entities.RunCancelled, ar.run.ExitCode)
+			return nil
+		}
+		if _, ok := h.queues.Remove(ar.run.ID); ok {
+			h.finishRun(ctx, ar, entities.RunCancelled, nil)
+			h.dropPipeline(ctx, ar.run.ID)
+		}
+		return nil
+	}
+
+	err := ar.proc.Kill()
+	if err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("cancelRun: cancel command failed %w", err)
+	}
+
+	return nil
+}
+
+// cancelQueued removes the run waiting in the queue and marks it as cancelled.
+// It returns false if the run is not waiting in the queue.
+func (h *CommandHandler) cancelQueued(ctx context.Context, id int) bool {
+	j, ok := h.queues.Remove(id)
+	if !ok {
+		return false
+	}
+
+	val, ok := h.procs.Load(j.ID)
+	if !ok {
+		return true
+	}
+	ar := val.(*activeRun)
+
+	ar.mu.Lock()
+	ar.cancelled = true
+	ar.mu.Unlock()
+
+	h.finishRun(ctx, ar, entities.RunCancelled, nil)
+	h.dropPipeline(ctx, ar.run.ID)
+
+	return true
+}
+
+// activeRuns returns the queued and running runs of the command.
+func (h *CommandHandler) activeRuns(name string) []*activeRun {
+	active := make([]*activeRun, 0)
+	h.procs.Range(func(key, val any) bool {
+		ar := val.(*activeRun)
+		if ar.run.Name == name {
+			active = append(active, ar)
+		}
+		return true
+	})
+	return active
 }
@@ -0,0 +1,155 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/sshexec/sshtest"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandHandler_HandleCreateCommandSSH(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	// SSH host served in process
+	key, pub, err := sshtest.ClientKey()
+	require.NoError(t, err)
+	srv, err := sshtest.NewServer(pub)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	cfg := &config.Config{
+		Address:       `localhost:8080`,
+		RateLimit:     1,
+		SSHHosts:      config.HostList{"test": "deploy@" + srv.Addr},
+		SSHKey:        filepath.Join(dir, "id_ed25519"),
+		SSHKnownHosts: filepath.Join(dir, "known_hosts"),
+	}
+	require.NoError(t, os.WriteFile(cfg.SSHKey, key, 0o600))
+	require.NoError(t, os.WriteFile(cfg.SSHKnownHosts, []byte(srv.KnownHosts()), 0o600))
+
+	tests := []struct {
+		name       string
+		reqBody    string
+		cancel     bool
+		wantStatus string
+		wantCode   int
+		wantOutput string
+	}{
+		{
+			name:       "succeeded",
+			reqBody:    `{"name": "ssh", "script": "echo ssh", "host": "test"}`,
+			wantStatus: entities.RunSucceeded,
+			wantOutput: "ssh\n",
+		},
+		{
+			name:       "failed",
+			reqBody:    `{"name": "ssh", "script": "ls /nonexistent", "host": "test"}`,
+			wantStatus: entities.RunFailed,
+			wantCode:   2,
+		},
+		{
+			name:       "cancelled",
+			reqBody:    `{"name": "ssh", "script": "sleep 10", "host": "test"}`,
+			cancel:     true,
+			wantStatus: entities.RunCancelled,
+			wantCode:   -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var output string
+			started := make(chan struct{}, 1)
+			finished := make(chan *entities.Run, 1)
+
+			// Mocks expected response
+			mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, c *entities.Command) (*entities.Command, error) {
+					require.Equal(t, "test", c.Host)
+					c.ID = 1
+					return c, nil
+				}).Times(1)
+			mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+				Return(&entities.Run{ID: 1, CommandID: 1, Name: "ssh", Status: entities.RunQueued}, nil).Times(1)
+			mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+					started <- struct{}{}
+					return nil
+				}).Times(1)
+			mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+					mu.Lock()
+					defer mu.Unlock()
+					output += run.Output
+					return nil
+				}).AnyTimes()
+			mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+					finished <- run
+					return nil
+				}).Times(1)
+			mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil, nil).AnyTimes()
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Submit the command
+			r := httptest.NewRequest(http.MethodPost, `http://`+cfg.Address+`/command`, bytes.NewBufferString(tt.reqBody))
+			w := httptest.NewRecorder()
+			mh.ServeHTTP(w, r)
+			require.Equal(t, http.StatusCreated, w.Code)
+
+			if tt.cancel {
+				select {
+				case <-started:
+				case <-time.After(5 * time.Second):
+					t.Fatal("run is not started")
+				}
+
+				r := httptest.NewRequest(http.MethodDelete, `http://`+cfg.Address+`/run?id=1`, nil)
+				w := httptest.NewRecorder()
+				mh.ServeHTTP(w, r)
+				require.Equal(t, http.StatusNoContent, w.Code)
+			}
+
+			// Check the status and the output streamed from the host
+			select {
+			case run := <-finished:
+				require.Equal(t, tt.wantStatus, run.Status)
+				require.NotNil(t, run.ExitCode)
+				require.Equal(t, tt.wantCode, *run.ExitCode)
+			case <-time.After(5 * time.Second):
+				t.Fatal("run is not finished")
+			}
+			if tt.wantOutput != "" {
+				mu.Lock()
+				defer mu.Unlock()
+				require.Equal(t, tt.wantOutput, output)
+			}
+		})
+	}
+}
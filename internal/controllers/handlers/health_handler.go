@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/repository"
+	"github.com/pavlegich/scripts-hub/internal/service/health"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"go.uber.org/zap"
+)
+
+// HealthHandler contains objects for work with health handlers.
+type HealthHandler struct {
+	Config  *config.Config
+	Service health.Service
+}
+
+// healthActivate activates handler for health checks and returns
+// the health service draining the server on shutdown.
+func healthActivate(ctx context.Context, r *http.ServeMux, repo repository.Repository, cfg *config.Config, queues *queue.Manager) health.Service {
+	s := health.NewHealthService(ctx, repo, queues, cfg.WorkerStallTimeout)
+	newHealthHandler(ctx, r, cfg, s)
+	return s
+}
+
+// newHealthHandler initializes handler for health checks.
+func newHealthHandler(ctx context.Context, r *http.ServeMux, cfg *config.Config, s health.Service) {
+	h := &HealthHandler{
+		Config:  cfg,
+		Service: s,
+	}
+
+	r.HandleFunc("/healthz", h.HandleLiveness)
+	r.HandleFunc("/readyz", h.HandleReadiness)
+}
+
+// HandleLiveness handles request to check that the server process is up.
+func (h *HealthHandler) HandleLiveness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandleLiveness: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"status":"` + health.StatusOK + `"}`))
+}
+
+// HandleReadiness handles request to check that the server is ready
+// to serve the requests, the failed checks are responded with 503.
+func (h *HealthHandler) HandleReadiness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandleReadiness: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	report := h.Service.Ready(ctx)
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		logger.Log.Error("HandleReadiness: marshal report failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		logger.Log.Warn("HandleReadiness: server is not ready",
+			zap.Any("checks", report.Checks))
+
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(reportJSON)
+}
@@ -0,0 +1,159 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/database"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler_HandleLiveness(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	// Controller
+	ctrl := handlers.NewController(ctx, cfg)
+	mh, err := ctrl.BuildRoute(ctx, mockRepo, nil)
+	require.NoError(t, err)
+
+	// Liveness does not depend on the storage and the shutdown
+	ctrl.Drain(ctx)
+
+	r := httptest.NewRequest(http.MethodGet, `http://`+cfg.Address+`/healthz`, nil)
+	w := httptest.NewRecorder()
+
+	mh.ServeHTTP(w, r)
+
+	resp := w.Result()
+	gotBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.JSONEq(t, `{"status": "ok"}`, string(gotBody))
+}
+
+func TestHealthHandler_HandleReadiness(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:            `localhost:8080`,
+		RateLimit:          1,
+		WorkerStallTimeout: time.Minute,
+	}
+
+	latest, err := database.LatestVersion()
+	require.NoError(t, err)
+
+	type expected struct {
+		pingErr error
+		version int64
+	}
+	tests := []struct {
+		name     string
+		expected expected
+		drain    bool
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "ready",
+			expected: expected{version: latest},
+			wantCode: http.StatusOK,
+			wantBody: `{"status": "ok", "checks": [
+				{"name": "database", "status": "ok"},
+				{"name": "migrations", "status": "ok"},
+				{"name": "workers", "status": "ok"},
+				{"name": "shutdown", "status": "ok"}
+			]}`,
+		},
+		{
+			name:     "database_down",
+			expected: expected{pingErr: errors.New("connection refused"), version: latest},
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: `{"status": "fail", "checks": [
+				{"name": "database", "status": "fail", "error": "checkDatabase: connection refused"},
+				{"name": "migrations", "status": "ok"},
+				{"name": "workers", "status": "ok"},
+				{"name": "shutdown", "status": "ok"}
+			]}`,
+		},
+		{
+			name:     "migrations_behind",
+			expected: expected{version: latest - 1},
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:     "draining",
+			expected: expected{version: latest},
+			drain:    true,
+			wantCode: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			mockRepo.EXPECT().Ping(gomock.Any()).
+				Return(tt.expected.pingErr).Times(1)
+			mockRepo.EXPECT().GetMigrationVersion(gomock.Any()).
+				Return(tt.expected.version, nil).Times(1)
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			q, _ := queues.Get(queue.DefaultQueue)
+			require.Eventually(t, func() bool {
+				return q.Attached() == q.Workers()
+			}, time.Second, 10*time.Millisecond)
+
+			if tt.drain {
+				ctrl.Drain(ctx)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, `http://`+cfg.Address+`/readyz`, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"go.uber.org/zap"
+)
+
+// HandlePipeline handles request to run or get the pipeline.
+func (h *CommandHandler) HandlePipeline(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.HandleCreatePipeline(w, r)
+	case http.MethodGet:
+		h.HandleGetPipeline(w, r)
+	default:
+		logger.Log.Error("HandlePipeline: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleCreatePipeline handles request to run the saved commands as the pipeline
+// with the output of every command piped into the input of the next one.
+func (h *CommandHandler) HandleCreatePipeline(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req entities.Pipeline
+	var buf bytes.Buffer
+
+	_, err := buf.ReadFrom(r.Body)
+	if err != nil {
+		logger.Log.Error("HandleCreatePipeline: read request body failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = json.Unmarshal(buf.Bytes(), &req)
+	if err != nil {
+		logger.Log.Error("HandleCreatePipeline: request unmarshal failed",
+			zap.String("body", buf.String()),
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if h.maintenance.Load() {
+		logger.Log.Error("HandleCreatePipeline: new runs are refused",
+			zap.Error(errs.ErrMaintenance))
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	if len(req.Commands) < 2 {
+		logger.Log.Error("HandleCreatePipeline: pipeline needs at least two commands",
+			zap.Strings("commands", req.Commands))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	cmds := make([]*entities.Command, 0, len(req.Commands))
+	for _, name := range req.Commands {
+		c, err := h.Service.Unload(ctx, name)
+		if err != nil {
+			logger.Log.With(zap.String("cmd_name", name)).Error("HandleCreatePipeline: get command failed",
+				zap.Error(err))
+
+			if errors.Is(err, errs.ErrCmdNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		cmds = append(cmds, c)
+	}
+
+	q, ok := h.queues.Get(cmds[0].Queue)
+	if !ok {
+		logger.Log.With(zap.String("cmd_name", cmds[0].Name)).Error("HandleCreatePipeline: queue not found",
+			zap.String("queue", cmds[0].Queue))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	caller := callerAddr(r, h.Config.TrustedProxies)
+	err = q.Reserve(caller)
+	if err != nil {
+		logger.Log.Error("HandleCreatePipeline: queue rejected pipeline",
+			zap.Error(err), zap.String("queue", q.Name()), zap.String("caller", caller))
+
+		writeQueueRejected(w, q, err)
+		return
+	}
+
+	p, err := h.Service.CreatePipeline(ctx, cmds)
+	if err != nil {
+		q.CancelReservation(caller)
+
+		logger.Log.Error("HandleCreatePipeline: create pipeline failed",
+			zap.Error(err), zap.Strings("commands", req.Commands))
+
+		if errors.Is(err, errs.ErrPipelineIncorrect) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = h.pushPipeline(ctx, q, p, cmds, caller, true)
+	if err != nil {
+		logger.Log.Error("HandleCreatePipeline: enqueue pipeline failed",
+			zap.Error(err), zap.String("queue", q.Name()), zap.Int("pipeline_id", p.ID))
+
+		if errors.Is(err, errs.ErrQueueClosed) {
+			writeQueueRejected(w, q, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"pipeline_id": p.ID})
+}
+
+// HandleGetPipeline handles request to get the pipeline with the runs of its steps.
+func (h *CommandHandler) HandleGetPipeline(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := queryID(r)
+	if err != nil {
+		logger.Log.Error("HandleGetPipeline: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.Service.UnloadPipeline(ctx, id)
+	if err != nil {
+		logger.Log.Error("HandleGetPipeline: get pipeline failed",
+			zap.Error(err), zap.Int("pipeline_id", id))
+
+		if errors.Is(err, errs.ErrPipelineNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	pipelineJSON, err := json.Marshal(p)
+	if err != nil {
+		logger.Log.Error("HandleGetPipeline: marshal pipeline failed",
+			zap.Error(err), zap.Int("pipeline_id", id))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(pipelineJSON)
+}
@@ -0,0 +1,280 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandHandler_HandleCreatePipeline(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 1,
+	}
+
+	commands := map[string]*entities.Command{
+		"export":    {ID: 1, Name: "export", Script: "echo hello pipeline", Queue: queue.DefaultQueue},
+		"transform": {ID: 2, Name: "transform", Script: "tr a-z A-Z", Queue: queue.DefaultQueue},
+		"yes":       {ID: 3, Name: "yes", Script: "yes", Queue: queue.DefaultQueue},
+		"head":      {ID: 4, Name: "head", Script: "head -n 2", Queue: queue.DefaultQueue},
+	}
+
+	tests := []struct {
+		name       string
+		reqBody    string
+		lookups    int
+		run        bool
+		wantCode   int
+		wantBody   string
+		wantOutput map[int]string
+		wantStatus string
+	}{
+		{
+			name:     "success",
+			reqBody:  `{"commands": ["export", "transform"]}`,
+			lookups:  2,
+			run:      true,
+			wantCode: http.StatusCreated,
+			wantBody: `{"pipeline_id": 1}`,
+			wantOutput: map[int]string{
+				1: "hello pipeline\n",
+				2: "HELLO PIPELINE\n",
+			},
+			wantStatus: entities.RunSucceeded,
+		},
+		{
+			name:     "next_step_stops_reading",
+			reqBody:  `{"commands": ["yes", "head"]}`,
+			lookups:  2,
+			run:      true,
+			wantCode: http.StatusCreated,
+			wantBody: `{"pipeline_id": 1}`,
+			wantOutput: map[int]string{
+				2: "y\ny\n",
+			},
+			wantStatus: entities.RunFailed,
+		},
+		{
+			name:     "single_command",
+			reqBody:  `{"commands": ["export"]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "incorrect_body",
+			reqBody:  `{"commands": "export"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "command_not_found",
+			reqBody:  `{"commands": ["export", "unknown"]}`,
+			lookups:  2,
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Initialize mock repository for every case,
+			// so the outputs of the previous pipelines are not mixed up
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockRepo := mocks.NewMockRepository(mockCtrl)
+
+			var mu sync.Mutex
+			outputs := make(map[int]string)
+			finished := make(chan *entities.Pipeline, 1)
+
+			// Mocks expected response
+			if tt.lookups > 0 {
+				mockRepo.EXPECT().GetCommandByName(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, name string) (*entities.Command, error) {
+						c, ok := commands[name]
+						if !ok {
+							return nil, errs.ErrCmdNotFound
+						}
+						return c, nil
+					}).Times(tt.lookups)
+			}
+			if tt.run {
+				mockRepo.EXPECT().CreatePipeline(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, p *entities.Pipeline) (*entities.Pipeline, error) {
+						p.ID = 1
+						for i, run := range p.Steps {
+							run.ID = i + 1
+						}
+						return p, nil
+					}).Times(1)
+				mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).
+					Return(nil).Times(2)
+				mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+						mu.Lock()
+						outputs[run.ID] += run.Output
+						mu.Unlock()
+						return nil
+					}).AnyTimes()
+				mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+					Return(nil).Times(2)
+				mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, nil).AnyTimes()
+				mockRepo.EXPECT().UpdatePipeline(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, p *entities.Pipeline) error {
+						if p.FinishedAt != nil {
+							finished <- p
+						}
+						return nil
+					}).Times(2)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/pipeline`
+
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(tt.reqBody))
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+
+			if !tt.run {
+				return
+			}
+
+			// Check the output of every step
+			select {
+			case p := <-finished:
+				require.Equal(t, tt.wantStatus, p.Status)
+			case <-time.After(5 * time.Second):
+				t.Fatal("pipeline is not finished")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for id, want := range tt.wantOutput {
+				require.Equal(t, want, outputs[id])
+			}
+		})
+	}
+}
+
+func TestCommandHandler_HandleGetPipeline(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	created := time.Date(2024, time.April, 24, 10, 0, 0, 0, time.UTC)
+	succeeded, failed := 0, 1
+
+	tests := []struct {
+		name     string
+		query    string
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "success",
+			query:    "?id=1",
+			wantCode: http.StatusOK,
+			wantBody: `{"id": 1, "status": "failed", "created_at": "2024-04-24T10:00:00Z", "steps": [
+			{"id": 1, "command_id": 1, "name": "export", "trigger": "pipeline", "status": "succeeded",
+			"exit_code": 0, "output": "data\n", "created_at": "2024-04-24T10:00:00Z"},
+			{"id": 2, "command_id": 2, "name": "upload", "trigger": "pipeline", "status": "failed",
+			"exit_code": 1, "output": "", "created_at": "2024-04-24T10:00:00Z"}]}`,
+		},
+		{
+			name:     "pipeline_not_found",
+			query:    "?id=2",
+			err:      errs.ErrPipelineNotFound,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "incorrect_id",
+			query:    "?id=one",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			if tt.wantCode != http.StatusBadRequest {
+				var p *entities.Pipeline
+				if tt.err == nil {
+					p = &entities.Pipeline{ID: 1, Status: entities.RunFailed, CreatedAt: created,
+						Steps: []*entities.Run{
+							{ID: 1, CommandID: 1, Name: "export", Trigger: entities.TriggerPipeline,
+								Status: entities.RunSucceeded, ExitCode: &succeeded, Output: "data\n", CreatedAt: created},
+							{ID: 2, CommandID: 2, Name: "upload", Trigger: entities.TriggerPipeline,
+								Status: entities.RunFailed, ExitCode: &failed, CreatedAt: created},
+						}}
+				}
+				mockRepo.EXPECT().GetPipelineByID(gomock.Any(), gomock.Any()).
+					Return(p, tt.err).Times(1)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/pipeline` + tt.query
+
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+		})
+	}
+}
@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/executor"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/infra/tracing"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"go.uber.org/zap"
+)
+
+// activePipeline contains the queued or running pipeline with the commands
+// and the active runs of its steps.
+type activePipeline struct {
+	pipeline *entities.Pipeline
+	commands []*entities.Command
+	steps    []*activeRun
+
+	trace  tracing.SpanContext
+	queued *tracing.Span
+}
+
+// stepLink connects the standard output of the pipeline step with the standard
+// input of the next step and copies the passing data into the output of the step run.
+type stepLink struct {
+	outR, outW *os.File
+	inR, inW   *os.File
+	done       chan struct{}
+}
+
+// newStepLink creates the pipes of the link between the pipeline steps.
+func newStepLink() (*stepLink, error) {
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("newStepLink: create output pipe failed %w", err)
+	}
+
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		outR.Close()
+		outW.Close()
+		return nil, fmt.Errorf("newStepLink: create input pipe failed %w", err)
+	}
+
+	return &stepLink{
+		outR: outR,
+		outW: outW,
+		inR:  inR,
+		inW:  inW,
+		done: make(chan struct{}),
+	}, nil
+}
+
+// copy streams the output of the step into the input of the next step and into
+// the run output until the step closes its output. When the next step stops reading
+// its input, the output of the step is closed, so the step gets the broken pipe
+// the same way as in the shell pipeline.
+func (l *stepLink) copy(out io.Writer) {
+	defer close(l.done)
+	defer l.outR.Close()
+	defer l.inW.Close()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := l.outR.Read(buf)
+		if n > 0 {
+			out.Write(buf[:n])
+
+			_, werr := l.inW.Write(buf[:n])
+			if werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// close closes the pipes of the link which data is not copied.
+func (l *stepLink) close() {
+	closeFiles([]*os.File{l.outR, l.outW, l.inR, l.inW})
+	close(l.done)
+}
+
+// pushPipeline puts the created pipeline into the queue of its first command,
+// the steps of the pipeline are run together by the single worker.
+func (h *CommandHandler) pushPipeline(ctx context.Context, q *queue.Queue, p *entities.Pipeline, cmds []*entities.Command,
+	caller string, reserved bool) error {
+	ap := &activePipeline{
+		pipeline: p,
+		commands: cmds,
+		steps:    make([]*activeRun, 0, len(p.Steps)),
+	}
+	ap.trace, ap.queued = startQueued(ctx, q, p.Steps[0])
+	ap.queued.SetAttributes(tracing.Int("pipeline_id", p.ID))
+	for i, run := range p.Steps {
+		ar := &activeRun{
+			run:   run,
+			done:  make(chan struct{}),
+			hooks: cmds[i].Webhooks,
+		}
+		h.procs.Store(run.ID, ar)
+		ap.steps = append(ap.steps, ar)
+	}
+	h.pipelines.Store(p.Steps[0].ID, ap)
+
+	err := q.PushJob(&queue.Job{
+		ID:       p.Steps[0].ID,
+		Caller:   caller,
+		Reserved: reserved,
+		Command:  *cmds[0],
+	})
+	if err != nil {
+		h.dropPipeline(context.Background(), p.Steps[0].ID)
+		return fmt.Errorf("pushPipeline: push pipeline into queue failed %w", err)
+	}
+
+	return nil
+}
+
+// runPipeline starts the commands of all the pipeline steps with the standard
+// output of each step connected to the standard input of the next one,
+// waits for their completion and stores the result of every step.
+func (h *CommandHandler) runPipeline(ctx context.Context, ap *activePipeline) {
+	p := ap.pipeline
+	if h.draining.Load() {
+		h.interruptPipeline(ap)
+		return
+	}
+	ap.queued.End()
+
+	// The shutdown signal does not stop the running steps,
+	// they are interrupted by the drain after the drain timeout.
+	ctx = context.WithoutCancel(ctx)
+
+	ctx, span := tracing.Start(tracing.ContextWithRemote(ctx, ap.trace), "pipeline", tracing.KindInternal,
+		tracing.Int("pipeline_id", p.ID))
+	defer span.End()
+
+	err := h.Service.StartPipeline(ctx, p)
+	if err != nil {
+		pipelineLogger(p).Error("runPipeline: mark pipeline as running failed",
+			zap.Error(err))
+	}
+
+	// The pipeline steps are connected by the pipes of the server host,
+	// so they are always run by the local executor.
+	exe := h.executors[executor.Local]
+
+	specs := make([]*executor.Spec, 0, len(ap.commands))
+	spans := make([]*tracing.Span, 0, len(ap.commands))
+	defer func() {
+		for _, s := range spans {
+			s.End()
+		}
+	}()
+	for i, c := range ap.commands {
+		bashCmd := strings.Split(c.Script, " ")
+
+		err = exe.LookPath(bashCmd[0])
+		if err != nil {
+			runLogger(ap.steps[i].run).Error("runPipeline: set command failed",
+				zap.Error(err), zap.String("cmd", c.Script), zap.Int("pipeline_id", p.ID))
+
+			h.finishRun(context.Background(), ap.steps[i], entities.RunFailed, nil)
+			h.cancelSteps(ap, i+1)
+			for _, ar := range ap.steps[:i] {
+				h.finishRun(context.Background(), ar, entities.RunCancelled, nil)
+			}
+			h.finishPipeline(ap)
+			return
+		}
+
+		_, stepSpan := tracing.Start(ctx, "run "+c.Name, tracing.KindInternal,
+			tracing.String("cmd_name", c.Name), tracing.Int("run_id", ap.steps[i].run.ID))
+
+		specs = append(specs, &executor.Spec{
+			Path: bashCmd[0],
+			Args: bashCmd[1:],
+			Env:  []string{"TRACEPARENT=" + stepSpan.TraceParent()},
+		})
+		spans = append(spans, stepSpan)
+	}
+
+	links := make([]*stepLink, 0, len(specs)-1)
+	for i := 0; i < len(specs)-1; i++ {
+		l, err := newStepLink()
+		if err != nil {
+			pipelineLogger(p).Error("runPipeline: connect pipeline steps failed",
+				zap.Error(err))
+
+			for _, l := range links {
+				l.close()
+			}
+			h.cancelSteps(ap, 0)
+			h.finishPipeline(ap)
+			return
+		}
+
+		specs[i+1].Stdin = l.inR
+		links = append(links, l)
+	}
+
+	procs := make([]executor.Process, 0, len(specs))
+	for i, spec := range specs {
+		var stdout *os.File
+		if i < len(links) {
+			stdout = links[i].outW
+		}
+
+		cmdWriter, proc, err := h.startRun(ctx, ap.steps[i], exe, spec, stdout)
+		if i > 0 {
+			links[i-1].inR.Close()
+		}
+		if err != nil {
+			runLogger(ap.steps[i].run).Error("runPipeline: start step failed",
+				zap.Error(err), zap.Int("pipeline_id", p.ID))
+			spans[i].SetError(err)
+
+			status := entities.RunFailed
+			if errors.Is(err, errs.ErrRunCancelled) {
+				status = entities.RunCancelled
+			}
+			h.finishRun(context.Background(), ap.steps[i], status, nil)
+			h.cancelSteps(ap, i+1)
+
+			for _, l := range links[i:] {
+				l.close()
+			}
+			for _, ar := range ap.steps[:len(procs)] {
+				err = h.cancelRun(ctx, ar)
+				if err != nil {
+					runLogger(ar.run).Error("runPipeline: cancel started step failed",
+						zap.Error(err), zap.Int("pipeline_id", p.ID))
+				}
+			}
+			break
+		}
+		procs = append(procs, proc)
+
+		if stdout != nil {
+			stdout.Close()
+			go links[i].copy(cmdWriter)
+		}
+	}
+
+	for i, proc := range procs {
+		ar := ap.steps[i]
+
+		exitCode, err := proc.Wait()
+		if i < len(links) {
+			<-links[i].done
+		}
+		spans[i].SetAttributes(tracing.Int("exit_code", exitCode))
+		spans[i].SetAttributes(usageAttributes(proc.Usage())...)
+		spans[i].SetError(err)
+
+		ar.mu.Lock()
+		cancelled, interrupted := ar.cancelled, ar.interrupted
+		ar.mu.Unlock()
+
+		switch {
+		case cancelled:
+			h.finishRun(context.Background(), ar, entities.RunCancelled, &exitCode)
+		case interrupted:
+			h.finishRun(context.Background(), ar, entities.RunInterrupted, &exitCode)
+		case err != nil:
+			runLogger(ar.run).Error("runPipeline: wait step failed",
+				zap.Error(err), zap.Int("pipeline_id", p.ID))
+
+			h.finishRun(context.Background(), ar, entities.RunFailed, &exitCode)
+		default:
+			h.finishRun(context.Background(), ar, entities.RunSucceeded, &exitCode)
+		}
+	}
+
+	h.finishPipeline(ap)
+}
+
+// closeFiles closes the opened pipe ends.
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		if f != nil {
+			f.Close()
+		}
+	}
+}
+
+// pipelineHolder returns the holder of the concurrency groups of the pipeline.
+func pipelineHolder(ap *activePipeline) queue.GroupHolder {
+	names := make([]string, 0, len(ap.commands))
+	for _, c := range ap.commands {
+		names = append(names, c.Name)
+	}
+	return queue.GroupHolder{
+		ID:   fmt.Sprintf("pipeline:%d", ap.pipeline.ID),
+		Name: strings.Join(names, " | "),
+	}
+}
+
+// pipelineGroups returns the concurrency groups of all the pipeline commands,
+// every group is taken once by the pipeline.
+func pipelineGroups(cmds []*entities.Command) []string {
+	groups := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, c := range cmds {
+		for _, group := range c.Groups {
+			name, _, err := queue.ParseGroup(group)
+			if err != nil || seen[name] {
+				continue
+			}
+			seen[name] = true
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// cancelSteps marks the pipeline steps not started yet from the specified one as cancelled.
+func (h *CommandHandler) cancelSteps(ap *activePipeline, from int) {
+	for _, ar := range ap.steps[from:] {
+		ar.mu.Lock()
+		ar.cancelled = true
+		ar.mu.Unlock()
+
+		h.finishRun(context.Background(), ar, entities.RunCancelled, nil)
+	}
+}
+
+// dropPipeline cancels all the steps of the pipeline removed from the queue
+// before its run and stores the pipeline as cancelled.
+func (h *CommandHandler) dropPipeline(ctx context.Context, id int) {
+	val, ok := h.pipelines.Load(id)
+	if !ok {
+		return
+	}
+	ap := val.(*activePipeline)
+
+	for _, ar := range ap.steps {
+		if _, ok := h.procs.Load(ar.run.ID); !ok {
+			continue
+		}
+
+		ar.mu.Lock()
+		ar.cancelled = true
+		ar.mu.Unlock()
+
+		h.finishRun(ctx, ar, entities.RunCancelled, nil)
+	}
+	h.finishPipeline(ap)
+}
+
+// interruptPipeline marks the steps of the pipeline which has not been started
+// before the server shutdown and the pipeline itself as interrupted.
+func (h *CommandHandler) interruptPipeline(ap *activePipeline) {
+	for _, ar := range ap.steps {
+		if _, ok := h.procs.Load(ar.run.ID); !ok {
+			continue
+		}
+
+		h.finishRun(context.Background(), ar, entities.RunInterrupted, nil)
+	}
+	h.finishPipeline(ap)
+}
+
+// finishPipeline stores the final status of the pipeline and removes it
+// from the active pipelines. The pipeline succeeds only if all its steps succeed,
+// the cancelled step takes precedence over the interrupted and the failed ones.
+func (h *CommandHandler) finishPipeline(ap *activePipeline) {
+	p := ap.pipeline
+	ap.queued.End()
+	h.groups.Forget(pipelineHolder(ap))
+
+	p.Status = entities.RunSucceeded
+	for _, ar := range ap.steps {
+		switch ar.run.Status {
+		case entities.RunSucceeded:
+		case entities.RunCancelled:
+			p.Status = entities.RunCancelled
+		case entities.RunInterrupted:
+			if p.Status != entities.RunCancelled {
+				p.Status = entities.RunInterrupted
+			}
+		default:
+			if p.Status != entities.RunCancelled && p.Status != entities.RunInterrupted {
+				p.Status = entities.RunFailed
+			}
+		}
+	}
+
+	err := h.Service.FinishPipeline(context.Background(), p)
+	if err != nil {
+		pipelineLogger(p).Error("finishPipeline: finish pipeline failed",
+			zap.Error(err))
+	}
+
+	h.pipelines.Delete(p.Steps[0].ID)
+}
+
+// pipelineLogger returns the logger with the fields identifying the pipeline,
+// its steps are created by the same request and share its request ID.
+func pipelineLogger(p *entities.Pipeline) *zap.Logger {
+	return logger.Log.With(zap.Int("pipeline_id", p.ID),
+		zap.String("request_id", p.Steps[0].RequestID))
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"go.uber.org/zap"
+)
+
+// HandleQueues handles request to get the depth and active jobs of the queues.
+func (h *CommandHandler) HandleQueues(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandleQueues: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	queuesJSON, err := json.Marshal(h.queues.Stats())
+	if err != nil {
+		logger.Log.Error("HandleQueues: marshal queues failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(queuesJSON)
+}
+
+// HandleGroups handles request to get the holders and the waiting commands
+// of the concurrency groups.
+func (h *CommandHandler) HandleGroups(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandleGroups: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	groupsJSON, err := json.Marshal(h.groups.Stats())
+	if err != nil {
+		logger.Log.Error("HandleGroups: marshal groups failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(groupsJSON)
+}
+
+// HandlePauseQueues handles request to stop handing out the waiting jobs
+// of the queue specified by name or of all the queues.
+func (h *CommandHandler) HandlePauseQueues(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logger.Log.Error("HandlePauseQueues: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, err := queryValue(r, "name", false)
+	if err != nil {
+		logger.Log.Error("HandlePauseQueues: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !h.queues.Pause(name) {
+		logger.Log.Error("HandlePauseQueues: queue not found",
+			zap.String("queue", name))
+
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleResumeQueues handles request to continue handing out the waiting jobs
+// of the queue specified by name or of all the queues.
+func (h *CommandHandler) HandleResumeQueues(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logger.Log.Error("HandleResumeQueues: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, err := queryValue(r, "name", false)
+	if err != nil {
+		logger.Log.Error("HandleResumeQueues: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !h.queues.Resume(name) {
+		logger.Log.Error("HandleResumeQueues: queue not found",
+			zap.String("queue", name))
+
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleJobs handles request to get the waiting jobs with their positions
+// and estimated start time.
+func (h *CommandHandler) HandleJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandleJobs: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	jobsJSON, err := json.Marshal(h.queues.Jobs())
+	if err != nil {
+		logger.Log.Error("HandleJobs: marshal jobs failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jobsJSON)
+}
+
+// HandleCancelJob handles request to remove the job from the queue before it starts.
+func (h *CommandHandler) HandleCancelJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		logger.Log.Error("HandleCancelJob: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := queryID(r)
+	if err != nil {
+		logger.Log.Error("HandleCancelJob: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !h.cancelQueued(r.Context(), id) {
+		logger.Log.Error("HandleCancelJob: job not found in queues",
+			zap.Int("job_id", id))
+
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	logger.Log.Info("HandleCancelJob: job cancelled",
+		zap.Int("job_id", id))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleMoveJobToFront handles request to move the waiting job to the front of its queue.
+func (h *CommandHandler) HandleMoveJobToFront(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logger.Log.Error("HandleMoveJobToFront: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := queryID(r)
+	if err != nil {
+		logger.Log.Error("HandleMoveJobToFront: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !h.queues.MoveToFront(id) {
+		logger.Log.Error("HandleMoveJobToFront: job not found in queues",
+			zap.Int("job_id", id))
+
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// queryValue returns the value of the only allowed query key.
+// If the key is not required, the empty value is returned for the request without queries.
+func queryValue(r *http.Request, key string, required bool) (string, error) {
+	queries := r.URL.Query()
+	if len(queries) == 0 {
+		if required {
+			return "", fmt.Errorf("queryValue: query %s not found", key)
+		}
+		return "", nil
+	}
+
+	for val := range queries {
+		if val != key {
+			return "", fmt.Errorf("queryValue: incorrect query %s", val)
+		}
+	}
+
+	if len(queries[key]) != 1 {
+		return "", fmt.Errorf("queryValue: incorrect number of %s queries", key)
+	}
+
+	return queries[key][0], nil
+}
+
+// queryID returns the job or run identifier from the request query.
+func queryID(r *http.Request) (int, error) {
+	val, err := queryValue(r, "id", true)
+	if err != nil {
+		return 0, fmt.Errorf("queryID: %w", err)
+	}
+
+	id, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("queryID: incorrect id %w", err)
+	}
+
+	return id, nil
+}
+
+// writeQueueRejected writes the response for the job rejected by the queue
+// with the time after which the client should retry.
+func writeQueueRejected(w http.ResponseWriter, q *queue.Queue, err error) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(q.RetryAfter().Seconds())))
+
+	if errors.Is(err, errs.ErrQueueCallerLimit) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
+
+// callerAddr returns the host of the client that made the request.
+// The request passed through the trusted proxies is attributed to the nearest
+// untrusted address of the X-Forwarded-For header, the header of other
+// clients is ignored, so they cannot choose the caller they are counted as.
+func callerAddr(r *http.Request, proxies config.ProxyList) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !proxies.Trusted(addr) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+		host = addr.Unmap().String()
+		if !proxies.Trusted(addr) {
+			break
+		}
+	}
+	return host
+}
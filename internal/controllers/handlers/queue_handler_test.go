@@ -0,0 +1,296 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandHandler_HandleQueues(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+		Queues:  config.QueueLimits{"heavy": 1},
+	}
+
+	type args struct {
+		method string
+		queued []entities.Command
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "success",
+			args: args{
+				method: http.MethodGet,
+				queued: []entities.Command{
+					{Name: "first", Queue: "heavy"},
+					{Name: "second", Queue: "heavy"},
+				},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[{"name": "default", "workers": 0, "depth": 0, "active": 0, "paused": false},
+			{"name": "heavy", "workers": 1, "depth": 2, "active": 0, "paused": true}]`,
+		},
+		{
+			name: "incorrect_method",
+			args: args{
+				method: http.MethodPost,
+			},
+			wantCode: http.StatusMethodNotAllowed,
+			wantBody: ``,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The controller starts the workers of the queues,
+			// so the queue is paused to keep the pushed jobs in it
+			queues := queue.NewManager(ctx, cfg)
+			for _, c := range tt.args.queued {
+				q, ok := queues.Get(c.Queue)
+				require.True(t, ok)
+				q.Pause()
+				q.Push(c)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, nil, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/queues`
+
+			r := httptest.NewRequest(tt.args.method, url, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+		})
+	}
+}
+
+func TestCommandHandler_HandleGroups(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	type args struct {
+		method string
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "success",
+			args: args{
+				method: http.MethodGet,
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[]`,
+		},
+		{
+			name: "incorrect_method",
+			args: args{
+				method: http.MethodDelete,
+			},
+			wantCode: http.StatusMethodNotAllowed,
+			wantBody: ``,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, nil, nil)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/groups`
+
+			r := httptest.NewRequest(tt.args.method, url, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+		})
+	}
+}
+
+func TestCommandHandler_HandleJobs(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	type args struct {
+		method string
+		path   string
+		query  string
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantCode int
+		wantJobs []string
+	}{
+		{
+			name: "list",
+			args: args{
+				method: http.MethodGet,
+				path:   "/jobs",
+			},
+			wantCode: http.StatusOK,
+			wantJobs: []string{"first", "second", "third"},
+		},
+		{
+			name: "cancel",
+			args: args{
+				method: http.MethodDelete,
+				path:   "/job",
+				query:  "id=2",
+			},
+			wantCode: http.StatusNoContent,
+			wantJobs: []string{"first", "third"},
+		},
+		{
+			name: "cancel_not_found",
+			args: args{
+				method: http.MethodDelete,
+				path:   "/job",
+				query:  "id=4",
+			},
+			wantCode: http.StatusNotFound,
+			wantJobs: []string{"first", "second", "third"},
+		},
+		{
+			name: "cancel_incorrect_id",
+			args: args{
+				method: http.MethodDelete,
+				path:   "/job",
+				query:  "id=second",
+			},
+			wantCode: http.StatusBadRequest,
+			wantJobs: []string{"first", "second", "third"},
+		},
+		{
+			name: "move_to_front",
+			args: args{
+				method: http.MethodPost,
+				path:   "/job/front",
+				query:  "id=3",
+			},
+			wantCode: http.StatusNoContent,
+			wantJobs: []string{"third", "first", "second"},
+		},
+		{
+			name: "move_to_front_no_query",
+			args: args{
+				method: http.MethodPost,
+				path:   "/job/front",
+			},
+			wantCode: http.StatusBadRequest,
+			wantJobs: []string{"first", "second", "third"},
+		},
+		{
+			name: "pause_unknown_queue",
+			args: args{
+				method: http.MethodPost,
+				path:   "/queues/pause",
+				query:  "name=unknown",
+			},
+			wantCode: http.StatusNotFound,
+			wantJobs: []string{"first", "second", "third"},
+		},
+		{
+			name: "resume_incorrect_method",
+			args: args{
+				method: http.MethodGet,
+				path:   "/queues/resume",
+			},
+			wantCode: http.StatusMethodNotAllowed,
+			wantJobs: []string{"first", "second", "third"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The default queue has no workers with the zero rate limit
+			queues := queue.NewManager(ctx, cfg)
+			q, ok := queues.Get(queue.DefaultQueue)
+			require.True(t, ok)
+			q.Push(entities.Command{ID: 1, Name: "first"})
+			q.Push(entities.Command{ID: 2, Name: "second"})
+			q.Push(entities.Command{ID: 3, Name: "third"})
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, nil, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + tt.args.path
+			if tt.args.query != "" {
+				url += "?" + tt.args.query
+			}
+
+			r := httptest.NewRequest(tt.args.method, url, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			// Check status code and the queue
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+
+			gotJobs := make([]string, 0)
+			for _, j := range queues.Jobs() {
+				gotJobs = append(gotJobs, j.Name)
+			}
+			require.Equal(t, tt.wantJobs, gotJobs)
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/repository"
+	"github.com/pavlegich/scripts-hub/internal/service/rule"
+	"go.uber.org/zap"
+)
+
+// RuleHandler contains objects for work with rule handlers.
+type RuleHandler struct {
+	Config  *config.Config
+	Service rule.Service
+}
+
+// rulesActivate activates handler for rule object and returns
+// the rule service matching the finished runs.
+func rulesActivate(ctx context.Context, r *http.ServeMux, repo repository.Repository, cfg *config.Config) rule.Service {
+	s := rule.NewRuleService(ctx, repo)
+	newRuleHandler(ctx, r, cfg, s)
+	return s
+}
+
+// newRuleHandler initializes handler for rule object.
+func newRuleHandler(ctx context.Context, r *http.ServeMux, cfg *config.Config, s rule.Service) {
+	h := &RuleHandler{
+		Config:  cfg,
+		Service: s,
+	}
+
+	r.HandleFunc("/rule", h.HandleCreateRule)
+	r.HandleFunc("/rules", h.HandleRules)
+	r.HandleFunc("/rule/enable", h.HandleEnableRule)
+	r.HandleFunc("/rule/disable", h.HandleDisableRule)
+}
+
+// HandleCreateRule handles request to create new rule.
+func (h *RuleHandler) HandleCreateRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logger.Log.Error("HandleCreateRule: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	var req entities.Rule
+	var buf bytes.Buffer
+
+	_, err := buf.ReadFrom(r.Body)
+	if err != nil {
+		logger.Log.Error("HandleCreateRule: read request body failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = json.Unmarshal(buf.Bytes(), &req)
+	if err != nil {
+		logger.Log.Error("HandleCreateRule: request unmarshal failed",
+			zap.String("body", buf.String()),
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.Service.Create(ctx, &req)
+	if err != nil {
+		logger.Log.With(zap.String("cmd_name", req.Command)).Error("HandleCreateRule: create rule failed",
+			zap.Error(err), zap.String("on", req.On), zap.String("target", req.Target))
+
+		switch {
+		case errors.Is(err, errs.ErrRuleIncorrect):
+			w.WriteHeader(http.StatusBadRequest)
+		case errors.Is(err, errs.ErrCmdNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, errs.ErrRuleCycle), errors.Is(err, errs.ErrRuleAlreadyExists):
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	respJSON, err := json.Marshal(map[string]int{"rule_id": id})
+	if err != nil {
+		logger.Log.Error("HandleCreateRule: marshal response failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respJSON)
+}
+
+// HandleRules handles request to get all the rules.
+func (h *RuleHandler) HandleRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandleRules: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	rules, err := h.Service.List(ctx)
+	if err != nil {
+		logger.Log.Error("HandleRules: get rules failed",
+			zap.Error(err))
+
+		if errors.Is(err, errs.ErrRuleNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		logger.Log.Error("HandleRules: marshal rules failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(rulesJSON)
+}
+
+// HandleEnableRule handles request to enable the rule.
+func (h *RuleHandler) HandleEnableRule(w http.ResponseWriter, r *http.Request) {
+	h.setRuleEnabled(w, r, true)
+}
+
+// HandleDisableRule handles request to disable the rule, its target
+// is not queued until the rule is enabled again.
+func (h *RuleHandler) HandleDisableRule(w http.ResponseWriter, r *http.Request) {
+	h.setRuleEnabled(w, r, false)
+}
+
+// setRuleEnabled enables or disables the rule from the request query.
+func (h *RuleHandler) setRuleEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	if r.Method != http.MethodPost {
+		logger.Log.Error("setRuleEnabled: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	id, err := queryID(r)
+	if err != nil {
+		logger.Log.Error("setRuleEnabled: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = h.Service.SetEnabled(ctx, id, enabled)
+	if err != nil {
+		logger.Log.Error("setRuleEnabled: set rule enabled failed",
+			zap.Error(err), zap.Int("rule_id", id), zap.Bool("enabled", enabled))
+
+		switch {
+		case errors.Is(err, errs.ErrRuleNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, errs.ErrRuleCycle):
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,349 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleHandler_HandleCreateRule(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	rules := []*entities.Rule{
+		{ID: 1, Command: "build", On: entities.RunSucceeded, Target: "deploy", Enabled: true},
+	}
+
+	type expected struct {
+		rules  bool
+		create bool
+		err    error
+	}
+	tests := []struct {
+		name     string
+		body     string
+		expected expected
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "created",
+			body:     `{"command": "deploy", "on": "failed", "target": "rollback"}`,
+			expected: expected{rules: true, create: true},
+			wantCode: http.StatusCreated,
+			wantBody: `{"rule_id": 2}`,
+		},
+		{
+			name:     "command_not_found",
+			body:     `{"command": "deploy", "on": "failed", "target": "unknown"}`,
+			expected: expected{rules: true, create: true, err: errs.ErrCmdNotFound},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "rule_exists",
+			body:     `{"command": "build", "on": "succeeded", "target": "deploy"}`,
+			expected: expected{rules: true, create: true, err: errs.ErrRuleAlreadyExists},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "cycle",
+			body:     `{"command": "deploy", "on": "succeeded", "target": "build"}`,
+			expected: expected{rules: true},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "timed_out_event",
+			body:     `{"command": "build", "on": "timed_out", "target": "deploy"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "incorrect_body",
+			body:     `{"command": "build",`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			if tt.expected.rules {
+				mockRepo.EXPECT().GetAllRules(gomock.Any()).
+					Return(rules, nil).Times(1)
+			}
+			if tt.expected.create {
+				mockRepo.EXPECT().CreateRule(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, r *entities.Rule) (*entities.Rule, error) {
+						if tt.expected.err != nil {
+							return nil, tt.expected.err
+						}
+						r.ID = 2
+						return r, nil
+					}).Times(1)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/rule`
+
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+		})
+	}
+}
+
+func TestRuleHandler_HandleRules(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	created := time.Date(2024, time.May, 4, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rules    []*entities.Rule
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "success",
+			rules: []*entities.Rule{
+				{ID: 1, Command: "build", On: entities.RunSucceeded, Target: "deploy", Enabled: false, CreatedAt: created},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[{"id": 1, "command": "build", "on": "succeeded", "target": "deploy", "enabled": false,
+			"created_at": "2024-05-04T10:00:00Z"}]`,
+		},
+		{
+			name:     "rules_not_found",
+			err:      errs.ErrRuleNotFound,
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			mockRepo.EXPECT().GetAllRules(gomock.Any()).
+				Return(tt.rules, tt.err).Times(1)
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/rules`
+
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+		})
+	}
+}
+
+func TestRuleHandler_HandleDisableRule(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		query    string
+		err      error
+		wantCode int
+	}{
+		{
+			name:     "disabled",
+			method:   http.MethodPost,
+			query:    "?id=1",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "rule_not_found",
+			method:   http.MethodPost,
+			query:    "?id=2",
+			err:      errs.ErrRuleNotFound,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "incorrect_id",
+			method:   http.MethodPost,
+			query:    "?id=one",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "incorrect_method",
+			method:   http.MethodGet,
+			query:    "?id=1",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			if tt.method == http.MethodPost && tt.wantCode != http.StatusBadRequest {
+				mockRepo.EXPECT().SetRuleEnabled(gomock.Any(), gomock.Any(), false).
+					Return(tt.err).Times(1)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/rule/disable` + tt.query
+
+			r := httptest.NewRequest(tt.method, url, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Check status code
+			resp := w.Result()
+			defer resp.Body.Close()
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestCommandHandler_HandleCreateCommandRules(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 1,
+	}
+
+	finished := make(chan *entities.Run, 2)
+
+	// Mocks expected response
+	mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+		Return(&entities.Command{ID: 1, Name: "pwd", Script: "pwd"}, nil).Times(1)
+	mockRepo.EXPECT().GetCommandByName(gomock.Any(), "ls").
+		Return(&entities.Command{ID: 2, Name: "ls", Script: "ls"}, nil).Times(1)
+	mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, run *entities.Run) (*entities.Run, error) {
+			run.ID = run.CommandID
+			return run, nil
+		}).Times(2)
+	mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+			finished <- run
+			return nil
+		}).Times(2)
+	mockRepo.EXPECT().GetEnabledRules(gomock.Any(), "pwd", entities.RunSucceeded).
+		Return([]*entities.Rule{{ID: 1, Command: "pwd", On: entities.RunSucceeded, Target: "ls", Enabled: true}}, nil).Times(1)
+	mockRepo.EXPECT().GetEnabledRules(gomock.Any(), "ls", entities.RunSucceeded).
+		Return(nil, nil).AnyTimes()
+
+	// Controller
+	ctrl := handlers.NewController(ctx, cfg)
+	queues := queue.NewManager(ctx, cfg)
+	mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+	require.NoError(t, err)
+
+	// Form new request
+	url := `http://` + cfg.Address + `/command`
+	body := `{"name": "pwd", "script": "pwd"}`
+
+	r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	mh.ServeHTTP(w, r)
+
+	// Check status code
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// Check the chained run
+	for _, want := range []string{"pwd", "ls"} {
+		select {
+		case run := <-finished:
+			require.Equal(t, want, run.Name)
+			require.Equal(t, entities.RunSucceeded, run.Status)
+			if want == "ls" {
+				require.Equal(t, entities.TriggerRule, run.Trigger)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("run of %s is not finished", want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"go.uber.org/zap"
+)
+
+// HandleRuns handles request to get the runs history of the command.
+func (h *CommandHandler) HandleRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandleRuns: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	cmdName, err := queryValue(r, "name", true)
+	if err != nil {
+		logger.Log.Error("HandleRuns: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	runs, err := h.Service.ListRuns(ctx, cmdName)
+	if err != nil {
+		logger.Log.With(zap.String("cmd_name", cmdName)).
+			Error("HandleRuns: get runs list failed", zap.Error(err))
+
+		if errors.Is(err, errs.ErrRunNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	runsJSON, err := json.Marshal(runs)
+	if err != nil {
+		logger.Log.With(zap.String("cmd_name", cmdName)).
+			Error("HandleRuns: marshal runs failed", zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(runsJSON)
+}
+
+// HandleRun handles request to get or cancel the single run of the command.
+func (h *CommandHandler) HandleRun(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.HandleGetRun(w, r)
+	case http.MethodDelete:
+		h.HandleCancelRun(w, r)
+	default:
+		logger.Log.Error("HandleRun: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleGetRun handles request to get the single run of the command.
+func (h *CommandHandler) HandleGetRun(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := queryID(r)
+	if err != nil {
+		logger.Log.Error("HandleGetRun: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.Service.UnloadRun(ctx, id)
+	if err != nil {
+		logger.Log.Error("HandleGetRun: get run failed",
+			zap.Error(err), zap.Int("run_id", id))
+
+		if errors.Is(err, errs.ErrRunNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	runJSON, err := json.Marshal(run)
+	if err != nil {
+		logger.Log.Error("HandleGetRun: marshal run failed",
+			zap.Error(err), zap.Int("run_id", id))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(runJSON)
+}
+
+// HandleCancelRun handles request to cancel the delayed, queued or running run of the command.
+func (h *CommandHandler) HandleCancelRun(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := queryID(r)
+	if err != nil {
+		logger.Log.Error("HandleCancelRun: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	val, ok := h.procs.Load(id)
+	if ok {
+		err = h.cancelRun(ctx, val.(*activeRun))
+		if err != nil {
+			logger.Log.Error("HandleCancelRun: cancel active run failed",
+				zap.Error(err), zap.Int("run_id", id))
+
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	err = h.Service.CancelPendingRun(ctx, id)
+	if err != nil {
+		logger.Log.Error("HandleCancelRun: cancel delayed run failed",
+			zap.Error(err), zap.Int("run_id", id))
+
+		if errors.Is(err, errs.ErrRunNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandlePendingRuns handles request to get the delayed runs waiting for their run time.
+func (h *CommandHandler) HandlePendingRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandlePendingRuns: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	runs, err := h.Service.ListPendingRuns(ctx)
+	if err != nil {
+		logger.Log.Error("HandlePendingRuns: get pending runs list failed",
+			zap.Error(err))
+
+		if errors.Is(err, errs.ErrRunNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	runsJSON, err := json.Marshal(runs)
+	if err != nil {
+		logger.Log.Error("HandlePendingRuns: marshal runs failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(runsJSON)
+}
@@ -0,0 +1,232 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandHandler_HandleRuns(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	created := time.Date(2024, time.April, 15, 10, 0, 0, 0, time.UTC)
+	exitCode := 0
+
+	type expList struct {
+		want bool
+		runs []*entities.Run
+		err  error
+	}
+	tests := []struct {
+		name     string
+		query    string
+		expected expList
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:  "success",
+			query: "?name=pwd",
+			expected: expList{
+				want: true,
+				runs: []*entities.Run{
+					{ID: 2, CommandID: 1, Name: "pwd", Trigger: entities.TriggerSchedule,
+						Status: entities.RunSkipped, CreatedAt: created},
+					{ID: 1, CommandID: 1, Name: "pwd", Trigger: entities.TriggerAPI,
+						Status: entities.RunSucceeded, ExitCode: &exitCode, Output: "/\n", CreatedAt: created},
+				},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[{"id": 2, "command_id": 1, "name": "pwd", "trigger": "schedule", "status": "skipped",
+			"output": "", "created_at": "2024-04-15T10:00:00Z"},
+			{"id": 1, "command_id": 1, "name": "pwd", "trigger": "api", "status": "succeeded", "exit_code": 0,
+			"output": "/\n", "created_at": "2024-04-15T10:00:00Z"}]`,
+		},
+		{
+			name:     "name_not_specified",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:  "runs_not_found",
+			query: "?name=unknown",
+			expected: expList{
+				want: true,
+				err:  errs.ErrRunNotFound,
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			if tt.expected.want {
+				mockRepo.EXPECT().GetRunsByCommandName(gomock.Any(), gomock.Any()).
+					Return(tt.expected.runs, tt.expected.err).Times(1)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/runs` + tt.query
+
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+		})
+	}
+}
+
+func TestCommandHandler_HandleCancelRun(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		moved    bool
+		wantCode int
+	}{
+		{
+			name:     "pending_run_cancelled",
+			query:    "?id=5",
+			moved:    true,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "run_not_pending",
+			query:    "?id=6",
+			moved:    false,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "incorrect_id",
+			query:    "?id=five",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			if tt.wantCode != http.StatusBadRequest {
+				mockRepo.EXPECT().MoveRunStatus(gomock.Any(), gomock.Any(), entities.RunPending).
+					DoAndReturn(func(ctx context.Context, run *entities.Run, from string) (bool, error) {
+						require.Equal(t, entities.RunCancelled, run.Status)
+						require.NotNil(t, run.FinishedAt)
+						return tt.moved, nil
+					}).Times(1)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/run` + tt.query
+
+			r := httptest.NewRequest(http.MethodDelete, url, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Check status code
+			resp := w.Result()
+			defer resp.Body.Close()
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestCommandHandler_HandlePendingRuns(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	created := time.Date(2024, time.April, 17, 10, 0, 0, 0, time.UTC)
+	runAt := time.Date(2024, time.April, 17, 23, 0, 0, 0, time.UTC)
+
+	mockRepo.EXPECT().GetPendingRuns(gomock.Any()).
+		Return([]*entities.Run{
+			{ID: 5, CommandID: 1, Name: "backup", Trigger: entities.TriggerAPI,
+				Status: entities.RunPending, CreatedAt: created, RunAt: &runAt},
+		}, nil).Times(1)
+
+	// Controller
+	ctrl := handlers.NewController(ctx, cfg)
+	queues := queue.NewManager(ctx, cfg)
+	mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+	require.NoError(t, err)
+
+	// Form new request
+	url := `http://` + cfg.Address + `/runs/pending`
+
+	r := httptest.NewRequest(http.MethodGet, url, nil)
+	w := httptest.NewRecorder()
+
+	mh.ServeHTTP(w, r)
+
+	// Get response
+	resp := w.Result()
+	gotBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Check status code and body
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.JSONEq(t, `[{"id": 5, "command_id": 1, "name": "backup", "trigger": "api", "status": "pending",
+	"output": "", "created_at": "2024-04-17T10:00:00Z", "run_at": "2024-04-17T23:00:00Z"}]`, string(gotBody))
+}
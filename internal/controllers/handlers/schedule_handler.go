@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/repository"
+	"github.com/pavlegich/scripts-hub/internal/service/schedule"
+	"go.uber.org/zap"
+)
+
+// defaultNextRuns is the number of the next run times of the schedules shown by default.
+const defaultNextRuns = 5
+
+// ScheduleHandler contains objects for work with schedule handlers.
+type ScheduleHandler struct {
+	Config  *config.Config
+	Service schedule.Service
+}
+
+// schedulesActivate activates handler for schedule object.
+func schedulesActivate(ctx context.Context, r *http.ServeMux, repo repository.Repository, cfg *config.Config, submitter schedule.Submitter) {
+	s := schedule.NewScheduleService(ctx, repo)
+	newScheduleHandler(ctx, r, cfg, s, submitter)
+}
+
+// newScheduleHandler initializes handler for schedule object
+// and starts the scheduler if it is enabled.
+func newScheduleHandler(ctx context.Context, r *http.ServeMux, cfg *config.Config, s schedule.Service, submitter schedule.Submitter) {
+	h := &ScheduleHandler{
+		Config:  cfg,
+		Service: s,
+	}
+
+	r.HandleFunc("/schedule", h.HandleSchedule)
+	r.HandleFunc("/schedules", h.HandleSchedules)
+
+	if cfg.ScheduleInterval <= 0 {
+		return
+	}
+	go schedule.NewScheduler(ctx, s, submitter, cfg.ScheduleInterval).Run(ctx)
+}
+
+// HandleSchedule handles request to create or delete the schedule.
+func (h *ScheduleHandler) HandleSchedule(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.HandleCreateSchedule(w, r)
+	case http.MethodDelete:
+		h.HandleDeleteSchedule(w, r)
+	default:
+		logger.Log.Error("HandleSchedule: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleCreateSchedule handles request to create new schedule of the saved command.
+func (h *ScheduleHandler) HandleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req entities.Schedule
+	var buf bytes.Buffer
+
+	_, err := buf.ReadFrom(r.Body)
+	if err != nil {
+		logger.Log.Error("HandleCreateSchedule: read request body failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = json.Unmarshal(buf.Bytes(), &req)
+	if err != nil {
+		logger.Log.Error("HandleCreateSchedule: request unmarshal failed",
+			zap.String("body", buf.String()),
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" || req.Cron == "" {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateSchedule: command name or cron empty",
+			zap.String("cron", req.Cron))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	scheduleID, err := h.Service.Create(ctx, &req)
+	if err != nil {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateSchedule: create schedule failed",
+			zap.Error(err), zap.String("cron", req.Cron))
+
+		switch {
+		case errors.Is(err, errs.ErrScheduleIncorrect):
+			w.WriteHeader(http.StatusBadRequest)
+		case errors.Is(err, errs.ErrCmdNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, errs.ErrScheduleAlreadyExists):
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"schedule_id": scheduleID})
+}
+
+// HandleDeleteSchedule handles request to delete the schedule of the command.
+func (h *ScheduleHandler) HandleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	cmdName, err := queryValue(r, "name", true)
+	if err != nil {
+		logger.Log.Error("HandleDeleteSchedule: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = h.Service.Delete(ctx, cmdName)
+	if err != nil {
+		logger.Log.With(zap.String("cmd_name", cmdName)).
+			Error("HandleDeleteSchedule: delete schedule failed", zap.Error(err))
+
+		if errors.Is(err, errs.ErrScheduleNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleSchedules handles request to get list of schedules with their next run times.
+func (h *ScheduleHandler) HandleSchedules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandleSchedules: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	count := defaultNextRuns
+	val, err := queryValue(r, "count", false)
+	if err == nil && val != "" {
+		count, err = strconv.Atoi(val)
+		if err == nil && count < 1 {
+			err = errors.New("count must be positive")
+		}
+	}
+	if err != nil {
+		logger.Log.Error("HandleSchedules: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	schedules, err := h.Service.List(ctx, count)
+	if err != nil {
+		logger.Log.Error("HandleSchedules: get schedules list failed",
+			zap.Error(err))
+
+		if errors.Is(err, errs.ErrScheduleNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	schedulesJSON, err := json.Marshal(schedules)
+	if err != nil {
+		logger.Log.Error("HandleSchedules: marshal schedules failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(schedulesJSON)
+}
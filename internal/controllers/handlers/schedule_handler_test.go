@@ -0,0 +1,235 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleHandler_HandleCreateSchedule(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	type expCreate struct {
+		want bool
+		sch  *entities.Schedule
+		err  error
+	}
+	type args struct {
+		reqBody string
+	}
+	tests := []struct {
+		name     string
+		args     args
+		expected expCreate
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "success",
+			args: args{
+				reqBody: `{"name": "pwd", "cron": "*/5 * * * *", "timezone": "Europe/Moscow", "overlap": "queue"}`,
+			},
+			expected: expCreate{
+				want: true,
+				sch:  &entities.Schedule{ID: 1},
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `{"schedule_id": 1}`,
+		},
+		{
+			name: "empty_cron",
+			args: args{
+				reqBody: `{"name": "pwd"}`,
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "incorrect_cron",
+			args: args{
+				reqBody: `{"name": "pwd", "cron": "61 * * * *"}`,
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "incorrect_overlap",
+			args: args{
+				reqBody: `{"name": "pwd", "cron": "* * * * *", "overlap": "parallel"}`,
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "command_not_found",
+			args: args{
+				reqBody: `{"name": "unknown", "cron": "* * * * *"}`,
+			},
+			expected: expCreate{
+				want: true,
+				err:  errs.ErrCmdNotFound,
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "schedule_already_exists",
+			args: args{
+				reqBody: `{"name": "pwd", "cron": "* * * * *"}`,
+			},
+			expected: expCreate{
+				want: true,
+				err:  errs.ErrScheduleAlreadyExists,
+			},
+			wantCode: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			if tt.expected.want {
+				mockRepo.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).
+					Return(tt.expected.sch, tt.expected.err).Times(1)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/schedule`
+
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(tt.args.reqBody))
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+		})
+	}
+}
+
+func TestScheduleHandler_HandleSchedules(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	type expList struct {
+		want bool
+		schs []*entities.Schedule
+		err  error
+	}
+	tests := []struct {
+		name     string
+		query    string
+		expected expList
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:  "success",
+			query: "?count=2",
+			expected: expList{
+				want: true,
+				schs: []*entities.Schedule{
+					{
+						ID:        1,
+						Name:      "pwd",
+						Cron:      "0 */12 * * *",
+						Timezone:  "UTC",
+						Overlap:   entities.OverlapSkip,
+						NextRunAt: time.Date(2024, time.April, 15, 12, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[{"id": 1, "name": "pwd", "cron": "0 */12 * * *", "timezone": "UTC", "overlap": "skip",
+			"next_run_at": "2024-04-15T12:00:00Z", "next_runs": ["2024-04-15T12:00:00Z", "2024-04-16T00:00:00Z"]}]`,
+		},
+		{
+			name:     "incorrect_count",
+			query:    "?count=0",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "schedules_not_found",
+			expected: expList{
+				want: true,
+				err:  errs.ErrScheduleNotFound,
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			if tt.expected.want {
+				mockRepo.EXPECT().GetAllSchedules(gomock.Any()).
+					Return(tt.expected.schs, tt.expected.err).Times(1)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/schedules` + tt.query
+
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+		})
+	}
+}
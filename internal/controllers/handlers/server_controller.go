@@ -6,35 +6,94 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/pavlegich/scripts-hub/internal/controllers/middlewares"
-	"github.com/pavlegich/scripts-hub/internal/entities"
 	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/executor"
+	"github.com/pavlegich/scripts-hub/internal/infra/metrics"
+	"github.com/pavlegich/scripts-hub/internal/infra/sshexec"
 	"github.com/pavlegich/scripts-hub/internal/repository"
+	"github.com/pavlegich/scripts-hub/internal/service/health"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
 )
 
 // Controller contains database and configuration
 // for building the server router.
 type Controller struct {
-	cfg *config.Config
+	cfg       *config.Config
+	health    health.Service
+	commands  *CommandHandler
+	executors map[string]executor.Executor
 }
 
-// NewController creates and returns new server controller.
+// NewController creates and returns new server controller
+// with the local executor of the command processes.
 func NewController(ctx context.Context, cfg *config.Config) *Controller {
 	return &Controller{
 		cfg: cfg,
+		executors: map[string]executor.Executor{
+			executor.Local: executor.NewLocal(),
+		},
 	}
 }
 
+// AddExecutor adds the executor which the commands select by the name,
+// the executor replaces the previous one of the name. It has to be called
+// before the route is built.
+func (c *Controller) AddExecutor(name string, e executor.Executor) {
+	c.executors[name] = e
+}
+
 // BuildRoute creates new router and appends handlers and middlewares to it.
-func (c *Controller) BuildRoute(ctx context.Context, repo repository.Repository, runCmdChan chan entities.Command) (http.Handler, error) {
+func (c *Controller) BuildRoute(ctx context.Context, repo repository.Repository, queues *queue.Manager) (http.Handler, error) {
 	router := http.NewServeMux()
 
-	commandsActivate(ctx, router, repo, c.cfg, runCmdChan)
+	hosts, err := sshexec.NewExecutor(ctx, c.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("BuildRoute: %w", err)
+	}
+
+	c.health = healthActivate(ctx, router, repo, c.cfg, queues)
+	hooks := webhooksActivate(ctx, router, repo, c.cfg)
+	rules := rulesActivate(ctx, router, repo, c.cfg)
+	agents := agentsActivate(ctx, router, c.cfg)
+	h := commandsActivate(ctx, router, repo, c.cfg, queues, hooks, rules, agents, hosts, c.executors)
+	c.commands = h
+	schedulesActivate(ctx, router, repo, c.cfg, h)
+	workflowsActivate(ctx, router, repo, c.cfg, h)
+	triggersActivate(ctx, router, repo, c.cfg, h)
+	adminActivate(ctx, router, c.cfg, h)
+
+	reg := metrics.NewRegistry(queue.NewCollector(queues))
+	router.Handle("/metrics", metrics.Handler(reg))
 
 	handler := middlewares.Recovery(router)
-	handler = middlewares.WithLogging(handler)
+	handler = middlewares.WithLogging(handler, router)
+	handler = middlewares.WithTracing(handler, router)
+	handler = middlewares.WithRequestID(handler)
 
 	return handler, nil
 }
+
+// Drain marks the server as shutting down, so the readiness checks fail
+// and the load balancers stop sending the traffic before the server shutdown.
+// The new runs are refused and the waiting runs are kept for the next start.
+func (c *Controller) Drain(ctx context.Context) {
+	if c.health != nil {
+		c.health.Drain()
+	}
+	if c.commands != nil {
+		c.commands.Drain(ctx)
+	}
+}
+
+// Stop waits for the running commands to finish until the context is done
+// and interrupts the remaining ones.
+func (c *Controller) Stop(ctx context.Context) {
+	if c.commands == nil {
+		return
+	}
+	c.commands.Stop(ctx)
+}
@@ -2,10 +2,23 @@ package handlers
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
 	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/executor"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewController(t *testing.T) {
@@ -26,6 +39,9 @@ func TestNewController(t *testing.T) {
 			},
 			want: &Controller{
 				cfg: cfg,
+				executors: map[string]executor.Executor{
+					executor.Local: executor.NewLocal(),
+				},
 			},
 		},
 	}
@@ -38,3 +54,163 @@ func TestNewController(t *testing.T) {
 		})
 	}
 }
+
+func TestController_Metrics(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 2,
+		Queues:    config.QueueLimits{"heavy": 1},
+	}
+
+	// Mocks expected response
+	mockRepo.EXPECT().GetAllRules(gomock.Any()).
+		Return(nil, errs.ErrRuleNotFound).Times(1)
+
+	// Controller
+	mh, err := NewController(ctx, cfg).BuildRoute(ctx, mockRepo, queue.NewManager(ctx, cfg))
+	require.NoError(t, err)
+
+	requests := []string{`/rules`, `/unknown`, `/metrics`}
+	var body string
+	for _, path := range requests {
+		r := httptest.NewRequest(http.MethodGet, `http://`+cfg.Address+path, nil)
+		w := httptest.NewRecorder()
+
+		mh.ServeHTTP(w, r)
+
+		resp := w.Result()
+		gotBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+		body = string(gotBody)
+	}
+
+	// Check the exposed metrics
+	require.Contains(t, body, `scripts_hub_http_requests_total{code="404",method="GET",route="/rules"}`)
+	require.Contains(t, body, `scripts_hub_http_requests_total{code="404",method="GET",route="unmatched"}`)
+	require.Contains(t, body, `scripts_hub_http_request_duration_seconds_bucket{method="GET",route="/rules"`)
+	require.Contains(t, body, `scripts_hub_queue_depth{queue="default"} 0`)
+	require.Contains(t, body, `scripts_hub_queue_workers{queue="default"} 2`)
+	require.Contains(t, body, `scripts_hub_queue_busy_workers{queue="heavy"} 0`)
+	require.Contains(t, body, `go_goroutines`)
+}
+
+func TestController_Drain(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &config.Config{
+		Address:     `localhost:8080`,
+		RateLimit:   1,
+		KillTimeout: time.Second,
+	}
+
+	tests := []struct {
+		name       string
+		script     string
+		timeout    time.Duration
+		wantStatus string
+	}{
+		{
+			name:       "finished_before_deadline",
+			script:     "sleep 0.2",
+			timeout:    5 * time.Second,
+			wantStatus: entities.RunSucceeded,
+		},
+		{
+			name:       "interrupted_after_deadline",
+			script:     "sleep 10",
+			timeout:    100 * time.Millisecond,
+			wantStatus: entities.RunInterrupted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Initialize mock repository
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockRepo := mocks.NewMockRepository(mockCtrl)
+
+			// Mocks expected response
+			var runs atomic.Int32
+			started := make(chan struct{})
+			finished := make(chan *entities.Run, 1)
+			mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, c *entities.Command) (*entities.Command, error) {
+					return c, nil
+				}).Times(2)
+			mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) (*entities.Run, error) {
+					run.ID = int(runs.Add(1))
+					return run, nil
+				}).Times(2)
+			mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+					close(started)
+					return nil
+				}).Times(1)
+			mockRepo.EXPECT().SuspendRun(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+					require.Equal(t, 2, run.ID)
+					require.Equal(t, entities.RunPending, run.Status)
+					require.NotNil(t, run.RunAt)
+					return nil
+				}).Times(1)
+			mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+					finished <- run
+					return nil
+				}).Times(1)
+			mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil, nil).AnyTimes()
+
+			// Controller
+			ctrl := NewController(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queue.NewManager(ctx, cfg))
+			require.NoError(t, err)
+
+			submit := func(name string) int {
+				body := `{"name": "` + name + `", "script": "` + tt.script + `"}`
+				r := httptest.NewRequest(http.MethodPost, `http://`+cfg.Address+`/command`, strings.NewReader(body))
+				w := httptest.NewRecorder()
+
+				mh.ServeHTTP(w, r)
+
+				resp := w.Result()
+				defer resp.Body.Close()
+				return resp.StatusCode
+			}
+
+			// The first run is running, the second one is waiting in the queue
+			require.Equal(t, http.StatusCreated, submit("running"))
+			select {
+			case <-started:
+			case <-time.After(5 * time.Second):
+				t.Fatal("run is not started")
+			}
+			require.Equal(t, http.StatusCreated, submit("waiting"))
+
+			// The waiting run is kept for the next start, the new runs are refused
+			ctrl.Drain(ctx)
+			require.Equal(t, http.StatusServiceUnavailable, submit("refused"))
+
+			ctxStop, cancelStop := context.WithTimeout(ctx, tt.timeout)
+			defer cancelStop()
+			ctrl.Stop(ctxStop)
+
+			select {
+			case run := <-finished:
+				require.Equal(t, 1, run.ID)
+				require.Equal(t, tt.wantStatus, run.Status)
+			default:
+				t.Fatal("run is not finished after stop")
+			}
+		})
+	}
+}
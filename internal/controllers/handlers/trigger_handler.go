@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/repository"
+	"github.com/pavlegich/scripts-hub/internal/service/trigger"
+	"go.uber.org/zap"
+)
+
+// Trigger invocation request limits and names.
+const (
+	maxTriggerInput    = 1 << 20
+	triggerTokenHeader = "X-Trigger-Token"
+	invokePath         = "/invoke/"
+)
+
+// TriggerHandler contains objects for work with trigger handlers.
+type TriggerHandler struct {
+	Config  *config.Config
+	Service trigger.Service
+	runner  trigger.Runner
+}
+
+// triggersActivate activates handler for trigger object.
+func triggersActivate(ctx context.Context, r *http.ServeMux, repo repository.Repository, cfg *config.Config, runner trigger.Runner) {
+	s := trigger.NewTriggerService(ctx, repo)
+	newTriggerHandler(ctx, r, cfg, s, runner)
+}
+
+// newTriggerHandler initializes handler for trigger object and starts
+// the watcher of the watch triggers and the listener of the notify triggers
+// if they are enabled.
+func newTriggerHandler(ctx context.Context, r *http.ServeMux, cfg *config.Config, s trigger.Service, runner trigger.Runner) {
+	h := &TriggerHandler{
+		Config:  cfg,
+		Service: s,
+		runner:  runner,
+	}
+
+	r.HandleFunc("/trigger", h.HandleTrigger)
+	r.HandleFunc("/triggers", h.HandleTriggers)
+	r.HandleFunc("/trigger/rotate", h.HandleRotateTrigger)
+	r.HandleFunc(invokePath, h.HandleInvokeTrigger)
+
+	if cfg.WatchInterval > 0 {
+		go trigger.NewWatcher(ctx, s, runner, cfg.WatchInterval).Run(ctx)
+	}
+	if cfg.NotifyInterval > 0 {
+		go trigger.NewListener(ctx, s, runner, cfg.NotifyInterval).Run(ctx)
+	}
+}
+
+// HandleTrigger handles request to create or revoke the trigger.
+func (h *TriggerHandler) HandleTrigger(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.HandleCreateTrigger(w, r)
+	case http.MethodDelete:
+		h.HandleRevokeTrigger(w, r)
+	default:
+		logger.Log.Error("HandleTrigger: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleCreateTrigger handles request to create new trigger of the saved command,
+// the response of the URL trigger contains the secret token which is not shown again.
+func (h *TriggerHandler) HandleCreateTrigger(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req entities.Trigger
+	var buf bytes.Buffer
+
+	_, err := buf.ReadFrom(r.Body)
+	if err != nil {
+		logger.Log.Error("HandleCreateTrigger: read request body failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = json.Unmarshal(buf.Bytes(), &req)
+	if err != nil {
+		logger.Log.Error("HandleCreateTrigger: request unmarshal failed",
+			zap.String("body", buf.String()),
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" {
+		logger.Log.Error("HandleCreateTrigger: command name empty")
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	t, err := h.Service.Create(ctx, &req)
+	if err != nil {
+		logger.Log.With(zap.String("cmd_name", req.Name)).Error("HandleCreateTrigger: create trigger failed",
+			zap.Error(err), zap.String("type", req.Type))
+
+		switch {
+		case errors.Is(err, errs.ErrTriggerIncorrect):
+			w.WriteHeader(http.StatusBadRequest)
+		case errors.Is(err, errs.ErrCmdNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeTrigger(w, http.StatusCreated, t)
+}
+
+// HandleRevokeTrigger handles request to revoke the trigger, its URL stops working.
+func (h *TriggerHandler) HandleRevokeTrigger(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := queryID(r)
+	if err != nil {
+		logger.Log.Error("HandleRevokeTrigger: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = h.Service.Revoke(ctx, id)
+	if err != nil {
+		logger.Log.Error("HandleRevokeTrigger: revoke trigger failed",
+			zap.Error(err), zap.Int("trigger_id", id))
+
+		if errors.Is(err, errs.ErrTriggerNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleRotateTrigger handles request to replace the token of the trigger with the new one.
+func (h *TriggerHandler) HandleRotateTrigger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logger.Log.Error("HandleRotateTrigger: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	id, err := queryID(r)
+	if err != nil {
+		logger.Log.Error("HandleRotateTrigger: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	t, err := h.Service.Rotate(ctx, id)
+	if err != nil {
+		logger.Log.Error("HandleRotateTrigger: rotate trigger token failed",
+			zap.Error(err), zap.Int("trigger_id", id))
+
+		switch {
+		case errors.Is(err, errs.ErrTriggerIncorrect):
+			w.WriteHeader(http.StatusBadRequest)
+		case errors.Is(err, errs.ErrTriggerNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeTrigger(w, http.StatusOK, t)
+}
+
+// HandleTriggers handles request to get the triggers of the command.
+func (h *TriggerHandler) HandleTriggers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandleTriggers: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	cmdName, err := queryValue(r, "name", true)
+	if err != nil {
+		logger.Log.Error("HandleTriggers: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	triggers, err := h.Service.List(ctx, cmdName)
+	if err != nil {
+		logger.Log.With(zap.String("cmd_name", cmdName)).Error("HandleTriggers: get triggers failed",
+			zap.Error(err))
+
+		if errors.Is(err, errs.ErrTriggerNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	triggersJSON, err := json.Marshal(triggers)
+	if err != nil {
+		logger.Log.Error("HandleTriggers: marshal triggers failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(triggersJSON)
+}
+
+// HandleInvokeTrigger handles request to the trigger URL which starts the command
+// of the trigger. The token is taken from the X-Trigger-Token header or the token
+// query, the request body is passed to the command according to the trigger input mode.
+func (h *TriggerHandler) HandleInvokeTrigger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logger.Log.Error("HandleInvokeTrigger: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, invokePath))
+	if err != nil {
+		logger.Log.Error("HandleInvokeTrigger: incorrect trigger id",
+			zap.Error(err), zap.String("path", r.URL.Path))
+
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	token := r.Header.Get(triggerTokenHeader)
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+
+	t, err := h.Service.Authorize(ctx, id, token)
+	if err != nil {
+		logger.Log.Error("HandleInvokeTrigger: authorize trigger failed",
+			zap.Error(err), zap.Int("trigger_id", id))
+
+		switch {
+		case errors.Is(err, errs.ErrTriggerNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, errs.ErrTriggerUnauthorized):
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTriggerInput))
+	if err != nil {
+		logger.Log.Error("HandleInvokeTrigger: read request body failed",
+			zap.Error(err), zap.Int("trigger_id", id))
+
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	opts := entities.SubmitOptions{
+		Trigger:   entities.TriggerURL,
+		TriggerID: &t.ID,
+		Overlap:   entities.OverlapQueue,
+	}
+	switch t.Input {
+	case entities.InputStdin:
+		opts.Input = body
+	case entities.InputParams:
+		opts.Params = map[string]string{}
+		if len(bytes.TrimSpace(body)) > 0 {
+			err = json.Unmarshal(body, &opts.Params)
+			if err != nil {
+				logger.Log.Error("HandleInvokeTrigger: parameters unmarshal failed",
+					zap.Error(err), zap.Int("trigger_id", id))
+
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	run, err := h.runner.Submit(ctx, t.Name, opts)
+	if err != nil {
+		logger.Log.With(zap.String("cmd_name", t.Name)).Error("HandleInvokeTrigger: submit run failed",
+			zap.Error(err), zap.Int("trigger_id", id))
+
+		switch {
+		case errors.Is(err, errs.ErrParamsIncorrect):
+			w.WriteHeader(http.StatusBadRequest)
+		case errors.Is(err, errs.ErrCmdNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, errs.ErrQueueCallerLimit):
+			w.WriteHeader(http.StatusTooManyRequests)
+		case errors.Is(err, errs.ErrQueueFull), errors.Is(err, errs.ErrQueueClosed),
+			errors.Is(err, errs.ErrMaintenance):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"run_id": run.ID})
+}
+
+// writeTrigger writes the response with the trigger and its token.
+func writeTrigger(w http.ResponseWriter, status int, t *entities.Trigger) {
+	triggerJSON, err := json.Marshal(t)
+	if err != nil {
+		logger.Log.Error("writeTrigger: marshal trigger failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(triggerJSON)
+}
@@ -0,0 +1,373 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerHandler_HandleCreateTrigger(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	dir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		body      string
+		err       error
+		wantInput string
+		wantToken bool
+		wantCode  int
+	}{
+		{
+			name:      "success",
+			body:      `{"name": "deploy", "input": "params"}`,
+			wantInput: entities.InputParams,
+			wantToken: true,
+			wantCode:  http.StatusCreated,
+		},
+		{
+			name:      "default_input",
+			body:      `{"name": "deploy"}`,
+			wantInput: entities.InputNone,
+			wantToken: true,
+			wantCode:  http.StatusCreated,
+		},
+		{
+			name:     "watch",
+			body:     `{"name": "import", "type": "watch", "path": "` + dir + `", "glob": "*.csv"}`,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "watch_missing_directory",
+			body:     `{"name": "import", "type": "watch", "path": "` + dir + `/missing"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "command_not_found",
+			body:     `{"name": "unknown"}`,
+			err:      errs.ErrCmdNotFound,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "incorrect_input",
+			body:     `{"name": "deploy", "input": "file"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "empty_name",
+			body:     `{"input": "stdin"}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			if tt.wantCode == http.StatusCreated || tt.err != nil {
+				mockRepo.EXPECT().CreateTrigger(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, tr *entities.Trigger) (*entities.Trigger, error) {
+						if tt.err != nil {
+							return nil, tt.err
+						}
+						if tr.Type == entities.TriggerTypeURL {
+							sum := sha256.Sum256([]byte(tr.Token))
+							require.Equal(t, hex.EncodeToString(sum[:]), tr.TokenHash)
+						}
+						tr.ID = 7
+						return tr, nil
+					}).Times(1)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/trigger`
+
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if tt.wantCode != http.StatusCreated {
+				return
+			}
+
+			var got map[string]any
+			require.NoError(t, json.Unmarshal(gotBody, &got))
+			require.Equal(t, float64(7), got["id"])
+			require.NotContains(t, got, "token_hash")
+			if !tt.wantToken {
+				require.NotContains(t, got, "token")
+				require.Equal(t, entities.TriggerTypeWatch, got["type"])
+				return
+			}
+			require.Equal(t, tt.wantInput, got["input"])
+			require.Len(t, got["token"], 64)
+		})
+	}
+}
+
+func TestTriggerHandler_HandleRevokeTrigger(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		err      error
+		wantCode int
+	}{
+		{
+			name:     "revoked",
+			query:    "?id=7",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "already_revoked",
+			query:    "?id=8",
+			err:      errs.ErrTriggerNotFound,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "incorrect_id",
+			query:    "?id=seven",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			if tt.wantCode != http.StatusBadRequest {
+				mockRepo.EXPECT().RevokeTrigger(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(tt.err).Times(1)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/trigger` + tt.query
+
+			r := httptest.NewRequest(http.MethodDelete, url, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Check status code
+			resp := w.Result()
+			defer resp.Body.Close()
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestTriggerHandler_HandleInvokeTrigger(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &config.Config{
+		Address:   `localhost:8080`,
+		RateLimit: 1,
+	}
+
+	token := strings.Repeat("ab", 32)
+	sum := sha256.Sum256([]byte(token))
+	revokedAt := time.Date(2024, time.April, 28, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		body       string
+		trigger    *entities.Trigger
+		script     string
+		wantCode   int
+		wantOutput string
+	}{
+		{
+			name:       "stdin",
+			path:       "/invoke/7",
+			header:     token,
+			body:       "hello from ci",
+			trigger:    &entities.Trigger{ID: 7, Type: entities.TriggerTypeURL, Name: "reader", Input: entities.InputStdin},
+			script:     "cat",
+			wantCode:   http.StatusCreated,
+			wantOutput: "hello from ci",
+		},
+		{
+			name:       "params_with_query_token",
+			path:       "/invoke/7?token=" + token,
+			body:       `{"msg": "deployed"}`,
+			trigger:    &entities.Trigger{ID: 7, Type: entities.TriggerTypeURL, Name: "notify", Input: entities.InputParams},
+			script:     "echo {{msg}}",
+			wantCode:   http.StatusCreated,
+			wantOutput: "deployed\n",
+		},
+		{
+			name:     "missing_param",
+			path:     "/invoke/7",
+			header:   token,
+			body:     `{}`,
+			trigger:  &entities.Trigger{ID: 7, Type: entities.TriggerTypeURL, Name: "notify", Input: entities.InputParams},
+			script:   "echo {{msg}}",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "wrong_token",
+			path:     "/invoke/7",
+			header:   strings.Repeat("cd", 32),
+			trigger:  &entities.Trigger{ID: 7, Type: entities.TriggerTypeURL, Name: "reader", Input: entities.InputStdin},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "revoked",
+			path:     "/invoke/7",
+			header:   token,
+			trigger:  &entities.Trigger{ID: 7, Type: entities.TriggerTypeURL, Name: "reader", RevokedAt: &revokedAt},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "incorrect_id",
+			path:     "/invoke/seven",
+			header:   token,
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Initialize mock repository
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockRepo := mocks.NewMockRepository(mockCtrl)
+
+			var mu sync.Mutex
+			var output string
+			finished := make(chan struct{})
+
+			// Mocks expected response
+			if tt.trigger != nil {
+				tt.trigger.TokenHash = hex.EncodeToString(sum[:])
+				mockRepo.EXPECT().GetTriggerByID(gomock.Any(), tt.trigger.ID).
+					Return(tt.trigger, nil).Times(1)
+			}
+			if tt.script != "" {
+				mockRepo.EXPECT().GetCommandByName(gomock.Any(), tt.trigger.Name).
+					Return(&entities.Command{ID: 1, Name: tt.trigger.Name, Script: tt.script}, nil).Times(1)
+			}
+			if tt.wantCode == http.StatusCreated {
+				mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, run *entities.Run) (*entities.Run, error) {
+						require.Equal(t, entities.TriggerURL, run.Trigger)
+						require.Equal(t, 7, *run.TriggerID)
+						run.ID = 3
+						return run, nil
+					}).Times(1)
+				mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+						mu.Lock()
+						defer mu.Unlock()
+						output += run.Output
+						return nil
+					}).AnyTimes()
+				mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, run *entities.Run) error {
+						require.Equal(t, entities.RunSucceeded, run.Status)
+						close(finished)
+						return nil
+					}).Times(1)
+				mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, nil).AnyTimes()
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + tt.path
+
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(tt.body))
+			if tt.header != "" {
+				r.Header.Set("X-Trigger-Token", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if tt.wantCode != http.StatusCreated {
+				return
+			}
+			require.JSONEq(t, `{"run_id": 3}`, string(gotBody))
+
+			// Check the run output
+			select {
+			case <-finished:
+			case <-time.After(5 * time.Second):
+				t.Fatal("run is not finished")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			require.Equal(t, tt.wantOutput, output)
+		})
+	}
+}
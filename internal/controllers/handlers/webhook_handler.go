@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/repository"
+	"github.com/pavlegich/scripts-hub/internal/service/webhook"
+	"go.uber.org/zap"
+)
+
+// WebhookHandler contains objects for work with webhook handlers.
+type WebhookHandler struct {
+	Config  *config.Config
+	Service webhook.Service
+}
+
+// webhooksActivate activates handler for webhook object and returns
+// the webhook service storing the run events.
+func webhooksActivate(ctx context.Context, r *http.ServeMux, repo repository.Repository, cfg *config.Config) webhook.Service {
+	s := webhook.NewWebhookService(ctx, repo, cfg.WebhookURLs, cfg.WebhookMaxAttempts)
+	newWebhookHandler(ctx, r, cfg, s)
+	return s
+}
+
+// newWebhookHandler initializes handler for webhook object
+// and starts the dispatcher if it is enabled.
+func newWebhookHandler(ctx context.Context, r *http.ServeMux, cfg *config.Config, s webhook.Service) {
+	h := &WebhookHandler{
+		Config:  cfg,
+		Service: s,
+	}
+
+	r.HandleFunc("/webhooks/dead", h.HandleDeadDeliveries)
+	r.HandleFunc("/webhooks/replay", h.HandleReplayDelivery)
+
+	if cfg.WebhookInterval <= 0 {
+		return
+	}
+	go webhook.NewDispatcher(ctx, s, cfg.WebhookSecret, cfg.WebhookInterval).Run(ctx)
+}
+
+// HandleDeadDeliveries handles request to get the webhook deliveries which attempts are exhausted.
+func (h *WebhookHandler) HandleDeadDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandleDeadDeliveries: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	deliveries, err := h.Service.ListDead(ctx)
+	if err != nil {
+		logger.Log.Error("HandleDeadDeliveries: get dead deliveries failed",
+			zap.Error(err))
+
+		if errors.Is(err, errs.ErrDeliveryNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	deliveriesJSON, err := json.Marshal(deliveries)
+	if err != nil {
+		logger.Log.Error("HandleDeadDeliveries: marshal deliveries failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(deliveriesJSON)
+}
+
+// HandleReplayDelivery handles request to send the dead webhook delivery again.
+func (h *WebhookHandler) HandleReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		logger.Log.Error("HandleReplayDelivery: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	id, err := queryID(r)
+	if err != nil {
+		logger.Log.Error("HandleReplayDelivery: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = h.Service.Replay(ctx, id)
+	if err != nil {
+		logger.Log.Error("HandleReplayDelivery: replay delivery failed",
+			zap.Error(err), zap.Int("delivery_id", id))
+
+		if errors.Is(err, errs.ErrDeliveryNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
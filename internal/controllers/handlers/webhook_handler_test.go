@@ -0,0 +1,241 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookHandler_HandleDeadDeliveries(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	created := time.Date(2024, time.April, 26, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		deliveries []*entities.Delivery
+		err        error
+		wantCode   int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			deliveries: []*entities.Delivery{
+				{ID: 3, URL: "https://ci.local/hook", Event: "run.failed", Payload: []byte(`{"type":"run.failed"}`),
+					Status: entities.DeliveryDead, Attempts: 5, LastError: "send: unexpected response status 502",
+					CreatedAt: created, NextAttemptAt: created},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[{"id": 3, "url": "https://ci.local/hook", "event": "run.failed", "payload": {"type": "run.failed"},
+			"status": "dead", "attempts": 5, "last_error": "send: unexpected response status 502",
+			"created_at": "2024-04-26T10:00:00Z", "next_attempt_at": "2024-04-26T10:00:00Z"}]`,
+		},
+		{
+			name:     "dead_deliveries_not_found",
+			err:      errs.ErrDeliveryNotFound,
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			mockRepo.EXPECT().GetDeadDeliveries(gomock.Any()).
+				Return(tt.deliveries, tt.err).Times(1)
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/webhooks/dead`
+
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_HandleReplayDelivery(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		query    string
+		replayed bool
+		wantCode int
+	}{
+		{
+			name:     "replayed",
+			method:   http.MethodPost,
+			query:    "?id=3",
+			replayed: true,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "delivery_not_dead",
+			method:   http.MethodPost,
+			query:    "?id=4",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "incorrect_id",
+			method:   http.MethodPost,
+			query:    "?id=three",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "incorrect_method",
+			method:   http.MethodGet,
+			query:    "?id=3",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			if tt.method == http.MethodPost && tt.wantCode != http.StatusBadRequest {
+				mockRepo.EXPECT().ReplayDelivery(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(tt.replayed, nil).Times(1)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/webhooks/replay` + tt.query
+
+			r := httptest.NewRequest(tt.method, url, nil)
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Check status code
+			resp := w.Result()
+			defer resp.Body.Close()
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestCommandHandler_HandleCreateCommandWebhooks(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address:       `localhost:8080`,
+		RateLimit:     1,
+		WebhookURLs:   config.URLList{"https://ops.local/hook"},
+		WebhookSecret: "secret",
+	}
+
+	finished := make(chan struct{})
+	events := make([]string, 0)
+
+	// Mocks expected response
+	mockRepo.EXPECT().CreateCommand(gomock.Any(), gomock.Any()).
+		Return(&entities.Command{ID: 1, Name: "pwd", Script: "pwd"}, nil).Times(1)
+	mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+		Return(&entities.Run{ID: 1, CommandID: 1, Name: "pwd", Status: entities.RunQueued}, nil).Times(1)
+	mockRepo.EXPECT().StartRun(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockRepo.EXPECT().AppendRunOutput(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockRepo.EXPECT().FinishRun(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockRepo.EXPECT().GetEnabledRules(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil).AnyTimes()
+	mockRepo.EXPECT().CreateDeliveries(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, deliveries []*entities.Delivery) error {
+			urls := make([]string, 0, len(deliveries))
+			for _, d := range deliveries {
+				urls = append(urls, d.URL)
+			}
+			require.Equal(t, []string{"https://ci.local/hook", "https://ops.local/hook"}, urls)
+
+			events = append(events, deliveries[0].Event)
+			if deliveries[0].Event != "run.running" {
+				close(finished)
+			}
+			return nil
+		}).Times(2)
+
+	// Controller
+	ctrl := handlers.NewController(ctx, cfg)
+	queues := queue.NewManager(ctx, cfg)
+	mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+	require.NoError(t, err)
+
+	// Form new request
+	url := `http://` + cfg.Address + `/command`
+	body := `{"name": "pwd", "script": "pwd", "webhooks": ["https://ci.local/hook"]}`
+
+	r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	mh.ServeHTTP(w, r)
+
+	// Check status code
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// Check the run events
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run is not finished")
+	}
+	require.Equal(t, []string{"run.running", "run.succeeded"}, events)
+}
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/repository"
+	"github.com/pavlegich/scripts-hub/internal/service/workflow"
+	"go.uber.org/zap"
+)
+
+// WorkflowHandler contains objects for work with workflow handlers.
+type WorkflowHandler struct {
+	executor *workflow.Executor
+	Config   *config.Config
+	Service  workflow.Service
+}
+
+// workflowsActivate activates handler for workflow object.
+func workflowsActivate(ctx context.Context, r *http.ServeMux, repo repository.Repository, cfg *config.Config, runner workflow.Runner) {
+	s := workflow.NewWorkflowService(ctx, repo)
+	newWorkflowHandler(ctx, r, cfg, s, runner)
+}
+
+// newWorkflowHandler initializes handler for workflow object.
+func newWorkflowHandler(ctx context.Context, r *http.ServeMux, cfg *config.Config, s workflow.Service, runner workflow.Runner) {
+	h := &WorkflowHandler{
+		executor: workflow.NewExecutor(ctx, s, runner),
+		Config:   cfg,
+		Service:  s,
+	}
+
+	r.HandleFunc("/workflow", h.HandleWorkflow)
+	r.HandleFunc("/workflows", h.HandleWorkflows)
+	r.HandleFunc("/workflow/run", h.HandleWorkflowRun)
+}
+
+// HandleWorkflow handles request to create, get or delete the workflow.
+func (h *WorkflowHandler) HandleWorkflow(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.HandleCreateWorkflow(w, r)
+	case http.MethodGet:
+		h.HandleGetWorkflow(w, r)
+	case http.MethodDelete:
+		h.HandleDeleteWorkflow(w, r)
+	default:
+		logger.Log.Error("HandleWorkflow: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleCreateWorkflow handles request to create new workflow of the saved commands.
+func (h *WorkflowHandler) HandleCreateWorkflow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req entities.Workflow
+	var buf bytes.Buffer
+
+	_, err := buf.ReadFrom(r.Body)
+	if err != nil {
+		logger.Log.Error("HandleCreateWorkflow: read request body failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = json.Unmarshal(buf.Bytes(), &req)
+	if err != nil {
+		logger.Log.Error("HandleCreateWorkflow: request unmarshal failed",
+			zap.String("body", buf.String()),
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	workflowID, err := h.Service.Create(ctx, &req)
+	if err != nil {
+		logger.Log.With(zap.String("workflow", req.Name)).Error("HandleCreateWorkflow: create workflow failed",
+			zap.Error(err))
+
+		switch {
+		case errors.Is(err, errs.ErrWorkflowIncorrect):
+			w.WriteHeader(http.StatusBadRequest)
+		case errors.Is(err, errs.ErrCmdNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, errs.ErrWorkflowAlreadyExists):
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"workflow_id": workflowID})
+}
+
+// HandleGetWorkflow handles request to get the workflow by its name.
+func (h *WorkflowHandler) HandleGetWorkflow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	name, err := queryValue(r, "name", true)
+	if err != nil {
+		logger.Log.Error("HandleGetWorkflow: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	wf, err := h.Service.Unload(ctx, name)
+	if err != nil {
+		logger.Log.With(zap.String("workflow", name)).
+			Error("HandleGetWorkflow: get workflow failed", zap.Error(err))
+
+		if errors.Is(err, errs.ErrWorkflowNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	wfJSON, err := json.Marshal(wf)
+	if err != nil {
+		logger.Log.Error("HandleGetWorkflow: marshal workflow failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(wfJSON)
+}
+
+// HandleDeleteWorkflow handles request to delete the workflow with its runs.
+func (h *WorkflowHandler) HandleDeleteWorkflow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	name, err := queryValue(r, "name", true)
+	if err != nil {
+		logger.Log.Error("HandleDeleteWorkflow: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = h.Service.Delete(ctx, name)
+	if err != nil {
+		logger.Log.With(zap.String("workflow", name)).
+			Error("HandleDeleteWorkflow: delete workflow failed", zap.Error(err))
+
+		if errors.Is(err, errs.ErrWorkflowNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleWorkflows handles request to get list of workflows.
+func (h *WorkflowHandler) HandleWorkflows(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.Log.Error("HandleWorkflows: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	workflows, err := h.Service.List(ctx)
+	if err != nil {
+		logger.Log.Error("HandleWorkflows: get workflows list failed",
+			zap.Error(err))
+
+		if errors.Is(err, errs.ErrWorkflowNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	workflowsJSON, err := json.Marshal(workflows)
+	if err != nil {
+		logger.Log.Error("HandleWorkflows: marshal workflows failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(workflowsJSON)
+}
+
+// HandleWorkflowRun handles request to start or get the workflow run.
+func (h *WorkflowHandler) HandleWorkflowRun(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.HandleStartWorkflow(w, r)
+	case http.MethodGet:
+		h.HandleGetWorkflowRun(w, r)
+	default:
+		logger.Log.Error("HandleWorkflowRun: incorrect method",
+			zap.String("method", r.Method))
+
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleStartWorkflow handles request to start new run of the workflow.
+func (h *WorkflowHandler) HandleStartWorkflow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	name, err := queryValue(r, "name", true)
+	if err != nil {
+		logger.Log.Error("HandleStartWorkflow: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	wf, err := h.Service.Unload(ctx, name)
+	if err != nil {
+		logger.Log.With(zap.String("workflow", name)).
+			Error("HandleStartWorkflow: get workflow failed", zap.Error(err))
+
+		if errors.Is(err, errs.ErrWorkflowNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	run, err := h.executor.Start(ctx, wf)
+	if err != nil {
+		logger.Log.With(zap.String("workflow", name)).
+			Error("HandleStartWorkflow: start workflow failed", zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"workflow_run_id": run.ID})
+}
+
+// HandleGetWorkflowRun handles request to get the workflow run with the statuses of its nodes.
+func (h *WorkflowHandler) HandleGetWorkflowRun(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := queryID(r)
+	if err != nil {
+		logger.Log.Error("HandleGetWorkflowRun: incorrect query",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.Service.UnloadRun(ctx, id)
+	if err != nil {
+		logger.Log.Error("HandleGetWorkflowRun: get workflow run failed",
+			zap.Error(err), zap.Int("workflow_run_id", id))
+
+		if errors.Is(err, errs.ErrWorkflowRunNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	runJSON, err := json.Marshal(run)
+	if err != nil {
+		logger.Log.Error("HandleGetWorkflowRun: marshal workflow run failed",
+			zap.Error(err))
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(runJSON)
+}
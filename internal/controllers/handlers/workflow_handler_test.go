@@ -0,0 +1,170 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/controllers/handlers"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowHandler_HandleCreateWorkflow(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	type expected struct {
+		commands  int
+		cmdErr    error
+		create    bool
+		createErr error
+	}
+	tests := []struct {
+		name     string
+		reqBody  string
+		expected expected
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "success",
+			reqBody: `{"name": "deploy", "nodes": [{"name": "build", "command": "build"},
+			{"name": "test", "command": "test", "needs": [{"node": "build"}]},
+			{"name": "notify", "command": "notify", "needs": [{"node": "test", "on": "always"}]}]}`,
+			expected: expected{
+				commands: 3,
+				create:   true,
+			},
+			wantCode: http.StatusCreated,
+			wantBody: `{"workflow_id": 1}`,
+		},
+		{
+			name:     "cycle",
+			reqBody:  `{"name": "deploy", "nodes": [{"name": "a", "command": "a", "needs": [{"node": "b"}]}, {"name": "b", "command": "b", "needs": [{"node": "a"}]}]}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:    "command_not_found",
+			reqBody: `{"name": "deploy", "nodes": [{"name": "build", "command": "unknown"}]}`,
+			expected: expected{
+				commands: 1,
+				cmdErr:   errs.ErrCmdNotFound,
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:    "workflow_already_exists",
+			reqBody: `{"name": "deploy", "nodes": [{"name": "build", "command": "build"}]}`,
+			expected: expected{
+				commands:  1,
+				create:    true,
+				createErr: errs.ErrWorkflowAlreadyExists,
+			},
+			wantCode: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Mocks expected response
+			if tt.expected.commands > 0 {
+				mockRepo.EXPECT().GetCommandByName(gomock.Any(), gomock.Any()).
+					Return(&entities.Command{}, tt.expected.cmdErr).Times(tt.expected.commands)
+			}
+			if tt.expected.create {
+				mockRepo.EXPECT().CreateWorkflow(gomock.Any(), gomock.Any()).
+					Return(&entities.Workflow{ID: 1}, tt.expected.createErr).Times(1)
+			}
+
+			// Controller
+			ctrl := handlers.NewController(ctx, cfg)
+			queues := queue.NewManager(ctx, cfg)
+			mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+			require.NoError(t, err)
+
+			// Form new request
+			url := `http://` + cfg.Address + `/workflow`
+
+			r := httptest.NewRequest(http.MethodPost, url, bytes.NewBufferString(tt.reqBody))
+			w := httptest.NewRecorder()
+
+			mh.ServeHTTP(w, r)
+
+			// Get response
+			resp := w.Result()
+			gotBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			// Check status code
+			require.Equal(t, tt.wantCode, resp.StatusCode)
+			if !(tt.wantBody == ``) {
+				require.JSONEq(t, tt.wantBody, string(gotBody))
+			}
+		})
+	}
+}
+
+func TestWorkflowHandler_HandleGetWorkflowRun(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize mock repository
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	cfg := &config.Config{
+		Address: `localhost:8080`,
+	}
+
+	runID := 7
+	mockRepo.EXPECT().GetWorkflowRunByID(gomock.Any(), 3).
+		Return(&entities.WorkflowRun{ID: 3, WorkflowID: 1, Name: "deploy", Status: entities.RunRunning,
+			Nodes: []*entities.NodeRun{
+				{Node: "build", Command: "build", Status: entities.RunSucceeded, RunID: &runID},
+				{Node: "test", Command: "test", Status: entities.NodeWaiting},
+			}}, nil).Times(1)
+
+	// Controller
+	ctrl := handlers.NewController(ctx, cfg)
+	queues := queue.NewManager(ctx, cfg)
+	mh, err := ctrl.BuildRoute(ctx, mockRepo, queues)
+	require.NoError(t, err)
+
+	// Form new request
+	url := `http://` + cfg.Address + `/workflow/run?id=3`
+
+	r := httptest.NewRequest(http.MethodGet, url, nil)
+	w := httptest.NewRecorder()
+
+	mh.ServeHTTP(w, r)
+
+	// Get response
+	resp := w.Result()
+	gotBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Check status code and body
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.JSONEq(t, `{"id": 3, "workflow_id": 1, "name": "deploy", "status": "running",
+	"created_at": "0001-01-01T00:00:00Z", "nodes": [
+	{"node": "build", "command": "build", "status": "succeeded", "run_id": 7},
+	{"node": "test", "command": "test", "status": "waiting"}]}`, string(gotBody))
+}
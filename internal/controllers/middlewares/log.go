@@ -5,14 +5,17 @@ package middlewares
 import (
 	"bytes"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/infra/metrics"
 	"go.uber.org/zap"
 )
 
-// WithLogging logs actions from the handlers.
-func WithLogging(h http.Handler) http.Handler {
+// WithLogging logs actions from the handlers and records the request
+// metrics labeled by the pattern of the routes matching the request.
+func WithLogging(h http.Handler, routes *http.ServeMux) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
@@ -30,7 +33,16 @@ func WithLogging(h http.Handler) http.Handler {
 
 		duration := time.Since(start)
 
+		status := responseData.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		route := routePattern(routes, r)
+		metrics.HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		metrics.HTTPDuration.WithLabelValues(r.Method, route).Observe(duration.Seconds())
+
 		logger.Log.Info("incoming HTTP request",
+			zap.String("request_id", logger.RequestID(r.Context())),
 			zap.String("uri", r.RequestURI),
 			zap.String("method", r.Method),
 			zap.Duration("duration", duration),
@@ -40,3 +52,12 @@ func WithLogging(h http.Handler) http.Handler {
 		)
 	})
 }
+
+// routePattern returns the pattern of the routes matching the request.
+func routePattern(routes *http.ServeMux, r *http.Request) string {
+	_, pattern := routes.Handler(r)
+	if pattern == "" {
+		return "unmatched"
+	}
+	return pattern
+}
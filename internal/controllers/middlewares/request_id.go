@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+)
+
+// RequestIDHeader is the header carrying the request identifier.
+const RequestIDHeader = "X-Request-ID"
+
+var (
+	// requestIDPattern matches the accepted incoming request identifiers.
+	requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+	// traceParentPattern matches the W3C traceparent header of version 00.
+	traceParentPattern = regexp.MustCompile(`^00-([0-9a-f]{32})-[0-9a-f]{16}-[0-9a-f]{2}$`)
+)
+
+// WithRequestID puts the request identifier into the request context and
+// the response headers. The identifier is taken from the X-Request-ID header,
+// from the trace identifier of the W3C traceparent header or generated.
+func WithRequestID(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := requestID(r)
+
+		w.Header().Set(RequestIDHeader, id)
+		h.ServeHTTP(w, r.WithContext(logger.WithRequestID(r.Context(), id)))
+	})
+}
+
+// requestID returns the identifier of the incoming request.
+func requestID(r *http.Request) string {
+	id := r.Header.Get(RequestIDHeader)
+	if requestIDPattern.MatchString(id) {
+		return id
+	}
+
+	m := traceParentPattern.FindStringSubmatch(strings.TrimSpace(r.Header.Get("traceparent")))
+	if m != nil && m[1] != strings.Repeat("0", 32) {
+		return m[1]
+	}
+
+	return logger.NewRequestID()
+}
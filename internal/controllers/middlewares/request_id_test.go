@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRequestID(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "request_id_header",
+			headers: map[string]string{"X-Request-ID": "deploy-42"},
+			want:    "deploy-42",
+		},
+		{
+			name:    "traceparent_header",
+			headers: map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			want:    "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name: "request_id_preferred",
+			headers: map[string]string{
+				"X-Request-ID": "deploy-42",
+				"traceparent":  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			want: "deploy-42",
+		},
+		{
+			name:    "incorrect_request_id",
+			headers: map[string]string{"X-Request-ID": "bad id\n"},
+		},
+		{
+			name:    "zero_trace_id",
+			headers: map[string]string{"traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		},
+		{
+			name: "generated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := WithRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = logger.RequestID(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "http://localhost:8080/commands", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			require.NotEmpty(t, got)
+			require.Equal(t, got, w.Header().Get(RequestIDHeader))
+			if tt.want != "" {
+				require.Equal(t, tt.want, got)
+			} else {
+				require.Len(t, got, 32)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/infra/tracing"
+)
+
+// statusWriter captures the status code of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements writing the header and status code capturing.
+func (w *statusWriter) WriteHeader(statusCode int) {
+	w.status = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// WithTracing records the server span of the request named by the pattern
+// of the routes matching the request. The span continues the trace
+// of the W3C traceparent header of the request.
+func WithTracing(h http.Handler, routes *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if sc, ok := tracing.ParseTraceParent(r.Header.Get("traceparent")); ok {
+			ctx = tracing.ContextWithRemote(ctx, sc)
+		}
+
+		route := routePattern(routes, r)
+		ctx, span := tracing.Start(ctx, r.Method+" "+route, tracing.KindServer,
+			tracing.String("http.method", r.Method),
+			tracing.String("http.route", route),
+			tracing.String("request_id", logger.RequestID(ctx)))
+		defer span.End()
+
+		sw := &statusWriter{
+			ResponseWriter: w,
+			status:         http.StatusOK,
+		}
+		h.ServeHTTP(sw, r.WithContext(ctx))
+
+		span.SetAttributes(tracing.Int("http.status_code", sw.status))
+		if sw.status >= http.StatusInternalServerError {
+			span.SetError(fmt.Errorf("response status %d", sw.status))
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package entities
+
+// AdminSettings contains the operational settings of the server
+// which are changed without the restart.
+type AdminSettings struct {
+	LogLevel    string         `json:"log_level"`
+	Maintenance bool           `json:"maintenance"`
+	Workers     map[string]int `json:"workers"`
+}
+
+// LogLevel contains the level of the server logger.
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+// WorkersLimit contains the number of the workers serving the queue.
+type WorkersLimit struct {
+	Workers int `json:"workers"`
+}
+
+// MaintenanceMode contains the state of the maintenance mode refusing the new runs.
+type MaintenanceMode struct {
+	Enabled bool `json:"enabled"`
+}
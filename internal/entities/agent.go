@@ -0,0 +1,45 @@
+package entities
+
+import "time"
+
+// Agent contains data of the remote agent running the commands
+// which labels it has.
+type Agent struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Labels   []string  `json:"labels,omitempty"`
+	Capacity int       `json:"capacity"`
+	Running  int       `json:"running"`
+	SeenAt   time.Time `json:"seen_at"`
+}
+
+// AgentJob contains the command run which the agent has to start.
+// The environment variables are added to the ones of the agent.
+type AgentJob struct {
+	RunID int      `json:"run_id"`
+	Path  string   `json:"path"`
+	Args  []string `json:"args,omitempty"`
+	Env   []string `json:"env,omitempty"`
+	Input []byte   `json:"input,omitempty"`
+}
+
+// AgentSignal contains the signal which the agent has to send
+// to the command of the run.
+type AgentSignal struct {
+	RunID  int `json:"run_id"`
+	Signal int `json:"signal"`
+}
+
+// AgentPoll contains the jobs and the signals received by the agent.
+type AgentPoll struct {
+	Jobs    []*AgentJob    `json:"jobs,omitempty"`
+	Signals []*AgentSignal `json:"signals,omitempty"`
+}
+
+// AgentExit contains the exit status of the command run by the agent,
+// the error is set when the command could not be started.
+type AgentExit struct {
+	RunID    int    `json:"run_id"`
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+}
@@ -1,10 +1,47 @@
 // Package entities contains objects for the application.
 package entities
 
+import "time"
+
 // Command contains data for commands.
 type Command struct {
-	ID     int    `json:"id"`
-	Name   string `json:"name"`
-	Script string `json:"script"`
-	Output string `json:"output"`
+	ID       int          `json:"id"`
+	Name     string       `json:"name"`
+	Script   string       `json:"script"`
+	Output   string       `json:"output"`
+	Priority int          `json:"priority,omitempty"`
+	Queue    string       `json:"queue,omitempty"`
+	Groups   []string     `json:"groups,omitempty"`
+	Retry    *RetryPolicy `json:"retry,omitempty"`
+	Webhooks []string     `json:"webhooks,omitempty"`
+
+	// Detached command is run by the supervisor process which outlives
+	// the server restarts.
+	Detached bool `json:"detached,omitempty"`
+
+	// Labels select the remote agents running the command, the agent
+	// has to have all of them. The command without labels runs on the server.
+	Labels []string `json:"labels,omitempty"`
+
+	// Host is the name of the SSH host from the server config running
+	// the command. The command without host runs on the server.
+	Host string `json:"host,omitempty"`
+
+	// Executor is the name of the backend starting the command process
+	// on the server, the local processes are used by default.
+	Executor string `json:"executor,omitempty"`
+
+	// RunAt and Delay postpone the first run of the submitted command,
+	// they are not stored with the command.
+	RunAt *time.Time `json:"run_at,omitempty"`
+	Delay string     `json:"delay,omitempty"`
+}
+
+// RetryPolicy contains the rules of the automatic retries of the failed command run.
+// The empty exit codes list means that any non-zero exit code is retryable.
+type RetryPolicy struct {
+	MaxAttempts int    `json:"max_attempts"`
+	Backoff     string `json:"backoff,omitempty"`
+	MaxBackoff  string `json:"max_backoff,omitempty"`
+	ExitCodes   []int  `json:"exit_codes,omitempty"`
 }
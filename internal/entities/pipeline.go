@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// TriggerPipeline is the trigger of the runs started as the steps of the pipeline.
+const TriggerPipeline = "pipeline"
+
+// Pipeline contains data of the single run of the saved commands
+// which output is piped into the input of the next command.
+type Pipeline struct {
+	ID         int        `json:"id"`
+	Commands   []string   `json:"commands,omitempty"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Steps      []*Run     `json:"steps"`
+}
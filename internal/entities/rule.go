@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// TriggerRule is the trigger of the runs queued by the rules.
+const TriggerRule = "rule"
+
+// Rule contains the saved command queued when the run of another command
+// finishes with the status of the rule event (succeeded, failed or cancelled).
+type Rule struct {
+	ID        int       `json:"id"`
+	Command   string    `json:"command"`
+	On        string    `json:"on"`
+	Target    string    `json:"target"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
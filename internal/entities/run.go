@@ -0,0 +1,63 @@
+package entities
+
+import "time"
+
+// Run statuses.
+const (
+	RunPending     = "pending"
+	RunQueued      = "queued"
+	RunRunning     = "running"
+	RunRetrying    = "retrying"
+	RunSucceeded   = "succeeded"
+	RunFailed      = "failed"
+	RunCancelled   = "cancelled"
+	RunSkipped     = "skipped"
+	RunInterrupted = "interrupted"
+)
+
+// Run triggers.
+const (
+	TriggerAPI      = "api"
+	TriggerSchedule = "schedule"
+)
+
+// Run contains data of the single execution of the command.
+type Run struct {
+	ID         int        `json:"id"`
+	CommandID  int        `json:"command_id"`
+	Name       string     `json:"name"`
+	Trigger    string     `json:"trigger"`
+	TriggerID  *int       `json:"trigger_id,omitempty"`
+	RequestID  string     `json:"request_id,omitempty"`
+	Status     string     `json:"status"`
+	Attempt    int        `json:"attempt,omitempty"`
+	ExitCode   *int       `json:"exit_code,omitempty"`
+	Output     string     `json:"output"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RunAt      *time.Time `json:"run_at,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Attempts   []*Attempt `json:"attempts,omitempty"`
+}
+
+// Attempt contains data of the single attempt of the run retried after failures.
+type Attempt struct {
+	Number     int        `json:"attempt"`
+	Status     string     `json:"status"`
+	ExitCode   *int       `json:"exit_code,omitempty"`
+	Output     string     `json:"output"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// SubmitOptions contains options of running the saved command.
+type SubmitOptions struct {
+	Trigger   string
+	TriggerID *int
+	Overlap   string
+	Input     []byte
+	Params    map[string]string
+	Env       []string
+	Args      []string
+	Depth     int
+}
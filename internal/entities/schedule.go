@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+// Schedule overlap policies, they define what happens when the schedule
+// fires while the previous run of the command is still active.
+// The parallel policy is not available for the schedules, it is used
+// by the triggers limiting the number of their active runs themselves.
+const (
+	OverlapSkip     = "skip"
+	OverlapQueue    = "queue"
+	OverlapCancel   = "cancel"
+	OverlapParallel = "parallel"
+)
+
+// Schedule contains data of the recurring command schedule.
+type Schedule struct {
+	ID        int         `json:"id"`
+	Name      string      `json:"name"`
+	Cron      string      `json:"cron"`
+	Timezone  string      `json:"timezone"`
+	Overlap   string      `json:"overlap"`
+	NextRunAt time.Time   `json:"next_run_at"`
+	NextRuns  []time.Time `json:"next_runs,omitempty"`
+}
@@ -0,0 +1,70 @@
+package entities
+
+import "time"
+
+// Run triggers of the command triggers.
+const (
+	TriggerURL    = "url"
+	TriggerWatch  = "watch"
+	TriggerNotify = "notify"
+)
+
+// Trigger types.
+const (
+	TriggerTypeURL    = "url"
+	TriggerTypeWatch  = "watch"
+	TriggerTypeNotify = "notify"
+)
+
+// Trigger input modes, they define how the body of the trigger request
+// is passed to the command.
+const (
+	InputNone   = "none"
+	InputStdin  = "stdin"
+	InputParams = "params"
+)
+
+// Ways of passing the changed file path of the watch trigger
+// or the notification payload of the notify trigger to the command.
+const (
+	PassEnv   = "env"
+	PassArg   = "arg"
+	PassStdin = "stdin"
+)
+
+// Environment variables with the data of the watch and notify triggers.
+const (
+	WatchPathEnv     = "SCRIPTS_HUB_PATH"
+	NotifyChannelEnv = "SCRIPTS_HUB_CHANNEL"
+	NotifyPayloadEnv = "SCRIPTS_HUB_PAYLOAD"
+)
+
+// Trigger contains data of the trigger starting the saved command.
+// The URL trigger starts the command by the secret token, the token itself
+// is returned only when the trigger is created or rotated, the storage keeps
+// only its hash. The watch trigger starts the command when the files matching
+// the glob appear or change in the directory. The notify trigger starts
+// the command on the NOTIFY of the database channel.
+type Trigger struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	Input       string     `json:"input,omitempty"`
+	Token       string     `json:"token,omitempty"`
+	TokenHash   string     `json:"-"`
+	Path        string     `json:"path,omitempty"`
+	Glob        string     `json:"glob,omitempty"`
+	Debounce    string     `json:"debounce,omitempty"`
+	Concurrency int        `json:"concurrency,omitempty"`
+	Pass        string     `json:"pass,omitempty"`
+	Channel     string     `json:"channel,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RotatedAt   *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Notification contains the channel and the payload of the database notification.
+type Notification struct {
+	Channel string
+	Payload string
+}
@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Event contains data of the run state change sent to the webhooks.
+type Event struct {
+	Type      string    `json:"type"`
+	RunID     int       `json:"run_id"`
+	Command   string    `json:"command"`
+	Trigger   string    `json:"trigger"`
+	Status    string    `json:"status"`
+	Attempt   int       `json:"attempt,omitempty"`
+	ExitCode  *int      `json:"exit_code,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Delivery contains the event waiting in the outbox for the delivery to the webhook URL.
+type Delivery struct {
+	ID            int             `json:"id"`
+	URL           string          `json:"url"`
+	Event         string          `json:"event"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,60 @@
+package entities
+
+import "time"
+
+// Workflow node dependency conditions, they define the final status
+// of the dependency on which the dependent node runs.
+const (
+	OnSuccess = "success"
+	OnFailure = "failure"
+	OnAlways  = "always"
+)
+
+// NodeWaiting is the status of the workflow node waiting for its dependencies,
+// the finished nodes have the statuses of their runs.
+const NodeWaiting = "waiting"
+
+// TriggerWorkflow is the trigger of the runs started by the workflow.
+const TriggerWorkflow = "workflow"
+
+// Workflow contains data of the graph of the saved commands.
+type Workflow struct {
+	ID    int             `json:"id"`
+	Name  string          `json:"name"`
+	Nodes []*WorkflowNode `json:"nodes"`
+}
+
+// WorkflowNode contains the saved command run as the step of the workflow
+// and the nodes it depends on.
+type WorkflowNode struct {
+	Name    string        `json:"name"`
+	Command string        `json:"command"`
+	Needs   []*Dependency `json:"needs,omitempty"`
+}
+
+// Dependency contains the node the dependent node waits for
+// and the condition of running the dependent node.
+type Dependency struct {
+	Node string `json:"node"`
+	On   string `json:"on,omitempty"`
+}
+
+// WorkflowRun contains data of the single execution of the workflow.
+type WorkflowRun struct {
+	ID         int        `json:"id"`
+	WorkflowID int        `json:"workflow_id"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Nodes      []*NodeRun `json:"nodes"`
+}
+
+// NodeRun contains the status of the workflow node in the workflow run
+// and the run of its command.
+type NodeRun struct {
+	Node    string `json:"node"`
+	Command string `json:"command"`
+	Status  string `json:"status"`
+	RunID   *int   `json:"run_id,omitempty"`
+}
@@ -0,0 +1,10 @@
+package errors
+
+import "errors"
+
+var (
+	ErrAdminUnauthorized = errors.New("admin token is incorrect")
+	ErrAdminDisabled     = errors.New("admin token is not configured")
+	ErrMaintenance       = errors.New("server is in maintenance mode")
+	ErrWorkersIncorrect  = errors.New("number of workers is incorrect")
+)
@@ -0,0 +1,11 @@
+package errors
+
+import "errors"
+
+var (
+	ErrAgentNotFound     = errors.New("agent not found")
+	ErrAgentIncorrect    = errors.New("agent is incorrect")
+	ErrAgentUnauthorized = errors.New("agent token is incorrect")
+	ErrAgentLost         = errors.New("agent lost")
+	ErrAgentsDisabled    = errors.New("agent token is not configured")
+)
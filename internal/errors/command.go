@@ -6,4 +6,9 @@ import "errors"
 var (
 	ErrCmdNotFound      = errors.New("command not found")
 	ErrCmdAlreadyExists = errors.New("command already exists")
+
+	ErrQueueNotFound    = errors.New("queue not found")
+	ErrQueueClosed      = errors.New("queue is closed")
+	ErrQueueFull        = errors.New("queue is full")
+	ErrQueueCallerLimit = errors.New("caller queued jobs limit reached")
 )
@@ -0,0 +1,7 @@
+package errors
+
+import "errors"
+
+var (
+	ErrExecutorNotFound = errors.New("executor not found")
+)
@@ -0,0 +1,10 @@
+package errors
+
+import "errors"
+
+var (
+	ErrMigrationVersion = errors.New("database migration version mismatch")
+	ErrWorkersStopped   = errors.New("queue workers are not running")
+	ErrWorkersStalled   = errors.New("queue workers are stuck")
+	ErrShuttingDown     = errors.New("server is shutting down")
+)
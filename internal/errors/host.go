@@ -0,0 +1,7 @@
+package errors
+
+import "errors"
+
+var (
+	ErrHostNotFound = errors.New("ssh host not found")
+)
@@ -0,0 +1,8 @@
+package errors
+
+import "errors"
+
+var (
+	ErrPipelineNotFound  = errors.New("pipeline not found")
+	ErrPipelineIncorrect = errors.New("pipeline is incorrect")
+)
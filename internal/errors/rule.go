@@ -0,0 +1,10 @@
+package errors
+
+import "errors"
+
+var (
+	ErrRuleNotFound      = errors.New("rule not found")
+	ErrRuleAlreadyExists = errors.New("rule already exists")
+	ErrRuleIncorrect     = errors.New("rule is incorrect")
+	ErrRuleCycle         = errors.New("rules form a cycle")
+)
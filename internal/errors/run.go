@@ -0,0 +1,10 @@
+package errors
+
+import "errors"
+
+var (
+	ErrRunNotFound  = errors.New("run not found")
+	ErrRunCancelled = errors.New("run cancelled")
+
+	ErrRetryIncorrect = errors.New("retry policy is incorrect")
+)
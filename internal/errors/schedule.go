@@ -0,0 +1,9 @@
+package errors
+
+import "errors"
+
+var (
+	ErrScheduleNotFound      = errors.New("schedule not found")
+	ErrScheduleAlreadyExists = errors.New("schedule already exists")
+	ErrScheduleIncorrect     = errors.New("schedule is incorrect")
+)
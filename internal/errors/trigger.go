@@ -0,0 +1,10 @@
+package errors
+
+import "errors"
+
+var (
+	ErrTriggerNotFound     = errors.New("trigger not found")
+	ErrTriggerIncorrect    = errors.New("trigger is incorrect")
+	ErrTriggerUnauthorized = errors.New("trigger token is incorrect")
+	ErrParamsIncorrect     = errors.New("command parameters are incorrect")
+)
@@ -0,0 +1,8 @@
+package errors
+
+import "errors"
+
+var (
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookIncorrect = errors.New("webhook url is incorrect")
+)
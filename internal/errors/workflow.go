@@ -0,0 +1,10 @@
+package errors
+
+import "errors"
+
+var (
+	ErrWorkflowNotFound      = errors.New("workflow not found")
+	ErrWorkflowAlreadyExists = errors.New("workflow already exists")
+	ErrWorkflowIncorrect     = errors.New("workflow is incorrect")
+	ErrWorkflowRunNotFound   = errors.New("workflow run not found")
+)
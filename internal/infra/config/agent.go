@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// AgentConfig contains values of the remote agent flags and environments.
+type AgentConfig struct {
+	HubURL   string    `env:"HUB_URL" json:"hub_url"`
+	Name     string    `env:"AGENT_NAME" json:"agent_name"`
+	Labels   LabelList `env:"AGENT_LABELS" json:"agent_labels"`
+	Capacity int       `env:"AGENT_CAPACITY" json:"agent_capacity"`
+	Token    string    `env:"AGENT_TOKEN" json:"-"`
+
+	KillTimeout time.Duration `env:"AGENT_KILL_TIMEOUT" json:"agent_kill_timeout"`
+}
+
+// LabelList contains the agent labels in the form "label,label".
+type LabelList []string
+
+// NewAgentConfig returns new remote agent config.
+func NewAgentConfig(ctx context.Context) *AgentConfig {
+	return &AgentConfig{}
+}
+
+// ParseFlags handles and processes flags and environments values
+// when launching the remote agent.
+func (cfg *AgentConfig) ParseFlags(ctx context.Context) error {
+	hostname, _ := os.Hostname()
+
+	flag.StringVar(&cfg.HubURL, "a", "http://localhost:8080", "URL of the scripts hub server")
+	flag.StringVar(&cfg.Name, "n", hostname, "Name of the agent shown in the agents list")
+	flag.Var(&cfg.Labels, "l", "Labels of the agent selecting the commands it runs, e.g. linux,gpu")
+	flag.IntVar(&cfg.Capacity, "c", 1, "Maximum number of the commands run by the agent at once")
+	flag.StringVar(&cfg.Token, "r", "", "Token of the remote agents configured on the server")
+	flag.DurationVar(&cfg.KillTimeout, "p", 10*time.Second, "Time the command of the run unknown by the server may exit after SIGTERM before it is killed")
+
+	flag.Parse()
+
+	err := env.Parse(cfg)
+	if err != nil {
+		return fmt.Errorf("ParseFlags: wrong environment values %w", err)
+	}
+
+	return nil
+}
+
+// String returns the labels in the form "label,label".
+func (l LabelList) String() string {
+	return strings.Join(l, ",")
+}
+
+// Set parses the labels from the form "label,label".
+func (l *LabelList) Set(value string) error {
+	labels := make(LabelList, 0)
+
+	for _, label := range strings.Split(value, ",") {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			continue
+		}
+		labels = append(labels, label)
+	}
+
+	*l = labels
+
+	return nil
+}
+
+// UnmarshalText implements parsing the labels from the environment.
+func (l *LabelList) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
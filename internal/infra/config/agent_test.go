@@ -0,0 +1,48 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAgentConfig(t *testing.T) {
+	want := &AgentConfig{}
+	t.Run("success", func(t *testing.T) {
+		got := NewAgentConfig(context.Background())
+		require.Equal(t, want, got)
+	})
+}
+
+func TestLabelList_Set(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  LabelList
+	}{
+		{
+			name:  "success",
+			value: "linux, gpu",
+			want:  LabelList{"linux", "gpu"},
+		},
+		{
+			name:  "empty_labels_skipped",
+			value: "linux,,",
+			want:  LabelList{"linux"},
+		},
+		{
+			name:  "empty",
+			value: "",
+			want:  LabelList{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got LabelList
+			err := got.Set(tt.value)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
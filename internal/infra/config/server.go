@@ -6,6 +6,12 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 )
@@ -15,8 +21,61 @@ type Config struct {
 	Address   string `env:"ADDRESS" json:"address"`
 	DSN       string `env:"DATABASE_DSN" json:"database_dsn"`
 	RateLimit int    `env:"RATE_LIMIT" json:"rate_limit"`
+
+	PriorityAging time.Duration `env:"PRIORITY_AGING" json:"priority_aging"`
+	Queues        QueueLimits   `env:"QUEUES" json:"queues"`
+
+	QueueMaxDepth    int       `env:"QUEUE_MAX_DEPTH" json:"queue_max_depth"`
+	QueueCallerLimit int       `env:"QUEUE_CALLER_LIMIT" json:"queue_caller_limit"`
+	TrustedProxies   ProxyList `env:"TRUSTED_PROXIES" json:"trusted_proxies"`
+
+	ScheduleInterval time.Duration `env:"SCHEDULE_INTERVAL" json:"schedule_interval"`
+
+	WebhookURLs        URLList       `env:"WEBHOOK_URLS" json:"webhook_urls"`
+	WebhookSecret      string        `env:"WEBHOOK_SECRET" json:"-"`
+	WebhookInterval    time.Duration `env:"WEBHOOK_INTERVAL" json:"webhook_interval"`
+	WebhookMaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS" json:"webhook_max_attempts"`
+
+	WatchInterval  time.Duration `env:"WATCH_INTERVAL" json:"watch_interval"`
+	NotifyInterval time.Duration `env:"NOTIFY_INTERVAL" json:"notify_interval"`
+
+	TraceEndpoint string        `env:"TRACE_ENDPOINT" json:"trace_endpoint"`
+	TraceFile     string        `env:"TRACE_FILE" json:"trace_file"`
+	TraceInterval time.Duration `env:"TRACE_INTERVAL" json:"trace_interval"`
+
+	WorkerStallTimeout time.Duration `env:"WORKER_STALL_TIMEOUT" json:"worker_stall_timeout"`
+	ShutdownDelay      time.Duration `env:"SHUTDOWN_DELAY" json:"shutdown_delay"`
+	DrainTimeout       time.Duration `env:"DRAIN_TIMEOUT" json:"drain_timeout"`
+	KillTimeout        time.Duration `env:"KILL_TIMEOUT" json:"kill_timeout"`
+
+	SpoolDir string `env:"SPOOL_DIR" json:"spool_dir"`
+	ShimPath string `env:"SHIM_PATH" json:"shim_path"`
+
+	AgentToken   string        `env:"AGENT_TOKEN" json:"-"`
+	AgentTimeout time.Duration `env:"AGENT_TIMEOUT" json:"agent_timeout"`
+
+	SSHHosts      HostList `env:"SSH_HOSTS" json:"ssh_hosts"`
+	SSHKey        string   `env:"SSH_KEY" json:"ssh_key"`
+	SSHKnownHosts string   `env:"SSH_KNOWN_HOSTS" json:"ssh_known_hosts"`
+
+	AdminToken string `env:"ADMIN_TOKEN" json:"-"`
 }
 
+// QueueLimits contains the worker limits of the named queues
+// in the form "name:workers,name:workers".
+type QueueLimits map[string]int
+
+// URLList contains the URLs in the form "url,url".
+type URLList []string
+
+// ProxyList contains the addresses and the networks of the trusted proxies
+// in the form "addr,network/bits".
+type ProxyList []netip.Prefix
+
+// HostList contains the addresses of the named SSH hosts
+// in the form "name=user@host:port,name=user@host:port".
+type HostList map[string]string
+
 // NewConfig returns new server config.
 func NewConfig(ctx context.Context) *Config {
 	return &Config{}
@@ -28,6 +87,42 @@ func (cfg *Config) ParseFlags(ctx context.Context) error {
 	flag.StringVar(&cfg.Address, "a", "localhost:8080", "HTTP-server endpoint address host:port")
 	flag.StringVar(&cfg.DSN, "d", "postgresql://localhost:5432/postgres", "URI (DSN) to database")
 	flag.IntVar(&cfg.RateLimit, "l", 3, "Run command workers limit")
+	flag.DurationVar(&cfg.PriorityAging, "g", 30*time.Second, "Interval for raising the priority of the waiting command")
+	flag.Var(&cfg.Queues, "q", "Named queues with their workers limits, e.g. heavy:1,maintenance:1")
+	flag.IntVar(&cfg.QueueMaxDepth, "m", 1000, "Maximum number of waiting commands in each queue, 0 means no limit")
+	flag.IntVar(&cfg.QueueCallerLimit, "c", 100, "Maximum number of waiting commands of one caller in each queue, 0 means no limit")
+	flag.Var(&cfg.TrustedProxies, "P", "Trusted proxies whose X-Forwarded-For header identifies the caller, e.g. 10.0.0.1,10.1.0.0/16")
+
+	flag.DurationVar(&cfg.ScheduleInterval, "s", time.Second, "Interval for checking the due command schedules and delayed runs, 0 disables the scheduler")
+
+	flag.Var(&cfg.WebhookURLs, "u", "Webhook URLs receiving the events of all the commands, e.g. https://ci.local/hook")
+	flag.StringVar(&cfg.WebhookSecret, "k", "", "Secret key for signing the webhook payloads, required by the webhooks")
+	flag.DurationVar(&cfg.WebhookInterval, "i", time.Second, "Interval for sending the webhook deliveries from the outbox, 0 disables the delivery")
+	flag.IntVar(&cfg.WebhookMaxAttempts, "t", 5, "Maximum number of the webhook delivery attempts before the dead letter")
+
+	flag.DurationVar(&cfg.WatchInterval, "w", time.Second, "Interval for reloading the watch triggers and polling their directories without inotify, 0 disables the watcher")
+	flag.DurationVar(&cfg.NotifyInterval, "n", time.Second, "Interval for reloading the notify triggers and retrying to listen their channels, 0 disables the listener")
+
+	flag.StringVar(&cfg.TraceEndpoint, "o", "", "OTLP/HTTP collector endpoint receiving the spans in JSON, e.g. http://localhost:4318/v1/traces")
+	flag.StringVar(&cfg.TraceFile, "f", "", "File for appending the spans in OTLP/JSON lines")
+	flag.DurationVar(&cfg.TraceInterval, "x", time.Second, "Interval for exporting the ended spans")
+
+	flag.DurationVar(&cfg.WorkerStallTimeout, "v", time.Minute, "Time the job may wait in the queue with the idle workers before the workers are reported stuck, 0 disables the check")
+	flag.DurationVar(&cfg.ShutdownDelay, "y", 5*time.Second, "Time the server reports not ready before the shutdown so the load balancers drain the traffic")
+	flag.DurationVar(&cfg.DrainTimeout, "e", 30*time.Second, "Time the running commands may finish after the shutdown signal before they are interrupted")
+	flag.DurationVar(&cfg.KillTimeout, "p", 10*time.Second, "Time the interrupted command may exit after SIGTERM before it is killed")
+
+	flag.StringVar(&cfg.SpoolDir, "j", "", "Directory for the output and the state of the detached runs, empty value disables the detached commands")
+	flag.StringVar(&cfg.ShimPath, "b", "", "Path to the supervisor binary of the detached runs, scripts-hub-shim next to the server binary by default")
+
+	flag.StringVar(&cfg.AgentToken, "r", "", "Token of the remote agents, empty value disables the remote agents")
+	flag.DurationVar(&cfg.AgentTimeout, "z", time.Minute, "Time since the last poll after which the remote agent is lost and its runs fail")
+
+	flag.Var(&cfg.SSHHosts, "S", "Named SSH hosts running the commands, e.g. db=deploy@db.local:22")
+	flag.StringVar(&cfg.SSHKey, "K", "", "Private key file for the authentication on the SSH hosts")
+	flag.StringVar(&cfg.SSHKnownHosts, "H", "", "Known hosts file for checking the keys of the SSH hosts")
+
+	flag.StringVar(&cfg.AdminToken, "A", "", "Token of the admin API changing the server settings at runtime, empty value disables the admin API")
 
 	flag.Parse()
 
@@ -36,5 +131,205 @@ func (cfg *Config) ParseFlags(ctx context.Context) error {
 		return fmt.Errorf("ParseFlags: wrong environment values %w", err)
 	}
 
+	err = cfg.validate()
+	if err != nil {
+		return fmt.Errorf("ParseFlags: incorrect config %w", err)
+	}
+
+	return nil
+}
+
+// validate checks the settings depending on each other.
+func (cfg *Config) validate() error {
+	if len(cfg.WebhookURLs) > 0 && cfg.WebhookSecret == "" {
+		return fmt.Errorf("validate: webhook urls require the webhook secret")
+	}
+
+	return nil
+}
+
+// String returns the queue limits in the form "name:workers,name:workers".
+func (l QueueLimits) String() string {
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+":"+strconv.Itoa(l[name]))
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// Set parses the queue limits from the form "name:workers,name:workers".
+func (l *QueueLimits) Set(value string) error {
+	limits := make(QueueLimits)
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, workers, ok := strings.Cut(pair, ":")
+		if !ok || name == "" {
+			return fmt.Errorf("Set: incorrect queue limit %q", pair)
+		}
+
+		n, err := strconv.Atoi(workers)
+		if err != nil || n < 1 {
+			return fmt.Errorf("Set: incorrect workers limit for queue %q", name)
+		}
+
+		limits[name] = n
+	}
+
+	*l = limits
+
+	return nil
+}
+
+// UnmarshalText implements parsing the queue limits from the environment.
+func (l *QueueLimits) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
+
+// String returns the URLs in the form "url,url".
+func (l URLList) String() string {
+	return strings.Join(l, ",")
+}
+
+// Set parses the URLs from the form "url,url".
+func (l *URLList) Set(value string) error {
+	urls := make(URLList, 0)
+
+	for _, u := range strings.Split(value, ",") {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+		urls = append(urls, u)
+	}
+
+	*l = urls
+
+	return nil
+}
+
+// UnmarshalText implements parsing the URLs from the environment.
+func (l *URLList) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
+
+// String returns the proxies in the form "addr/bits,network/bits".
+func (l ProxyList) String() string {
+	proxies := make([]string, 0, len(l))
+	for _, p := range l {
+		proxies = append(proxies, p.String())
+	}
+	return strings.Join(proxies, ",")
+}
+
+// Set parses the proxies from the form "addr,network/bits".
+func (l *ProxyList) Set(value string) error {
+	proxies := make(ProxyList, 0)
+
+	for _, proxy := range strings.Split(value, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return fmt.Errorf("Set: incorrect proxy address %q", proxy)
+			}
+			addr = addr.Unmap()
+			proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return fmt.Errorf("Set: incorrect proxy network %q", proxy)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+
+	*l = proxies
+
+	return nil
+}
+
+// UnmarshalText implements parsing the proxies from the environment.
+func (l *ProxyList) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
+
+// Trusted checks whether the address belongs to one of the proxies.
+func (l ProxyList) Trusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, p := range l {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the hosts in the form "name=user@host:port,name=user@host:port".
+func (l HostList) String() string {
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+l[name])
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// Set parses the hosts from the form "name=user@host:port,name=user@host:port".
+// The port is 22 if it is not specified.
+func (l *HostList) Set(value string) error {
+	hosts := make(HostList)
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, addr, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("Set: incorrect ssh host %q", pair)
+		}
+
+		user, host, ok := strings.Cut(addr, "@")
+		if !ok || user == "" || host == "" {
+			return fmt.Errorf("Set: incorrect address of ssh host %q", name)
+		}
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, "22")
+		}
+
+		hosts[name] = user + "@" + host
+	}
+
+	*l = hosts
+
 	return nil
 }
+
+// UnmarshalText implements parsing the hosts from the environment.
+func (l *HostList) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"net/netip"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -22,3 +23,180 @@ func TestConfig_ParseFlags(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestConfig_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{
+			name: "success",
+			cfg:  Config{WebhookURLs: URLList{"https://ci.local/hook"}, WebhookSecret: "secret"},
+		},
+		{
+			name: "no_webhooks",
+			cfg:  Config{},
+		},
+		{
+			name:    "webhooks_without_secret",
+			cfg:     Config{WebhookURLs: URLList{"https://ci.local/hook"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestQueueLimits_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    QueueLimits
+		wantErr bool
+	}{
+		{
+			name:  "success",
+			value: "heavy:2, maintenance:1",
+			want:  QueueLimits{"heavy": 2, "maintenance": 1},
+		},
+		{
+			name:  "empty",
+			value: "",
+			want:  QueueLimits{},
+		},
+		{
+			name:    "no_workers",
+			value:   "heavy",
+			wantErr: true,
+		},
+		{
+			name:    "zero_workers",
+			value:   "heavy:0",
+			wantErr: true,
+		},
+		{
+			name:    "empty_name",
+			value:   ":1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got QueueLimits
+			err := got.Set(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHostList_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    HostList
+		wantErr bool
+	}{
+		{
+			name:  "success",
+			value: "db=deploy@db.local:2222, web=root@10.0.0.1",
+			want:  HostList{"db": "deploy@db.local:2222", "web": "root@10.0.0.1:22"},
+		},
+		{
+			name:  "empty",
+			value: "",
+			want:  HostList{},
+		},
+		{
+			name:    "no_address",
+			value:   "db",
+			wantErr: true,
+		},
+		{
+			name:    "no_user",
+			value:   "db=db.local",
+			wantErr: true,
+		},
+		{
+			name:    "empty_name",
+			value:   "=deploy@db.local",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got HostList
+			err := got.Set(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProxyList_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "success",
+			value: "10.0.0.1, 10.1.2.3/16, ::ffff:192.0.2.1",
+			want:  "10.0.0.1/32,10.1.0.0/16,192.0.2.1/32",
+		},
+		{
+			name:  "empty",
+			value: "",
+			want:  "",
+		},
+		{
+			name:    "incorrect_address",
+			value:   "proxy.local",
+			wantErr: true,
+		},
+		{
+			name:    "incorrect_network",
+			value:   "10.0.0.0/33",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ProxyList
+			err := got.Set(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestProxyList_Trusted(t *testing.T) {
+	var proxies ProxyList
+	require.NoError(t, proxies.Set("10.0.0.1,192.168.0.0/16"))
+
+	require.True(t, proxies.Trusted(netip.MustParseAddr("10.0.0.1")))
+	require.True(t, proxies.Trusted(netip.MustParseAddr("::ffff:192.168.1.1")))
+	require.False(t, proxies.Trusted(netip.MustParseAddr("10.0.0.2")))
+}
@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"embed"
 	"fmt"
+	"io/fs"
 
 	"github.com/pressly/goose/v3"
 )
@@ -39,3 +40,25 @@ func Init(ctx context.Context, path string) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// LatestVersion returns the version of the latest embedded migration
+// which the database is migrated to on initialization.
+func LatestVersion() (int64, error) {
+	entries, err := fs.ReadDir(embedMigrations, "migrations")
+	if err != nil {
+		return 0, fmt.Errorf("LatestVersion: read migrations failed %w", err)
+	}
+
+	var latest int64
+	for _, e := range entries {
+		v, err := goose.NumericComponent(e.Name())
+		if err != nil {
+			return 0, fmt.Errorf("LatestVersion: %w", err)
+		}
+		if v > latest {
+			latest = v
+		}
+	}
+
+	return latest, nil
+}
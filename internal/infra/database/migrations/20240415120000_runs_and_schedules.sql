@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+CREATE TABLE IF NOT EXISTS runs (
+    id serial PRIMARY KEY,
+    command_id integer NOT NULL REFERENCES commands (id) ON DELETE CASCADE,
+    trigger varchar(32) NOT NULL,
+    status varchar(16) NOT NULL,
+    exit_code integer,
+    output bytea DEFAULT ''::bytea,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    started_at timestamptz,
+    finished_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS schedules (
+    id serial PRIMARY KEY,
+    command_id integer UNIQUE NOT NULL REFERENCES commands (id) ON DELETE CASCADE,
+    cron varchar(128) NOT NULL,
+    timezone varchar(64) NOT NULL DEFAULT 'UTC',
+    overlap varchar(16) NOT NULL DEFAULT 'skip',
+    next_run_at timestamptz NOT NULL
+);
+
+-- create indexes
+CREATE INDEX IF NOT EXISTS run_command_id_idx ON runs (command_id);
+CREATE INDEX IF NOT EXISTS schedule_next_run_at_idx ON schedules (next_run_at);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP INDEX schedule_next_run_at_idx;
+DROP INDEX run_command_id_idx;
+DROP TABLE schedules;
+DROP TABLE runs;
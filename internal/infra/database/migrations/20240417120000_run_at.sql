@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS run_at timestamptz;
+
+-- create indexes
+CREATE INDEX IF NOT EXISTS run_pending_run_at_idx ON runs (run_at) WHERE status = 'pending';
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP INDEX run_pending_run_at_idx;
+ALTER TABLE runs DROP COLUMN run_at;
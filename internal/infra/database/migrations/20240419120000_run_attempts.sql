@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+ALTER TABLE commands ADD COLUMN IF NOT EXISTS max_attempts integer NOT NULL DEFAULT 1;
+ALTER TABLE commands ADD COLUMN IF NOT EXISTS retry_backoff varchar(32) NOT NULL DEFAULT '';
+ALTER TABLE commands ADD COLUMN IF NOT EXISTS retry_max_backoff varchar(32) NOT NULL DEFAULT '';
+ALTER TABLE commands ADD COLUMN IF NOT EXISTS retry_exit_codes varchar(256) NOT NULL DEFAULT '';
+
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS attempt integer NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS attempts (
+    id serial PRIMARY KEY,
+    run_id integer NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+    attempt integer NOT NULL,
+    status varchar(16) NOT NULL,
+    exit_code integer,
+    output bytea DEFAULT ''::bytea,
+    started_at timestamptz NOT NULL,
+    finished_at timestamptz,
+    UNIQUE (run_id, attempt)
+);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP TABLE attempts;
+ALTER TABLE runs DROP COLUMN attempt;
+ALTER TABLE commands DROP COLUMN retry_exit_codes;
+ALTER TABLE commands DROP COLUMN retry_max_backoff;
+ALTER TABLE commands DROP COLUMN retry_backoff;
+ALTER TABLE commands DROP COLUMN max_attempts;
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+CREATE TABLE IF NOT EXISTS workflows (
+    id serial PRIMARY KEY,
+    name varchar(64) UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS workflow_nodes (
+    id serial PRIMARY KEY,
+    workflow_id integer NOT NULL REFERENCES workflows (id) ON DELETE CASCADE,
+    name varchar(64) NOT NULL,
+    command varchar(32) NOT NULL,
+    needs text NOT NULL DEFAULT '',
+    UNIQUE (workflow_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS workflow_runs (
+    id serial PRIMARY KEY,
+    workflow_id integer NOT NULL REFERENCES workflows (id) ON DELETE CASCADE,
+    status varchar(16) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    finished_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS workflow_node_runs (
+    id serial PRIMARY KEY,
+    workflow_run_id integer NOT NULL REFERENCES workflow_runs (id) ON DELETE CASCADE,
+    node varchar(64) NOT NULL,
+    command varchar(32) NOT NULL,
+    status varchar(16) NOT NULL,
+    run_id integer REFERENCES runs (id) ON DELETE SET NULL,
+    UNIQUE (workflow_run_id, node)
+);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP TABLE workflow_node_runs;
+DROP TABLE workflow_runs;
+DROP TABLE workflow_nodes;
+DROP TABLE workflows;
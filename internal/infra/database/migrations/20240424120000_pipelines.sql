@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+CREATE TABLE IF NOT EXISTS pipelines (
+    id serial PRIMARY KEY,
+    status varchar(16) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    finished_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS pipeline_steps (
+    id serial PRIMARY KEY,
+    pipeline_id integer NOT NULL REFERENCES pipelines (id) ON DELETE CASCADE,
+    step integer NOT NULL,
+    run_id integer NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+    UNIQUE (pipeline_id, step)
+);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP TABLE pipeline_steps;
+DROP TABLE pipelines;
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+ALTER TABLE commands ADD COLUMN IF NOT EXISTS webhooks text NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS deliveries (
+    id serial PRIMARY KEY,
+    url text NOT NULL,
+    event varchar(32) NOT NULL,
+    payload text NOT NULL,
+    status varchar(16) NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    last_error text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    delivered_at timestamptz
+);
+
+-- create indexes
+CREATE INDEX IF NOT EXISTS delivery_status_next_attempt_at_idx ON deliveries (status, next_attempt_at);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP INDEX delivery_status_next_attempt_at_idx;
+DROP TABLE deliveries;
+ALTER TABLE commands DROP COLUMN webhooks;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+CREATE TABLE IF NOT EXISTS triggers (
+    id serial PRIMARY KEY,
+    command_id integer NOT NULL REFERENCES commands (id) ON DELETE CASCADE,
+    input varchar(16) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    rotated_at timestamptz,
+    revoked_at timestamptz
+);
+
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS trigger_id integer REFERENCES triggers (id) ON DELETE SET NULL;
+
+-- create indexes
+CREATE INDEX IF NOT EXISTS trigger_command_id_idx ON triggers (command_id);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+ALTER TABLE runs DROP COLUMN trigger_id;
+DROP INDEX trigger_command_id_idx;
+DROP TABLE triggers;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+ALTER TABLE triggers ADD COLUMN IF NOT EXISTS type varchar(16) NOT NULL DEFAULT 'url';
+ALTER TABLE triggers ADD COLUMN IF NOT EXISTS path text NOT NULL DEFAULT '';
+ALTER TABLE triggers ADD COLUMN IF NOT EXISTS glob text NOT NULL DEFAULT '';
+ALTER TABLE triggers ADD COLUMN IF NOT EXISTS debounce varchar(32) NOT NULL DEFAULT '';
+ALTER TABLE triggers ADD COLUMN IF NOT EXISTS concurrency integer NOT NULL DEFAULT 0;
+ALTER TABLE triggers ADD COLUMN IF NOT EXISTS pass varchar(8) NOT NULL DEFAULT '';
+
+-- create indexes
+CREATE INDEX IF NOT EXISTS trigger_type_idx ON triggers (type) WHERE revoked_at IS NULL;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP INDEX trigger_type_idx;
+ALTER TABLE triggers DROP COLUMN pass;
+ALTER TABLE triggers DROP COLUMN concurrency;
+ALTER TABLE triggers DROP COLUMN debounce;
+ALTER TABLE triggers DROP COLUMN glob;
+ALTER TABLE triggers DROP COLUMN path;
+ALTER TABLE triggers DROP COLUMN type;
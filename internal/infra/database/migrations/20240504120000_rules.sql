@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+CREATE TABLE IF NOT EXISTS rules (
+    id serial PRIMARY KEY,
+    command_id integer NOT NULL REFERENCES commands (id) ON DELETE CASCADE,
+    event varchar(16) NOT NULL,
+    target_id integer NOT NULL REFERENCES commands (id) ON DELETE CASCADE,
+    enabled boolean NOT NULL DEFAULT true,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (command_id, event, target_id)
+);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP TABLE rules;
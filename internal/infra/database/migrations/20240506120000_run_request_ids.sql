@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS request_id varchar(128) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS runs_request_id_idx ON runs (request_id);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP INDEX IF EXISTS runs_request_id_idx;
+ALTER TABLE runs DROP COLUMN request_id;
@@ -0,0 +1,57 @@
+// Package executor contains the interface of the backends running
+// the command processes, the local process backend and the in-memory
+// fake backend for the tests.
+package executor
+
+import (
+	"context"
+	"io"
+	"syscall"
+	"time"
+)
+
+// Local is the name of the executor running the commands on the server host,
+// it is used by the commands without the executor.
+const Local = "local"
+
+// Executor starts the processes of the commands.
+type Executor interface {
+	// LookPath checks that the command can be started by the executor.
+	LookPath(path string) error
+	// Start starts the process of the command.
+	Start(ctx context.Context, spec *Spec) (Process, error)
+}
+
+// Process is the started process of the command.
+type Process interface {
+	// Wait waits for the process to exit. It returns the exit code and
+	// the error if the process failed, the exit code is -1 if it is unknown.
+	Wait() (int, error)
+	// Signal sends the signal to the process, os.ErrProcessDone is returned
+	// if the process has exited.
+	Signal(sig syscall.Signal) error
+	// Kill stops the process immediately.
+	Kill() error
+	// Usage returns the resources used by the exited process.
+	Usage() Usage
+}
+
+// Spec contains the command started by the executor. The environment
+// variables are added to the environment of the executor host.
+// The nil streams are discarded.
+type Spec struct {
+	Path   string
+	Args   []string
+	Env    []string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Usage contains the resources used by the process,
+// the zero values are not reported by the executor.
+type Usage struct {
+	UserTime   time.Duration
+	SystemTime time.Duration
+	MaxRSS     int64
+}
@@ -0,0 +1,203 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+)
+
+// ErrKilled is returned by the wait of the killed fake process.
+var ErrKilled = errors.New("fake process killed")
+
+// FakeFunc is the behaviour of the fake command. It reads the input, writes
+// the output and returns the exit code. It has to return when the context
+// is done, the context is cancelled when the process is killed.
+type FakeFunc func(ctx context.Context, io *FakeIO) int
+
+// FakeIO contains the arguments and the streams of the fake command.
+// The signals sent to the process except SIGKILL are received from Signals.
+// The function may set the resource usage reported after the exit.
+type FakeIO struct {
+	Args    []string
+	Env     []string
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+	Signals <-chan syscall.Signal
+	Usage   Usage
+}
+
+// Fake is the in-memory executor for the deterministic tests, the commands
+// are run by the functions registered for their paths in the goroutines.
+type Fake struct {
+	mu      sync.Mutex
+	funcs   map[string]FakeFunc
+	started []Spec
+}
+
+// fakeProcess contains the state of the started fake command.
+type fakeProcess struct {
+	io      *FakeIO
+	signals chan syscall.Signal
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu     sync.Mutex
+	killed bool
+	code   int
+}
+
+// NewFake returns new fake executor without commands.
+func NewFake() *Fake {
+	return &Fake{
+		funcs: make(map[string]FakeFunc),
+	}
+}
+
+// Handle registers the function running the command of the path.
+func (f *Fake) Handle(path string, fn FakeFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.funcs[path] = fn
+}
+
+// Started returns the specs of the started commands in the start order.
+func (f *Fake) Started() []Spec {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Spec{}, f.started...)
+}
+
+// LookPath checks that the function of the command is registered.
+func (f *Fake) LookPath(path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.funcs[path]; !ok {
+		return fmt.Errorf("LookPath: %s %w", path, exec.ErrNotFound)
+	}
+	return nil
+}
+
+// Start runs the function of the command in the new goroutine.
+func (f *Fake) Start(ctx context.Context, spec *Spec) (Process, error) {
+	f.mu.Lock()
+	fn, ok := f.funcs[spec.Path]
+	if ok {
+		f.started = append(f.started, *spec)
+	}
+	f.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("Start: %s %w", spec.Path, exec.ErrNotFound)
+	}
+
+	signals := make(chan syscall.Signal, 8)
+	fio := &FakeIO{
+		Args:    spec.Args,
+		Env:     spec.Env,
+		Stdin:   spec.Stdin,
+		Stdout:  spec.Stdout,
+		Stderr:  spec.Stderr,
+		Signals: signals,
+	}
+	if fio.Stdin == nil {
+		fio.Stdin = &bytes.Buffer{}
+	}
+	if fio.Stdout == nil {
+		fio.Stdout = io.Discard
+	}
+	if fio.Stderr == nil {
+		fio.Stderr = io.Discard
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &fakeProcess{
+		io:      fio,
+		signals: signals,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		code := fn(ctx, fio)
+
+		p.mu.Lock()
+		p.code = code
+		p.mu.Unlock()
+
+		cancel()
+		close(p.done)
+	}()
+
+	return p, nil
+}
+
+// Wait waits for the function of the command to return.
+func (p *fakeProcess) Wait() (int, error) {
+	<-p.done
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case p.killed:
+		return -1, fmt.Errorf("Wait: %w", ErrKilled)
+	case p.code != 0:
+		return p.code, fmt.Errorf("Wait: exit status %d", p.code)
+	default:
+		return 0, nil
+	}
+}
+
+// Signal passes the signal to the function of the command,
+// SIGKILL kills the process.
+func (p *fakeProcess) Signal(sig syscall.Signal) error {
+	if sig == syscall.SIGKILL {
+		return p.Kill()
+	}
+
+	select {
+	case <-p.done:
+		return fmt.Errorf("Signal: %w", os.ErrProcessDone)
+	default:
+	}
+
+	select {
+	case p.signals <- sig:
+		return nil
+	default:
+		return fmt.Errorf("Signal: signals of the fake process are not received")
+	}
+}
+
+// Kill cancels the context of the function of the command.
+func (p *fakeProcess) Kill() error {
+	select {
+	case <-p.done:
+		return fmt.Errorf("Kill: %w", os.ErrProcessDone)
+	default:
+	}
+
+	p.mu.Lock()
+	p.killed = true
+	p.mu.Unlock()
+
+	p.cancel()
+	return nil
+}
+
+// Usage returns the resource usage set by the function of the exited command.
+func (p *fakeProcess) Usage() Usage {
+	select {
+	case <-p.done:
+		return p.io.Usage
+	default:
+		return Usage{}
+	}
+}
@@ -0,0 +1,85 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake_Start(t *testing.T) {
+	ctx := context.Background()
+
+	f := NewFake()
+	f.Handle("greet", func(ctx context.Context, fio *FakeIO) int {
+		name, _ := io.ReadAll(fio.Stdin)
+		fmt.Fprintf(fio.Stdout, "hello %s %v\n", name, fio.Args)
+		fio.Usage = Usage{UserTime: time.Second}
+		return 0
+	})
+	f.Handle("fail", func(ctx context.Context, fio *FakeIO) int {
+		return 3
+	})
+
+	var out bytes.Buffer
+	p, err := f.Start(ctx, &Spec{Path: "greet", Args: []string{"-v"}, Stdin: bytes.NewBufferString("world"), Stdout: &out})
+	require.NoError(t, err)
+	code, err := p.Wait()
+	require.NoError(t, err)
+	require.Equal(t, 0, code)
+	require.Equal(t, "hello world [-v]\n", out.String())
+	require.Equal(t, Usage{UserTime: time.Second}, p.Usage())
+
+	p, err = f.Start(ctx, &Spec{Path: "fail"})
+	require.NoError(t, err)
+	code, err = p.Wait()
+	require.Error(t, err)
+	require.Equal(t, 3, code)
+
+	// The commands without functions are not found
+	require.Error(t, f.LookPath("missing"))
+	_, err = f.Start(ctx, &Spec{Path: "missing"})
+	require.Error(t, err)
+
+	require.Len(t, f.Started(), 2)
+	require.Equal(t, "greet", f.Started()[0].Path)
+}
+
+func TestFake_Signal(t *testing.T) {
+	ctx := context.Background()
+
+	f := NewFake()
+	f.Handle("serve", func(ctx context.Context, fio *FakeIO) int {
+		select {
+		case sig := <-fio.Signals:
+			return 128 + int(sig)
+		case <-ctx.Done():
+			return 0
+		}
+	})
+
+	// The signal is received by the function
+	p, err := f.Start(ctx, &Spec{Path: "serve"})
+	require.NoError(t, err)
+	require.NoError(t, p.Signal(syscall.SIGTERM))
+	code, err := p.Wait()
+	require.Error(t, err)
+	require.Equal(t, 128+int(syscall.SIGTERM), code)
+	require.True(t, errors.Is(p.Signal(syscall.SIGTERM), os.ErrProcessDone))
+
+	// The killed function is stopped by its context
+	p, err = f.Start(ctx, &Spec{Path: "serve"})
+	require.NoError(t, err)
+	require.NoError(t, p.Signal(syscall.SIGKILL))
+	code, err = p.Wait()
+	require.ErrorIs(t, err, ErrKilled)
+	require.Equal(t, -1, code)
+	require.True(t, errors.Is(p.Kill(), os.ErrProcessDone))
+}
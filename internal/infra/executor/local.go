@@ -0,0 +1,89 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// LocalExecutor runs the commands as the processes of the server host.
+type LocalExecutor struct{}
+
+// localProcess contains the started command of the server host.
+type localProcess struct {
+	cmd *exec.Cmd
+}
+
+// NewLocal returns new executor of the server host processes.
+func NewLocal() *LocalExecutor {
+	return &LocalExecutor{}
+}
+
+// LookPath checks that the command is found in the PATH of the server.
+func (e *LocalExecutor) LookPath(path string) error {
+	_, err := exec.LookPath(path)
+	if err != nil {
+		return fmt.Errorf("LookPath: %w", err)
+	}
+	return nil
+}
+
+// Start starts the command process with the environment of the server
+// and the environment variables of the spec.
+func (e *LocalExecutor) Start(ctx context.Context, spec *Spec) (Process, error) {
+	cmd := exec.Command(spec.Path, spec.Args...)
+	cmd.Env = append(os.Environ(), spec.Env...)
+	cmd.Stdin = spec.Stdin
+	cmd.Stdout = spec.Stdout
+	cmd.Stderr = spec.Stderr
+
+	err := cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("Start: %w", err)
+	}
+
+	return &localProcess{cmd: cmd}, nil
+}
+
+// Wait waits for the process to exit and for its streams to be copied.
+func (p *localProcess) Wait() (int, error) {
+	err := p.cmd.Wait()
+	if err != nil {
+		return p.cmd.ProcessState.ExitCode(), fmt.Errorf("Wait: %w", err)
+	}
+	return p.cmd.ProcessState.ExitCode(), nil
+}
+
+// Signal sends the signal to the process.
+func (p *localProcess) Signal(sig syscall.Signal) error {
+	err := p.cmd.Process.Signal(sig)
+	if err != nil {
+		return fmt.Errorf("Signal: %w", err)
+	}
+	return nil
+}
+
+// Kill kills the process.
+func (p *localProcess) Kill() error {
+	err := p.cmd.Process.Kill()
+	if err != nil {
+		return fmt.Errorf("Kill: %w", err)
+	}
+	return nil
+}
+
+// Usage returns the CPU time and the maximum resident set size of the process.
+func (p *localProcess) Usage() Usage {
+	state := p.cmd.ProcessState
+	if state == nil {
+		return Usage{}
+	}
+
+	return Usage{
+		UserTime:   state.UserTime(),
+		SystemTime: state.SystemTime(),
+		MaxRSS:     maxRSS(state),
+	}
+}
@@ -0,0 +1,85 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalExecutor_Start(t *testing.T) {
+	ctx := context.Background()
+	e := NewLocal()
+
+	tests := []struct {
+		name       string
+		spec       Spec
+		wantCode   int
+		wantOutput string
+	}{
+		{
+			name:       "success",
+			spec:       Spec{Path: "echo", Args: []string{"hello"}},
+			wantOutput: "hello\n",
+		},
+		{
+			name:       "env_and_input",
+			spec:       Spec{Path: "sh", Args: []string{"-c", "echo $GREETING; cat"}, Env: []string{"GREETING=hello"}, Stdin: strings.NewReader("world\n")},
+			wantOutput: "hello\nworld\n",
+		},
+		{
+			name:     "failed",
+			spec:     Spec{Path: "sh", Args: []string{"-c", "exit 3"}},
+			wantCode: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, e.LookPath(tt.spec.Path))
+
+			var out bytes.Buffer
+			tt.spec.Stdout = &out
+
+			p, err := e.Start(ctx, &tt.spec)
+			require.NoError(t, err)
+
+			code, err := p.Wait()
+			require.Equal(t, tt.wantCode, code)
+			if tt.wantCode != 0 {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantOutput, out.String())
+		})
+	}
+
+	require.Error(t, e.LookPath("scripts-hub-nonexistent"))
+	_, err := e.Start(ctx, &Spec{Path: "scripts-hub-nonexistent"})
+	require.Error(t, err)
+}
+
+func TestLocalProcess_Signal(t *testing.T) {
+	ctx := context.Background()
+	e := NewLocal()
+
+	p, err := e.Start(ctx, &Spec{Path: "sleep", Args: []string{"10"}})
+	require.NoError(t, err)
+
+	require.NoError(t, p.Signal(syscall.SIGTERM))
+	code, err := p.Wait()
+	require.Error(t, err)
+	require.Equal(t, -1, code)
+
+	// The usage is reported for the exited process
+	usage := p.Usage()
+	require.Less(t, usage.UserTime+usage.SystemTime, 5*time.Second)
+	require.True(t, errors.Is(p.Signal(syscall.SIGTERM), os.ErrProcessDone))
+	require.True(t, errors.Is(p.Kill(), os.ErrProcessDone))
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package executor
+
+import "os"
+
+// maxRSS returns zero where the resident set size is not reported in the known units.
+func maxRSS(state *os.ProcessState) int64 {
+	return 0
+}
@@ -29,14 +29,18 @@ type (
 // Log is singleton of events logger.
 var Log *zap.Logger = zap.NewNop()
 
+// atomicLevel is the level of the logger singleton,
+// it is changed at runtime without rebuilding the logger.
+var atomicLevel = zap.NewAtomicLevel()
+
 // Init initializes logger singleton with the appropriate atomic level.
 func Init(ctx context.Context, level string) error {
-	lvl, err := zap.ParseAtomicLevel(level)
+	err := SetLevel(level)
 	if err != nil {
-		return fmt.Errorf("Init: parse level error %w", err)
+		return fmt.Errorf("Init: %w", err)
 	}
 	cfg := zap.NewProductionConfig()
-	cfg.Level = lvl
+	cfg.Level = Level()
 	zl, err := cfg.Build()
 	if err != nil {
 		return fmt.Errorf("Init: logger build error %w", err)
@@ -45,6 +49,21 @@ func Init(ctx context.Context, level string) error {
 	return nil
 }
 
+// Level returns the atomic level of the logger singleton.
+func Level() zap.AtomicLevel {
+	return atomicLevel
+}
+
+// SetLevel changes the level of the logger singleton.
+func SetLevel(l string) error {
+	lvl, err := zap.ParseAtomicLevel(l)
+	if err != nil {
+		return fmt.Errorf("SetLevel: parse level error %w", err)
+	}
+	atomicLevel.SetLevel(lvl.Level())
+	return nil
+}
+
 // WriteHeader implements writing the header and status code capturing.
 func (r *LoggingResponseWriter) WriteHeader(statusCode int) {
 	r.ResponseWriter.WriteHeader(statusCode)
@@ -42,3 +42,41 @@ func TestInit(t *testing.T) {
 		})
 	}
 }
+
+func TestSetLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "debug",
+			level: "debug",
+			want:  "debug",
+		},
+		{
+			name:  "upper_case",
+			level: "WARN",
+			want:  "warn",
+		},
+		{
+			name:    "wrong_level",
+			level:   "Wrong",
+			want:    "warn",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetLevel(tt.level)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetLevel() = %v, want %v", err != nil, tt.wantErr)
+			}
+			if got := Level().String(); got != tt.want {
+				t.Errorf("Level() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Package metrics contains the server, command and runtime metrics
+// and the registry exposing them in Prometheus text format.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "scripts_hub"
+
+var (
+	// HTTPRequests counts the handled HTTP requests by method, route and status code.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of the handled HTTP requests.",
+	}, []string{"method", "route", "code"})
+
+	// HTTPDuration observes the durations of the HTTP requests by method and route.
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of the handled HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// Runs counts the finished command runs by the final status.
+	Runs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "runs_total",
+		Help:      "Number of the finished command runs.",
+	}, []string{"status"})
+
+	// RunDuration observes the durations of the finished command runs by the final status.
+	RunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "run_duration_seconds",
+		Help:      "Duration of the finished command runs from the start of the first attempt.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 15, 30, 60, 300, 900, 3600},
+	}, []string{"status"})
+
+	// OutputBytes counts the bytes of the command output written into the storage.
+	OutputBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "run_output_bytes_total",
+		Help:      "Number of the bytes of the command output written into the storage.",
+	})
+
+	// AppendDuration observes the durations of appending the command output to the storage.
+	AppendDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "run_output_append_duration_seconds",
+		Help:      "Duration of appending the command output to the storage.",
+		Buckets:   []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 1},
+	})
+)
+
+// ObserveRun records the final status and the duration of the finished run.
+func ObserveRun(run *entities.Run) {
+	Runs.WithLabelValues(run.Status).Inc()
+
+	if run.StartedAt == nil || run.FinishedAt == nil {
+		return
+	}
+	RunDuration.WithLabelValues(run.Status).Observe(run.FinishedAt.Sub(*run.StartedAt).Seconds())
+}
+
+// NewRegistry returns new registry of the server, command and Go runtime metrics
+// and the specified collectors.
+func NewRegistry(cs ...prometheus.Collector) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		Runs,
+		RunDuration,
+		OutputBytes,
+		AppendDuration,
+	)
+	reg.MustRegister(cs...)
+
+	return reg
+}
+
+// Handler returns handler exposing the metrics of the registry in Prometheus text format.
+func Handler(reg *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
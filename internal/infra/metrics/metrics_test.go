@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveRun(t *testing.T) {
+	started := time.Date(2024, time.May, 6, 10, 0, 0, 0, time.UTC)
+	finished := started.Add(3 * time.Second)
+
+	tests := []struct {
+		name      string
+		run       *entities.Run
+		wantCount int
+	}{
+		{
+			name:      "finished",
+			run:       &entities.Run{Status: entities.RunFailed, StartedAt: &started, FinishedAt: &finished},
+			wantCount: 1,
+		},
+		{
+			name: "not_started",
+			run:  &entities.Run{Status: entities.RunSkipped, FinishedAt: &finished},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := testutil.ToFloat64(Runs.WithLabelValues(tt.run.Status))
+			durations := runDurations(t, tt.run.Status)
+
+			ObserveRun(tt.run)
+
+			require.Equal(t, runs+1, testutil.ToFloat64(Runs.WithLabelValues(tt.run.Status)))
+			require.Equal(t, durations+uint64(tt.wantCount), runDurations(t, tt.run.Status))
+		})
+	}
+}
+
+// runDurations returns the number of the observed durations of the runs with the status.
+func runDurations(t *testing.T, status string) uint64 {
+	var m dto.Metric
+	err := RunDuration.WithLabelValues(status).(prometheus.Histogram).Write(&m)
+	require.NoError(t, err)
+
+	return m.GetHistogram().GetSampleCount()
+}
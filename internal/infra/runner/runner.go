@@ -0,0 +1,491 @@
+// Package runner contains the remote agent which registers on the scripts hub,
+// runs the received command jobs on its host, sends their output and exit
+// statuses back and passes the requested signals to the commands.
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"github.com/pavlegich/scripts-hub/internal/service/agent"
+	"go.uber.org/zap"
+)
+
+const (
+	// retryInterval is the interval between the failed requests to the hub.
+	retryInterval = time.Second
+	// maxAttempts is the number of attempts of sending the output and the exit status.
+	maxAttempts = 5
+	// requestTimeout is the maximum duration of the request to the hub except the poll.
+	requestTimeout = 10 * time.Second
+)
+
+// Runner contains the state of the remote agent.
+type Runner struct {
+	cfg    *config.AgentConfig
+	client *http.Client
+	hubURL string
+
+	mu      sync.Mutex
+	id      string
+	procs   map[int]*exec.Cmd
+	pending map[int]syscall.Signal
+	orphans map[int]bool
+	wg      sync.WaitGroup
+}
+
+// NewRunner returns new remote agent of the hub.
+func NewRunner(ctx context.Context, cfg *config.AgentConfig) *Runner {
+	return &Runner{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: agent.PollWait + requestTimeout},
+		hubURL:  strings.TrimSuffix(cfg.HubURL, "/"),
+		procs:   make(map[int]*exec.Cmd),
+		pending: make(map[int]syscall.Signal),
+		orphans: make(map[int]bool),
+	}
+}
+
+// Run registers the agent and polls the hub for the jobs and the signals until
+// the context is done. The agent registers again when the hub does not know it,
+// the commands of the previous registration are terminated before, as the hub
+// has already completed their runs.
+// When the context is done, the running commands are terminated and their
+// exit statuses are sent before it returns.
+func (r *Runner) Run(ctx context.Context) error {
+	defer r.stop()
+
+	for ctx.Err() == nil {
+		if r.agentID() == "" {
+			err := r.register(ctx)
+			if err != nil {
+				logger.Log.Error("Run: register agent failed",
+					zap.Error(err), zap.String("hub_url", r.hubURL))
+
+				sleep(ctx, retryInterval)
+				continue
+			}
+		}
+
+		poll, err := r.poll(ctx)
+		if errors.Is(err, errs.ErrAgentNotFound) {
+			logger.Log.Warn("Run: agent is not known by the hub, registering again")
+
+			r.setAgentID("")
+			r.abandonAll()
+			r.waitOrphans(ctx, r.cfg.KillTimeout+retryInterval)
+			continue
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Log.Error("Run: poll hub failed",
+					zap.Error(err))
+
+				sleep(ctx, retryInterval)
+			}
+			continue
+		}
+
+		for _, j := range poll.Jobs {
+			r.accept(j)
+		}
+		for _, s := range poll.Signals {
+			r.signal(s)
+		}
+	}
+
+	return nil
+}
+
+// agentID returns the identifier of the registered agent.
+func (r *Runner) agentID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.id
+}
+
+// setAgentID sets the identifier of the registered agent.
+func (r *Runner) setAgentID(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.id = id
+}
+
+// register registers the agent with its labels and capacity on the hub.
+// The commands which are still running are not counted in the free capacity.
+func (r *Runner) register(ctx context.Context) error {
+	capacity := r.cfg.Capacity - r.running()
+	if capacity < 1 {
+		return fmt.Errorf("register: all %d slots are taken by the running commands", r.cfg.Capacity)
+	}
+
+	body, err := json.Marshal(&entities.Agent{
+		Name:     r.cfg.Name,
+		Labels:   r.cfg.Labels,
+		Capacity: capacity,
+	})
+	if err != nil {
+		return fmt.Errorf("register: marshal agent failed %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	resp, err := r.do(ctx, http.MethodPost, "/agents", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("register: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("register: unexpected status %d", resp.StatusCode)
+	}
+
+	var a entities.Agent
+	err = json.NewDecoder(resp.Body).Decode(&a)
+	if err != nil {
+		return fmt.Errorf("register: decode agent failed %w", err)
+	}
+	r.setAgentID(a.ID)
+
+	logger.Log.Info("register: agent registered",
+		zap.String("agent_id", a.ID), zap.String("hub_url", r.hubURL), zap.Int("capacity", capacity))
+
+	return nil
+}
+
+// poll waits for the jobs and the signals of the agent.
+func (r *Runner) poll(ctx context.Context) (*entities.AgentPoll, error) {
+	resp, err := r.do(ctx, http.MethodGet, "/agents/poll", nil)
+	if err != nil {
+		return nil, fmt.Errorf("poll: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return &entities.AgentPoll{}, nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("poll: %w", errs.ErrAgentNotFound)
+	default:
+		return nil, fmt.Errorf("poll: unexpected status %d", resp.StatusCode)
+	}
+
+	var poll entities.AgentPoll
+	err = json.NewDecoder(resp.Body).Decode(&poll)
+	if err != nil {
+		return nil, fmt.Errorf("poll: decode poll failed %w", err)
+	}
+
+	return &poll, nil
+}
+
+// accept starts running the command of the job. The job is known as soon as
+// it is accepted, so the signals received before the command starts are kept for it.
+func (r *Runner) accept(j *entities.AgentJob) {
+	r.mu.Lock()
+	r.procs[j.RunID] = nil
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+	go r.runJob(j)
+}
+
+// runJob runs the command of the job and sends its exit status to the hub.
+func (r *Runner) runJob(j *entities.AgentJob) {
+	defer r.wg.Done()
+
+	log := logger.Log.With(zap.Int("run_id", j.RunID))
+
+	cmd := exec.Command(j.Path, j.Args...)
+	cmd.Env = append(os.Environ(), j.Env...)
+	if j.Input != nil {
+		cmd.Stdin = bytes.NewReader(j.Input)
+	}
+	out := &outputWriter{runner: r, runID: j.RunID}
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	r.mu.Lock()
+	err := cmd.Start()
+	if err == nil {
+		r.procs[j.RunID] = cmd
+		if sig, ok := r.pending[j.RunID]; ok {
+			cmd.Process.Signal(sig)
+		}
+	} else {
+		delete(r.procs, j.RunID)
+	}
+	delete(r.pending, j.RunID)
+	r.mu.Unlock()
+
+	if err != nil {
+		log.Error("runJob: start command failed",
+			zap.Error(err), zap.String("path", j.Path))
+
+		if !r.forget(j.RunID) {
+			r.exit(&entities.AgentExit{RunID: j.RunID, ExitCode: -1, Error: err.Error()})
+		}
+		return
+	}
+	log.Info("runJob: command started",
+		zap.String("path", j.Path), zap.Int("pid", cmd.Process.Pid))
+
+	err = cmd.Wait()
+
+	r.mu.Lock()
+	delete(r.procs, j.RunID)
+	r.mu.Unlock()
+
+	exitCode := cmd.ProcessState.ExitCode()
+	log.Info("runJob: command finished",
+		zap.Int("exit_code", exitCode), zap.NamedError("reason", err))
+
+	if r.forget(j.RunID) {
+		return
+	}
+	r.exit(&entities.AgentExit{RunID: j.RunID, ExitCode: exitCode})
+}
+
+// running returns the number of the accepted commands which have not exited.
+func (r *Runner) running() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.procs)
+}
+
+// abandon terminates the command of the run which is not known by the hub,
+// its exit status is not sent. The command which does not exit after SIGTERM
+// within the kill timeout is killed, the command which has not started yet
+// is killed right after the start.
+func (r *Runner) abandon(runID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cmd, ok := r.procs[runID]
+	if !ok || r.orphans[runID] {
+		return
+	}
+	r.orphans[runID] = true
+
+	logger.Log.Warn("abandon: run is not known by the hub, terminating command",
+		zap.Int("run_id", runID))
+
+	if cmd == nil {
+		r.pending[runID] = syscall.SIGKILL
+		return
+	}
+
+	cmd.Process.Signal(syscall.SIGTERM)
+	time.AfterFunc(r.cfg.KillTimeout, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if r.procs[runID] == cmd {
+			cmd.Process.Kill()
+		}
+	})
+}
+
+// abandonAll terminates the commands of all the accepted runs.
+func (r *Runner) abandonAll() {
+	r.mu.Lock()
+	ids := make([]int, 0, len(r.procs))
+	for id := range r.procs {
+		ids = append(ids, id)
+	}
+	r.mu.Unlock()
+
+	for _, id := range ids {
+		r.abandon(id)
+	}
+}
+
+// waitOrphans waits for the abandoned commands to exit for the timeout
+// or until the context is done.
+func (r *Runner) waitOrphans(ctx context.Context, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		r.mu.Lock()
+		left := len(r.orphans)
+		r.mu.Unlock()
+		if left == 0 {
+			return
+		}
+
+		sleep(ctx, 10*time.Millisecond)
+		if ctx.Err() != nil {
+			logger.Log.Warn("waitOrphans: abandoned commands are still running",
+				zap.Int("running", left))
+			return
+		}
+	}
+}
+
+// forget removes the run from the abandoned ones and reports
+// whether it has been abandoned.
+func (r *Runner) forget(runID int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	orphan := r.orphans[runID]
+	delete(r.orphans, runID)
+	return orphan
+}
+
+// signal sends the signal to the command of the run.
+func (r *Runner) signal(s *entities.AgentSignal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cmd, ok := r.procs[s.RunID]
+	if !ok {
+		return
+	}
+	if cmd == nil {
+		r.pending[s.RunID] = syscall.Signal(s.Signal)
+		return
+	}
+
+	err := cmd.Process.Signal(syscall.Signal(s.Signal))
+	if err != nil && !errors.Is(err, os.ErrProcessDone) {
+		logger.Log.Error("signal: send signal failed",
+			zap.Error(err), zap.Int("run_id", s.RunID), zap.Int("signal", s.Signal))
+	}
+}
+
+// stop terminates the running commands and waits until their exit statuses are sent.
+func (r *Runner) stop() {
+	r.mu.Lock()
+	for id, cmd := range r.procs {
+		if cmd == nil {
+			r.pending[id] = syscall.SIGTERM
+			continue
+		}
+		cmd.Process.Signal(syscall.SIGTERM)
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+}
+
+// exit sends the exit status of the command to the hub.
+func (r *Runner) exit(exit *entities.AgentExit) {
+	body, err := json.Marshal(exit)
+	if err != nil {
+		logger.Log.Error("exit: marshal exit status failed",
+			zap.Error(err), zap.Int("run_id", exit.RunID))
+		return
+	}
+
+	err = r.send("/agents/exit", body)
+	if err != nil {
+		logger.Log.Error("exit: send exit status failed",
+			zap.Error(err), zap.Int("run_id", exit.RunID))
+	}
+}
+
+// send posts the body to the hub, the failed request is retried.
+// The run unknown by the hub is not retried.
+func (r *Runner) send(path string, body []byte) error {
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(retryInterval)
+		}
+
+		err = r.post(path, body)
+		if err == nil || errors.Is(err, errs.ErrRunNotFound) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("send: %d attempts failed %w", maxAttempts, err)
+}
+
+// post posts the body to the hub.
+func (r *Runner) post(path string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	resp, err := r.do(ctx, http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("post: %w", errs.ErrRunNotFound)
+	default:
+		return fmt.Errorf("post: unexpected status %d", resp.StatusCode)
+	}
+}
+
+// do sends the request of the agent to the hub.
+func (r *Runner) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, r.hubURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("do: create request failed %w", err)
+	}
+	req.Header.Set(agent.TokenHeader, r.cfg.Token)
+	req.Header.Set(agent.IDHeader, r.agentID())
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do: send request failed %w", err)
+	}
+
+	return resp, nil
+}
+
+// outputWriter sends the output of the command to the hub.
+type outputWriter struct {
+	runner *Runner
+	runID  int
+}
+
+// Write implements sending the output chunk of the command to the hub.
+func (w *outputWriter) Write(d []byte) (int, error) {
+	err := w.runner.send("/agents/output?id="+strconv.Itoa(w.runID), d)
+	if errors.Is(err, errs.ErrRunNotFound) {
+		w.runner.abandon(w.runID)
+	}
+	if err != nil {
+		return -1, fmt.Errorf("Write: %w", err)
+	}
+	return len(d), nil
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
@@ -0,0 +1,154 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/service/agent"
+	"github.com/stretchr/testify/require"
+)
+
+// expiringHub is the hub which expires the first registration of the agent
+// after the output of its command is received.
+type expiringHub struct {
+	mu         sync.Mutex
+	capacities []int
+	exits      []entities.AgentExit
+	started    chan struct{}
+	startOnce  sync.Once
+	polled     bool
+}
+
+func (h *expiringHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(agent.IDHeader)
+
+	switch r.URL.Path {
+	case "/agents":
+		var a entities.Agent
+		json.NewDecoder(r.Body).Decode(&a)
+
+		h.mu.Lock()
+		h.capacities = append(h.capacities, a.Capacity)
+		a.ID = "a" + strconv.Itoa(len(h.capacities))
+		h.mu.Unlock()
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(&a)
+	case "/agents/poll":
+		if id != "a1" {
+			time.Sleep(10 * time.Millisecond)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		h.mu.Lock()
+		polled := h.polled
+		h.polled = true
+		h.mu.Unlock()
+		if !polled {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&entities.AgentPoll{Jobs: []*entities.AgentJob{{
+				RunID: 1,
+				Path:  "/bin/sh",
+				Args:  []string{"-c", "trap '' TERM; echo started; exec sleep 10"},
+			}}})
+			return
+		}
+
+		// The agent is expired while its command runs
+		<-h.started
+		w.WriteHeader(http.StatusNotFound)
+	case "/agents/output":
+		io.Copy(io.Discard, r.Body)
+		if id != "a1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		h.startOnce.Do(func() { close(h.started) })
+	case "/agents/exit":
+		var exit entities.AgentExit
+		json.NewDecoder(r.Body).Decode(&exit)
+
+		h.mu.Lock()
+		h.exits = append(h.exits, exit)
+		h.mu.Unlock()
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestRunner_RunExpired(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := &expiringHub{started: make(chan struct{})}
+	srv := httptest.NewServer(hub)
+	defer srv.Close()
+
+	r := NewRunner(ctx, &config.AgentConfig{
+		HubURL:      srv.URL,
+		Name:        "build-1",
+		Capacity:    2,
+		KillTimeout: 100 * time.Millisecond,
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Run(ctx)
+	}()
+
+	// The agent registers again after the command of the expired
+	// registration ignoring SIGTERM is killed
+	require.Eventually(t, func() bool {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		return len(hub.capacities) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 0, r.running())
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent is not stopped")
+	}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	require.Equal(t, []int{2, 2}, hub.capacities)
+	require.Empty(t, hub.exits)
+}
+
+func TestRunner_RegisterCapacity(t *testing.T) {
+	ctx := context.Background()
+
+	hub := &expiringHub{started: make(chan struct{})}
+	srv := httptest.NewServer(hub)
+	defer srv.Close()
+
+	r := NewRunner(ctx, &config.AgentConfig{
+		HubURL:   srv.URL,
+		Name:     "build-1",
+		Capacity: 2,
+	})
+
+	// The command still running takes its slot
+	r.procs[1] = nil
+	require.NoError(t, r.register(ctx))
+
+	r.procs[2] = nil
+	require.Error(t, r.register(ctx))
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	require.Equal(t, []int{1}, hub.capacities)
+}
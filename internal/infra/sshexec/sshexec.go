@@ -0,0 +1,260 @@
+// Package sshexec runs the commands on the SSH hosts defined in the server
+// config, the hosts are authenticated by the known hosts file and the server
+// is authenticated on them by the private key.
+package sshexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/executor"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// dialTimeout is the maximum time for connecting to the host and the SSH handshake.
+const dialTimeout = 10 * time.Second
+
+// signals contains the names of the signals sent to the commands.
+var signals = map[syscall.Signal]ssh.Signal{
+	syscall.SIGHUP:  ssh.SIGHUP,
+	syscall.SIGINT:  ssh.SIGINT,
+	syscall.SIGKILL: ssh.SIGKILL,
+	syscall.SIGQUIT: ssh.SIGQUIT,
+	syscall.SIGTERM: ssh.SIGTERM,
+	syscall.SIGUSR1: ssh.SIGUSR1,
+	syscall.SIGUSR2: ssh.SIGUSR2,
+}
+
+// Executor contains the SSH hosts and the client settings.
+type Executor struct {
+	hosts   map[string]host
+	auth    []ssh.AuthMethod
+	hostKey ssh.HostKeyCallback
+}
+
+// host contains the address of the SSH host and the user logged in it.
+type host struct {
+	user string
+	addr string
+}
+
+// hostExecutor runs the commands on one of the hosts.
+type hostExecutor struct {
+	executor *Executor
+	name     string
+}
+
+// Process contains the session of the command run on the SSH host.
+type Process struct {
+	client  *ssh.Client
+	session *ssh.Session
+	once    sync.Once
+}
+
+// NewExecutor returns new executor of the commands on the configured hosts.
+// The private key and the known hosts files are required when there are hosts.
+func NewExecutor(ctx context.Context, cfg *config.Config) (*Executor, error) {
+	e := &Executor{
+		hosts: make(map[string]host),
+	}
+	if len(cfg.SSHHosts) == 0 {
+		return e, nil
+	}
+
+	if cfg.SSHKey == "" || cfg.SSHKnownHosts == "" {
+		return nil, fmt.Errorf("NewExecutor: private key and known hosts files are required for ssh hosts")
+	}
+
+	key, err := os.ReadFile(cfg.SSHKey)
+	if err != nil {
+		return nil, fmt.Errorf("NewExecutor: read private key failed %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("NewExecutor: parse private key failed %w", err)
+	}
+	e.auth = []ssh.AuthMethod{ssh.PublicKeys(signer)}
+
+	e.hostKey, err = knownhosts.New(cfg.SSHKnownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("NewExecutor: read known hosts failed %w", err)
+	}
+
+	for name, addr := range cfg.SSHHosts {
+		user, hostAddr, _ := strings.Cut(addr, "@")
+		e.hosts[name] = host{user: user, addr: hostAddr}
+	}
+
+	return e, nil
+}
+
+// Has returns true if the host is configured.
+func (e *Executor) Has(name string) bool {
+	_, ok := e.hosts[name]
+	return ok
+}
+
+// Host returns the executor of the commands on the host.
+func (e *Executor) Host(name string) executor.Executor {
+	return &hostExecutor{executor: e, name: name}
+}
+
+// Start connects to the host and starts the command. The environment variables
+// of the spec are set by the env utility, as the hosts usually do not accept them.
+func (e *Executor) Start(ctx context.Context, name string, spec *executor.Spec) (*Process, error) {
+	h, ok := e.hosts[name]
+	if !ok {
+		return nil, fmt.Errorf("Start: host %s %w", name, errs.ErrHostNotFound)
+	}
+
+	client, err := e.dial(ctx, h)
+	if err != nil {
+		return nil, fmt.Errorf("Start: %w", err)
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("Start: open session failed %w", err)
+	}
+	session.Stdin = spec.Stdin
+	session.Stdout = spec.Stdout
+	session.Stderr = spec.Stderr
+
+	err = session.Start(commandLine(spec))
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("Start: start command failed %w", err)
+	}
+
+	return &Process{client: client, session: session}, nil
+}
+
+// dial connects to the host and makes the SSH handshake.
+func (e *Executor) dial(ctx context.Context, h host) (*ssh.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", h.addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial: connect to host failed %w", err)
+	}
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, h.addr, &ssh.ClientConfig{
+		User:            h.user,
+		Auth:            e.auth,
+		HostKeyCallback: e.hostKey,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("dial: ssh handshake failed %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// LookPath accepts any command, it is looked up by the shell of the host.
+func (e *hostExecutor) LookPath(path string) error {
+	return nil
+}
+
+// Start connects to the host and starts the command.
+func (e *hostExecutor) Start(ctx context.Context, spec *executor.Spec) (executor.Process, error) {
+	return e.executor.Start(ctx, e.name, spec)
+}
+
+// Wait waits for the command to exit and closes the connection. It returns
+// the exit code of the command and the error if the command failed.
+// The exit code is -1 if the host has not reported it.
+func (p *Process) Wait() (int, error) {
+	err := p.session.Wait()
+	p.close()
+
+	var exitErr *ssh.ExitError
+	switch {
+	case err == nil:
+		return 0, nil
+	case errors.As(err, &exitErr):
+		return exitErr.ExitStatus(), fmt.Errorf("Wait: %w", err)
+	default:
+		return -1, fmt.Errorf("Wait: %w", err)
+	}
+}
+
+// Signal sends the signal to the command,
+// os.ErrProcessDone is returned if the session is closed.
+func (p *Process) Signal(sig syscall.Signal) error {
+	name, ok := signals[sig]
+	if !ok {
+		return fmt.Errorf("Signal: signal %d is not supported", int(sig))
+	}
+
+	err := p.session.Signal(name)
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("Signal: %w", os.ErrProcessDone)
+	}
+	if err != nil {
+		return fmt.Errorf("Signal: %w", err)
+	}
+	return nil
+}
+
+// Kill sends SIGKILL to the command and closes the connection, so the command
+// is stopped by the closed pipes even if the host does not accept the signals.
+func (p *Process) Kill() error {
+	p.session.Signal(ssh.SIGKILL)
+	p.close()
+	return nil
+}
+
+// Usage returns the zero usage, as it is not reported by the hosts.
+func (p *Process) Usage() executor.Usage {
+	return executor.Usage{}
+}
+
+// close closes the connection to the host once.
+func (p *Process) close() {
+	p.once.Do(func() {
+		p.client.Close()
+	})
+}
+
+// commandLine returns the quoted command line of the spec executed by
+// the shell of the host. The shell is replaced by the command,
+// so the signals of the session are received by the command.
+func commandLine(spec *executor.Spec) string {
+	words := make([]string, 0, len(spec.Env)+len(spec.Args)+3)
+	words = append(words, "exec")
+	if len(spec.Env) > 0 {
+		words = append(words, "env")
+		for _, v := range spec.Env {
+			words = append(words, quote(v))
+		}
+	}
+	words = append(words, quote(spec.Path))
+	for _, arg := range spec.Args {
+		words = append(words, quote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// quote quotes the word for the POSIX shell.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
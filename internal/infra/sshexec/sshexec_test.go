@@ -0,0 +1,191 @@
+package sshexec
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/pavlegich/scripts-hub/internal/infra/executor"
+	"github.com/pavlegich/scripts-hub/internal/infra/sshexec/sshtest"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is the buffer written by the session goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(d []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(d)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// newTestExecutor starts the test server and returns the executor
+// with the server configured as the host "test".
+func newTestExecutor(t *testing.T, knownHosts string) *Executor {
+	ctx := context.Background()
+
+	key, pub, err := sshtest.ClientKey()
+	require.NoError(t, err)
+	srv, err := sshtest.NewServer(pub)
+	require.NoError(t, err)
+	t.Cleanup(srv.Close)
+
+	if knownHosts == "" {
+		knownHosts = srv.KnownHosts()
+	}
+
+	dir := t.TempDir()
+	cfg := &config.Config{
+		SSHHosts:      config.HostList{"test": "deploy@" + srv.Addr},
+		SSHKey:        filepath.Join(dir, "id_ed25519"),
+		SSHKnownHosts: filepath.Join(dir, "known_hosts"),
+	}
+	require.NoError(t, os.WriteFile(cfg.SSHKey, key, 0o600))
+	require.NoError(t, os.WriteFile(cfg.SSHKnownHosts, []byte(knownHosts), 0o600))
+
+	e, err := NewExecutor(ctx, cfg)
+	require.NoError(t, err)
+	require.True(t, e.Has("test"))
+
+	return e
+}
+
+func TestExecutor_Start(t *testing.T) {
+	ctx := context.Background()
+	e := newTestExecutor(t, "")
+
+	tests := []struct {
+		name       string
+		spec       executor.Spec
+		wantCode   int
+		wantOutput string
+	}{
+		{
+			name:       "success",
+			spec:       executor.Spec{Path: "echo", Args: []string{"it's", "$HOME"}},
+			wantOutput: "it's $HOME\n",
+		},
+		{
+			name:       "env_and_input",
+			spec:       executor.Spec{Path: "sh", Args: []string{"-c", "echo $GREETING; cat"}, Env: []string{"GREETING=hello"}, Stdin: strings.NewReader("world\n")},
+			wantOutput: "hello\nworld\n",
+		},
+		{
+			name:       "failed",
+			spec:       executor.Spec{Path: "sh", Args: []string{"-c", "echo oops >&2; exit 3"}},
+			wantCode:   3,
+			wantOutput: "oops\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out syncBuffer
+			tt.spec.Stdout = &out
+			tt.spec.Stderr = &out
+
+			p, err := e.Host("test").Start(ctx, &tt.spec)
+			require.NoError(t, err)
+
+			code, err := p.Wait()
+			require.Equal(t, tt.wantCode, code)
+			if tt.wantCode != 0 {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantOutput, out.String())
+		})
+	}
+}
+
+func TestExecutor_StartErrors(t *testing.T) {
+	ctx := context.Background()
+
+	// The host missing in the config
+	e := newTestExecutor(t, "")
+	_, err := e.Start(ctx, "unknown", &executor.Spec{Path: "true"})
+	require.ErrorIs(t, err, errs.ErrHostNotFound)
+
+	// The host key missing in the known hosts
+	_, other, err := sshtest.ClientKey()
+	require.NoError(t, err)
+	srv, err := sshtest.NewServer(other)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	e = newTestExecutor(t, srv.KnownHosts())
+	_, err = e.Start(ctx, "test", &executor.Spec{Path: "true", Stdout: &syncBuffer{}})
+	require.Error(t, err)
+
+	// The hosts without the private key
+	_, err = NewExecutor(ctx, &config.Config{SSHHosts: config.HostList{"test": "deploy@localhost:22"}})
+	require.Error(t, err)
+}
+
+func TestProcess_Signal(t *testing.T) {
+	ctx := context.Background()
+	e := newTestExecutor(t, "")
+
+	tests := []struct {
+		name     string
+		sig      syscall.Signal
+		wantCode int
+	}{
+		{
+			name:     "terminated",
+			sig:      syscall.SIGTERM,
+			wantCode: 128 + 15,
+		},
+		{
+			name:     "killed",
+			sig:      syscall.SIGKILL,
+			wantCode: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out syncBuffer
+			p, err := e.Start(ctx, "test", &executor.Spec{Path: "sh", Args: []string{"-c", "echo started; exec sleep 10"}, Stdout: &out})
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				return out.String() == "started\n"
+			}, 5*time.Second, 10*time.Millisecond)
+			if tt.sig == syscall.SIGKILL {
+				require.NoError(t, p.Kill())
+			} else {
+				require.NoError(t, p.Signal(tt.sig))
+			}
+
+			done := make(chan int, 1)
+			go func() {
+				code, _ := p.Wait()
+				done <- code
+			}()
+
+			select {
+			case code := <-done:
+				require.Equal(t, tt.wantCode, code)
+			case <-time.After(5 * time.Second):
+				t.Fatal("command is not stopped")
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+// Package sshtest contains the in-process SSH server for testing
+// the commands run on the SSH hosts.
+package sshtest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// signals contains the signals accepted by the server.
+var signals = map[ssh.Signal]syscall.Signal{
+	ssh.SIGHUP:  syscall.SIGHUP,
+	ssh.SIGINT:  syscall.SIGINT,
+	ssh.SIGKILL: syscall.SIGKILL,
+	ssh.SIGTERM: syscall.SIGTERM,
+}
+
+// Server is the SSH server running the commands of the sessions on the local host
+// by "sh -c". The client is authenticated by its public key. The commands
+// of the closed connection are killed.
+type Server struct {
+	Addr string
+
+	config   *ssh.ServerConfig
+	hostKey  ssh.PublicKey
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// NewServer starts new server on the loopback interface
+// accepting the client with the key.
+func NewServer(clientKey ssh.PublicKey) (*Server, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("NewServer: generate host key failed %w", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("NewServer: create host key signer failed %w", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(clientKey.Marshal()) {
+				return nil, errors.New("unknown public key")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("NewServer: listen failed %w", err)
+	}
+
+	s := &Server{
+		Addr:     l.Addr().String(),
+		config:   config,
+		hostKey:  signer.PublicKey(),
+		listener: l,
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// KnownHosts returns the line of the known hosts file for the server.
+func (s *Server) KnownHosts() string {
+	return knownhosts.Line([]string{s.Addr}, s.hostKey) + "\n"
+}
+
+// Close stops accepting the connections and waits for the accepted ones to close.
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+// serve accepts the connections until the server is closed.
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.wg.Add(1)
+		go s.handleConn(conn)
+	}
+}
+
+// handleConn serves the sessions of the connection.
+func (s *Server) handleConn(conn net.Conn) {
+	defer s.wg.Done()
+
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	var mu sync.Mutex
+	procs := make([]*os.Process, 0)
+	go func() {
+		sconn.Wait()
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, p := range procs {
+			p.Kill()
+		}
+	}()
+
+	var sessions sync.WaitGroup
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			nc.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+
+		ch, chReqs, err := nc.Accept()
+		if err != nil {
+			continue
+		}
+
+		sessions.Add(1)
+		go func() {
+			defer sessions.Done()
+
+			p := handleSession(ch, chReqs)
+			if p == nil {
+				return
+			}
+
+			mu.Lock()
+			procs = append(procs, p)
+			mu.Unlock()
+		}()
+	}
+	sessions.Wait()
+}
+
+// handleSession runs the command of the exec request and passes the signals
+// to it. It returns the started process, the exit status is sent to the client
+// and the channel is closed when the process exits.
+func handleSession(ch ssh.Channel, reqs <-chan *ssh.Request) *os.Process {
+	started := make(chan *os.Process, 1)
+
+	go func() {
+		var proc *os.Process
+		execed := false
+		defer func() {
+			if !execed {
+				close(started)
+			}
+		}()
+
+		for req := range reqs {
+			switch req.Type {
+			case "exec":
+				var payload struct{ Command string }
+				if execed || ssh.Unmarshal(req.Payload, &payload) != nil {
+					req.Reply(false, nil)
+					continue
+				}
+				execed = true
+
+				cmd := exec.Command("sh", "-c", payload.Command)
+				cmd.Stdin = ch
+				cmd.Stdout = ch
+				cmd.Stderr = ch.Stderr()
+				if err := cmd.Start(); err != nil {
+					req.Reply(false, nil)
+					ch.Close()
+					close(started)
+					continue
+				}
+				req.Reply(true, nil)
+				proc = cmd.Process
+				started <- proc
+
+				go wait(ch, cmd)
+			case "signal":
+				var payload struct{ Signal string }
+				ssh.Unmarshal(req.Payload, &payload)
+				sig, ok := signals[ssh.Signal(payload.Signal)]
+				if ok && proc != nil {
+					proc.Signal(sig)
+				}
+				if req.WantReply {
+					req.Reply(ok, nil)
+				}
+			default:
+				if req.WantReply {
+					req.Reply(false, nil)
+				}
+			}
+		}
+	}()
+
+	return <-started
+}
+
+// wait sends the exit status or the exit signal of the command
+// to the client and closes the channel.
+func wait(ch ssh.Channel, cmd *exec.Cmd) {
+	defer ch.Close()
+
+	cmd.Wait()
+
+	status := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	if status.Signaled() {
+		for name, sig := range signals {
+			if sig == status.Signal() {
+				ch.SendRequest("exit-signal", false, ssh.Marshal(&struct {
+					Signal     string
+					CoreDumped bool
+					Error      string
+					Lang       string
+				}{Signal: string(name)}))
+				return
+			}
+		}
+	}
+
+	ch.SendRequest("exit-status", false, ssh.Marshal(&struct{ Status uint32 }{uint32(status.ExitStatus())}))
+}
+
+// ClientKey returns new private key of the client in the PEM form and its public key.
+func ClientKey() ([]byte, ssh.PublicKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ClientKey: generate key failed %w", err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("ClientKey: marshal key failed %w", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ClientKey: create signer failed %w", err)
+	}
+
+	return pem.EncodeToMemory(block), signer.PublicKey(), nil
+}
@@ -0,0 +1,323 @@
+package supervisor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// BinaryName is the name of the supervisor binary looked up
+	// next to the server binary when its path is not configured.
+	BinaryName = "scripts-hub-shim"
+
+	// startTimeout is the maximum time for the supervisor to start the command.
+	startTimeout = 5 * time.Second
+	// controlTimeout is the maximum duration of the control socket request.
+	controlTimeout = 2 * time.Second
+)
+
+var (
+	// ErrNotStarted is returned when the supervisor has not started the command.
+	ErrNotStarted = errors.New("supervisor has not started the command")
+	// ErrSupervisorLost is returned when the supervisor has exited
+	// without storing the exit status of the command.
+	ErrSupervisorLost = errors.New("supervisor exited without the exit status")
+)
+
+// Handle contains the spool directory of the supervised run.
+type Handle struct {
+	dir string
+}
+
+// DefaultPath returns the path of the supervisor binary next to the server binary.
+func DefaultPath() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return BinaryName
+	}
+	return filepath.Join(filepath.Dir(exe), BinaryName)
+}
+
+// Start writes the spec into the spool directory and starts the supervisor
+// of the command in its own session, so it is not stopped with the server.
+// The previous content of the spool directory is removed. It waits until
+// the supervisor starts the command.
+func Start(path, dir string, spec *Spec) (*Handle, error) {
+	err := os.RemoveAll(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Start: clean spool directory failed %w", err)
+	}
+
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("Start: create spool directory failed %w", err)
+	}
+
+	err = writeJSON(filepath.Join(dir, specFile), spec)
+	if err != nil {
+		return nil, fmt.Errorf("Start: %w", err)
+	}
+
+	cmd := exec.Command(path, dir)
+	cmd.SysProcAttr = detachedAttr()
+	err = cmd.Start()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("Start: start supervisor failed %w", err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+
+	h := &Handle{dir: dir}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.NewTimer(startTimeout)
+	defer timeout.Stop()
+
+	for {
+		if h.started() {
+			return h, nil
+		}
+
+		exit, err := h.exit()
+		if err != nil {
+			return nil, fmt.Errorf("Start: %w", err)
+		}
+		if exit != nil {
+			h.Remove()
+			return nil, fmt.Errorf("Start: %s %w", exit.Error, ErrNotStarted)
+		}
+
+		select {
+		case <-exited:
+			if h.started() {
+				return h, nil
+			}
+			if exit, _ := h.exit(); exit != nil {
+				h.Remove()
+				return nil, fmt.Errorf("Start: %s %w", exit.Error, ErrNotStarted)
+			}
+			h.Remove()
+			return nil, fmt.Errorf("Start: supervisor exited %w", ErrNotStarted)
+		case <-timeout.C:
+			cmd.Process.Kill()
+			return nil, fmt.Errorf("Start: timed out %w", ErrNotStarted)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Attach returns the handle of the run supervised in the spool directory,
+// the supervisor may be started by the previous server. The handle of the run
+// which supervisor is lost is returned too, so its output is drained by Follow.
+func Attach(dir string) (*Handle, error) {
+	h := &Handle{dir: dir}
+	if h.started() {
+		return h, nil
+	}
+
+	exit, err := h.exit()
+	if err != nil {
+		return nil, fmt.Errorf("Attach: %w", err)
+	}
+	if exit == nil || exit.Error != "" {
+		return nil, fmt.Errorf("Attach: %w", ErrNotStarted)
+	}
+
+	return h, nil
+}
+
+// Signal requests the supervisor to send the signal to the command.
+// It returns os.ErrProcessDone if the command has already exited.
+func (h *Handle) Signal(sig syscall.Signal) error {
+	conn, err := net.DialTimeout("unix", filepath.Join(h.dir, controlFile), controlTimeout)
+	if err != nil {
+		if exit, _ := h.exit(); exit != nil {
+			return fmt.Errorf("Signal: %w", os.ErrProcessDone)
+		}
+		return fmt.Errorf("Signal: connect supervisor failed %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(controlTimeout))
+
+	_, err = fmt.Fprintf(conn, "%d\n", int(sig))
+	if err != nil {
+		return fmt.Errorf("Signal: send request failed %w", err)
+	}
+
+	resp, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("Signal: read response failed %w", err)
+	}
+	resp = strings.TrimSpace(resp)
+	if resp != "ok" {
+		if resp == os.ErrProcessDone.Error() {
+			return fmt.Errorf("Signal: %w", os.ErrProcessDone)
+		}
+		return fmt.Errorf("Signal: %s", resp)
+	}
+
+	return nil
+}
+
+// Follow writes the output of the command from the offset into the writer
+// every interval until the command exits and returns its exit status.
+// The output which the writer failed to write is written again on the next interval.
+// If the supervisor is lost, the rest of the output is written, the unsupervised
+// command is killed and ErrSupervisorLost is returned.
+func (h *Handle) Follow(ctx context.Context, offset int64, w io.Writer, interval time.Duration) (*Exit, error) {
+	f, err := os.Open(filepath.Join(h.dir, outputFile))
+	if err != nil {
+		return nil, fmt.Errorf("Follow: open output file failed %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("Follow: seek output file failed %w", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	buf := make([]byte, 32*1024)
+	for {
+		// The exit status is read before the output, so the output written
+		// before the exit is copied in full.
+		exit, err := h.exit()
+		if err != nil {
+			return nil, fmt.Errorf("Follow: %w", err)
+		}
+
+		lost := exit == nil && h.Lost()
+
+		copied, err := copyOutput(f, &offset, w, buf)
+		if err != nil {
+			return nil, fmt.Errorf("Follow: %w", err)
+		}
+		if exit != nil && copied {
+			return exit, nil
+		}
+		if lost && copied {
+			// The exit status may be stored right before the supervisor exits.
+			exit, err = h.exit()
+			if err != nil {
+				return nil, fmt.Errorf("Follow: %w", err)
+			}
+			if exit != nil {
+				continue
+			}
+
+			h.killCommand()
+			return nil, fmt.Errorf("Follow: %w", ErrSupervisorLost)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("Follow: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Lost reports whether the supervisor which started the command
+// has exited without storing the exit status.
+func (h *Handle) Lost() bool {
+	st, err := h.state()
+	if err != nil {
+		return false
+	}
+
+	if alive(st.Supervisor) {
+		return false
+	}
+	exit, err := h.exit()
+	return err == nil && exit == nil
+}
+
+// Remove removes the spool directory of the finished run.
+func (h *Handle) Remove() error {
+	err := os.RemoveAll(h.dir)
+	if err != nil {
+		return fmt.Errorf("Remove: %w", err)
+	}
+	return nil
+}
+
+// started reports whether the supervisor has started the command.
+func (h *Handle) started() bool {
+	_, err := os.Stat(filepath.Join(h.dir, stateFile))
+	return err == nil
+}
+
+// state returns the processes of the started command and its supervisor.
+func (h *Handle) state() (*State, error) {
+	var st State
+	err := readJSON(filepath.Join(h.dir, stateFile), &st)
+	if err != nil {
+		return nil, fmt.Errorf("state: %w", err)
+	}
+	return &st, nil
+}
+
+// killCommand kills the command left running by the lost supervisor.
+func (h *Handle) killCommand() {
+	st, err := h.state()
+	if err != nil || !alive(st.PID) {
+		return
+	}
+	kill(st.PID)
+}
+
+// exit returns the exit status of the command or nil if it is still running.
+func (h *Handle) exit() (*Exit, error) {
+	var exit Exit
+	err := readJSON(filepath.Join(h.dir, exitFile), &exit)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("exit: %w", err)
+	}
+	return &exit, nil
+}
+
+// copyOutput writes the output appended to the file since the offset into the writer.
+// It returns false if the writer failed, the file is rewound to the offset then.
+func copyOutput(f *os.File, offset *int64, w io.Writer, buf []byte) (bool, error) {
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			_, werr := w.Write(buf[:n])
+			if werr != nil {
+				_, err = f.Seek(*offset, io.SeekStart)
+				if err != nil {
+					return false, fmt.Errorf("copyOutput: seek output file failed %w", err)
+				}
+				return false, nil
+			}
+			*offset += int64(n)
+		}
+		if errors.Is(err, io.EOF) {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("copyOutput: read output file failed %w", err)
+		}
+	}
+}
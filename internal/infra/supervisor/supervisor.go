@@ -0,0 +1,207 @@
+// Package supervisor contains the supervisor of the detached command run
+// and the methods for starting, following and controlling it from the server.
+// The supervisor keeps the state of the run in its spool directory, so the
+// server restarted while the command runs attaches to it again.
+package supervisor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Files of the spool directory of the run.
+const (
+	specFile    = "spec.json"
+	stateFile   = "state.json"
+	exitFile    = "exit.json"
+	outputFile  = "output"
+	controlFile = "control.sock"
+)
+
+// Spec contains the command started by the supervisor.
+type Spec struct {
+	RunID int      `json:"run_id"`
+	Path  string   `json:"path"`
+	Args  []string `json:"args,omitempty"`
+	Env   []string `json:"env,omitempty"`
+	Input []byte   `json:"input,omitempty"`
+}
+
+// State contains the processes of the started command and its supervisor.
+type State struct {
+	PID        int       `json:"pid"`
+	Supervisor int       `json:"supervisor_pid"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// Exit contains the exit status of the command, the error is set
+// when the command could not be started.
+type Exit struct {
+	ExitCode   int       `json:"exit_code"`
+	Error      string    `json:"error,omitempty"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Run supervises the command of the spool directory: it starts the command
+// with its output appended to the spool file, serves the signal requests on
+// the control socket and stores the exit status of the command.
+func Run(dir string) error {
+	var spec Spec
+	err := readJSON(filepath.Join(dir, specFile), &spec)
+	if err != nil {
+		return fmt.Errorf("Run: %w", err)
+	}
+
+	out, err := os.OpenFile(filepath.Join(dir, outputFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("Run: open output file failed %w", err)
+	}
+	defer out.Close()
+
+	sock := filepath.Join(dir, controlFile)
+	os.Remove(sock)
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		return fmt.Errorf("Run: listen control socket failed %w", err)
+	}
+	defer os.Remove(sock)
+	defer ln.Close()
+
+	cmd := exec.Command(spec.Path, spec.Args...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.Env = spec.Env
+	if spec.Input != nil {
+		cmd.Stdin = bytes.NewReader(spec.Input)
+	}
+
+	// The signals sent to the supervisor are passed to the command,
+	// so the supervisor itself always stores the exit status.
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+
+	err = cmd.Start()
+	if err != nil {
+		werr := writeJSON(filepath.Join(dir, exitFile), &Exit{
+			ExitCode:   -1,
+			Error:      err.Error(),
+			FinishedAt: time.Now(),
+		})
+		if werr != nil {
+			return fmt.Errorf("Run: %w", werr)
+		}
+		return fmt.Errorf("Run: start command failed %w", err)
+	}
+
+	err = writeJSON(filepath.Join(dir, stateFile), &State{
+		PID:        cmd.Process.Pid,
+		Supervisor: os.Getpid(),
+		StartedAt:  time.Now(),
+	})
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return fmt.Errorf("Run: %w", err)
+	}
+
+	go serve(ln, cmd.Process)
+	go func() {
+		for sig := range sigs {
+			if sig != syscall.SIGHUP {
+				cmd.Process.Signal(sig)
+			}
+		}
+	}()
+
+	cmd.Wait()
+
+	err = writeJSON(filepath.Join(dir, exitFile), &Exit{
+		ExitCode:   cmd.ProcessState.ExitCode(),
+		FinishedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("Run: %w", err)
+	}
+
+	return nil
+}
+
+// serve passes the signals requested on the control socket to the process.
+// The request is the signal number in the line, the response is "ok"
+// or the error message.
+func serve(ln net.Listener, proc *os.Process) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(controlTimeout))
+
+			line, err := bufio.NewReader(conn).ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			sig, err := strconv.Atoi(strings.TrimSpace(line))
+			if err == nil {
+				err = proc.Signal(syscall.Signal(sig))
+			}
+			if err != nil {
+				fmt.Fprintf(conn, "%s\n", err)
+				return
+			}
+			fmt.Fprintf(conn, "ok\n")
+		}()
+	}
+}
+
+// writeJSON atomically writes the value into the file of the spool directory.
+func writeJSON(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("writeJSON: marshal %s failed %w", filepath.Base(path), err)
+	}
+
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("writeJSON: write %s failed %w", filepath.Base(path), err)
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return fmt.Errorf("writeJSON: rename %s failed %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
+
+// readJSON reads the value from the file of the spool directory.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("readJSON: read %s failed %w", filepath.Base(path), err)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("readJSON: unmarshal %s failed %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain runs the test binary as the supervisor when it is started by Start.
+func TestMain(m *testing.M) {
+	if len(os.Args) == 2 && filepath.Base(os.Args[1]) == "run" {
+		if err := Run(os.Args[1]); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// failingWriter fails the first write and collects the next ones.
+type failingWriter struct {
+	mu     sync.Mutex
+	failed bool
+	buf    bytes.Buffer
+}
+
+func (w *failingWriter) Write(d []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.failed {
+		w.failed = true
+		return -1, errors.New("storage is unavailable")
+	}
+	return w.buf.Write(d)
+}
+
+func TestStart(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		spec     Spec
+		wantErr  bool
+		wantCode int
+		wantOut  string
+	}{
+		{
+			name:    "succeeded",
+			spec:    Spec{Path: "/bin/sh", Args: []string{"-c", "echo $GREETING; cat"}, Env: []string{"GREETING=hello"}, Input: []byte("input\n")},
+			wantOut: "hello\ninput\n",
+		},
+		{
+			name:     "failed",
+			spec:     Spec{Path: "/bin/sh", Args: []string{"-c", "echo failed >&2; exit 3"}},
+			wantCode: 3,
+			wantOut:  "failed\n",
+		},
+		{
+			name:    "command_not_found",
+			spec:    Spec{Path: "/not/found"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "run")
+
+			h, err := Start(os.Args[0], dir, &tt.spec)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrNotStarted)
+				require.NoDirExists(t, dir)
+				return
+			}
+			require.NoError(t, err)
+
+			w := &failingWriter{}
+			exit, err := h.Follow(ctx, 0, w, 10*time.Millisecond)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, exit.ExitCode)
+			require.Equal(t, tt.wantOut, w.buf.String())
+
+			require.NoError(t, h.Remove())
+			require.NoDirExists(t, dir)
+		})
+	}
+}
+
+func TestHandle_Signal(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "run")
+
+	h, err := Start(os.Args[0], dir, &Spec{Path: "/bin/sh", Args: []string{"-c", "echo started; exec sleep 10"}})
+	require.NoError(t, err)
+
+	// The restarted server attaches to the running command from the offset
+	// of the output it has already stored
+	attached, err := Attach(dir)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		data, _ := os.ReadFile(filepath.Join(dir, outputFile))
+		return string(data) == "started\n"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, attached.Signal(syscall.SIGTERM))
+
+	var out bytes.Buffer
+	exit, err := attached.Follow(ctx, int64(len("started\n")), &out, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, -1, exit.ExitCode)
+	require.Empty(t, out.String())
+
+	require.ErrorIs(t, h.Signal(syscall.SIGTERM), os.ErrProcessDone)
+
+	_, err = Attach(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorIs(t, err, ErrNotStarted)
+}
+
+func TestHandle_FollowLost(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "run")
+
+	h, err := Start(os.Args[0], dir, &Spec{Path: "/bin/sh", Args: []string{"-c", "echo started; exec sleep 10"}})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		data, _ := os.ReadFile(filepath.Join(dir, outputFile))
+		return string(data) == "started\n"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.False(t, h.Lost())
+
+	// The supervisor is killed without storing the exit status
+	st, err := h.state()
+	require.NoError(t, err)
+	p, err := os.FindProcess(st.Supervisor)
+	require.NoError(t, err)
+	require.NoError(t, p.Kill())
+
+	var out bytes.Buffer
+	_, err = h.Follow(ctx, 0, &out, 10*time.Millisecond)
+	require.ErrorIs(t, err, ErrSupervisorLost)
+	require.Equal(t, "started\n", out.String())
+	require.True(t, h.Lost())
+
+	// The unsupervised command is killed
+	require.Eventually(t, func() bool {
+		return !alive(st.PID)
+	}, 5*time.Second, 10*time.Millisecond)
+}
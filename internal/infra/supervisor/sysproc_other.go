@@ -0,0 +1,18 @@
+//go:build !unix
+
+package supervisor
+
+import "syscall"
+
+// detachedAttr returns the default attributes where the sessions are not supported.
+func detachedAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// alive reports the process alive where its existence is not checked.
+func alive(pid int) bool {
+	return true
+}
+
+// kill does nothing where the process existence is not checked.
+func kill(pid int) {}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package supervisor
+
+import (
+	"errors"
+	"syscall"
+)
+
+// detachedAttr starts the supervisor in the new session, so the signals
+// sent to the process group of the server do not reach it.
+func detachedAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// alive reports whether the process exists, the process of another
+// user is reported alive.
+func alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return !errors.Is(err, syscall.ESRCH)
+}
+
+// kill kills the process.
+func kill(pid int) {
+	syscall.Kill(pid, syscall.SIGKILL)
+}
@@ -0,0 +1,282 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// serviceName is the name of the service in the exported resource.
+	serviceName = "scripts-hub"
+	// scopeName is the name of the instrumentation scope of the spans.
+	scopeName = "github.com/pavlegich/scripts-hub"
+	// maxBuffered is the maximum number of the ended spans waiting for the export,
+	// the spans ended when the buffer is full are dropped.
+	maxBuffered = 4096
+	// statusError is the OTLP status code of the failed span.
+	statusError = 2
+)
+
+var (
+	exporterMu sync.RWMutex
+	exporter   *Exporter
+)
+
+// Exporter exports the ended spans in OTLP/JSON format to the collector
+// endpoint and appends them to the local file as the lines of JSON.
+type Exporter struct {
+	endpoint string
+	file     string
+	client   *http.Client
+
+	mu      sync.Mutex
+	spans   []otlpSpan
+	dropped int
+}
+
+// Init creates the exporter sending the spans to the collector endpoint
+// and/or appending them to the file every interval until the context is done.
+// Tracing is disabled if neither endpoint nor file is specified.
+func Init(ctx context.Context, endpoint, file string, interval time.Duration) error {
+	if endpoint == "" && file == "" {
+		return nil
+	}
+	if interval <= 0 {
+		return fmt.Errorf("Init: incorrect export interval %s", interval)
+	}
+
+	e := NewExporter(endpoint, file)
+
+	exporterMu.Lock()
+	exporter = e
+	exporterMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := e.Flush(context.Background())
+				if err != nil {
+					logger.Log.Error("Init: export spans failed",
+						zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// NewExporter returns new exporter of the spans.
+func NewExporter(endpoint, file string) *Exporter {
+	return &Exporter{
+		endpoint: endpoint,
+		file:     file,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// add puts the ended span into the buffer of the exporter.
+func (e *Exporter) add(span otlpSpan) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.spans) >= maxBuffered {
+		e.dropped++
+		return
+	}
+	e.spans = append(e.spans, span)
+}
+
+// Flush exports the buffered spans. The spans which export failed are dropped,
+// so the unavailable collector does not exhaust the memory.
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	spans, dropped := e.spans, e.dropped
+	e.spans, e.dropped = nil, 0
+	e.mu.Unlock()
+
+	if dropped > 0 {
+		logger.Log.Warn("Flush: spans dropped, buffer is full",
+			zap.Int("dropped", dropped))
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(newRequest(spans))
+	if err != nil {
+		return fmt.Errorf("Flush: marshal spans failed %w", err)
+	}
+
+	if e.file != "" {
+		err = e.write(body)
+		if err != nil {
+			return fmt.Errorf("Flush: %w", err)
+		}
+	}
+	if e.endpoint != "" {
+		err = e.send(ctx, body)
+		if err != nil {
+			return fmt.Errorf("Flush: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// write appends the export request to the file as the single line.
+func (e *Exporter) write(body []byte) error {
+	f, err := os.OpenFile(e.file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("write: open file failed %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(body, '\n'))
+	if err != nil {
+		return fmt.Errorf("write: write file failed %w", err)
+	}
+
+	return nil
+}
+
+// send posts the export request to the collector endpoint.
+func (e *Exporter) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("send: create request failed %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send: post spans failed %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("send: unexpected response status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Shutdown exports the remaining spans and disables the tracing.
+func Shutdown(ctx context.Context) error {
+	exporterMu.Lock()
+	e := exporter
+	exporter = nil
+	exporterMu.Unlock()
+
+	if e == nil {
+		return nil
+	}
+
+	err := e.Flush(ctx)
+	if err != nil {
+		return fmt.Errorf("Shutdown: %w", err)
+	}
+
+	return nil
+}
+
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            *otlpStatus     `json:"status,omitempty"`
+	}
+
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+
+	otlpValue struct {
+		StringValue *string `json:"stringValue,omitempty"`
+		IntValue    *string `json:"intValue,omitempty"`
+	}
+
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+// newRequest returns the OTLP/JSON export request of the spans.
+func newRequest(spans []otlpSpan) otlpRequest {
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: otlpAttributes([]Attribute{String("service.name", serviceName)}),
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: scopeName},
+				Spans: spans,
+			}},
+		}},
+	}
+}
+
+// otlpAttributes returns the attributes in OTLP/JSON format,
+// the integers are encoded as strings.
+func otlpAttributes(attrs []Attribute) []otlpAttribute {
+	res := make([]otlpAttribute, 0, len(attrs))
+	for _, a := range attrs {
+		var v otlpValue
+		switch val := a.Value.(type) {
+		case int:
+			s := strconv.Itoa(val)
+			v.IntValue = &s
+		default:
+			s := fmt.Sprint(val)
+			v.StringValue = &s
+		}
+		res = append(res, otlpAttribute{Key: a.Key, Value: v})
+	}
+	return res
+}
@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExporter_Flush(t *testing.T) {
+	ctx := context.Background()
+
+	received := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received <- body
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "spans.jsonl")
+	e := NewExporter(srv.URL, file)
+
+	exporterMu.Lock()
+	exporter = e
+	exporterMu.Unlock()
+	defer Shutdown(ctx)
+
+	ctx, parent := Start(ctx, "POST /command", KindServer, String("http.method", "POST"))
+	_, child := Start(ctx, "repository.CreateRun", KindClient)
+	child.SetError(errors.New("connection refused"))
+	child.End()
+	parent.SetAttributes(Int("http.status_code", 201))
+	parent.End()
+	parent.End()
+
+	require.NoError(t, e.Flush(ctx))
+	require.NoError(t, e.Flush(ctx))
+
+	// Exported request
+	var req otlpRequest
+	require.NoError(t, json.Unmarshal(<-received, &req))
+	require.Len(t, req.ResourceSpans, 1)
+	require.Equal(t, "service.name", req.ResourceSpans[0].Resource.Attributes[0].Key)
+
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	require.Equal(t, "repository.CreateRun", spans[0].Name)
+	require.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	require.Equal(t, spans[1].TraceID, spans[0].TraceID)
+	require.Equal(t, &otlpStatus{Code: statusError, Message: "connection refused"}, spans[0].Status)
+	require.Equal(t, KindServer, spans[1].Kind)
+	require.Empty(t, spans[1].ParentSpanID)
+	require.Equal(t, "201", *spans[1].Attributes[1].Value.IntValue)
+
+	// Appended file lines
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 1)
+	require.True(t, json.Valid([]byte(lines[0])))
+}
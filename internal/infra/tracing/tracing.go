@@ -0,0 +1,206 @@
+// Package tracing contains the spans of the requests, queued runs, command
+// executions and repository calls, the W3C trace context propagation
+// and the exporter of the ended spans in OTLP/JSON format.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Kinds of the spans.
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// traceParentPattern matches the W3C traceparent header of version 00.
+var traceParentPattern = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// SpanContext contains the identifiers of the trace and of the span in it.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+}
+
+// IsValid reports whether the trace and span identifiers are not zero.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceParent returns the span context in the form of the W3C traceparent header.
+func (sc SpanContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-01", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]))
+}
+
+// ParseTraceParent parses the W3C traceparent header, it returns false
+// if the header is incorrect or contains zero identifiers.
+func ParseTraceParent(s string) (SpanContext, bool) {
+	var sc SpanContext
+
+	m := traceParentPattern.FindStringSubmatch(s)
+	if m == nil {
+		return sc, false
+	}
+	hex.Decode(sc.TraceID[:], []byte(m[1]))
+	hex.Decode(sc.SpanID[:], []byte(m[2]))
+
+	return sc, sc.IsValid()
+}
+
+// Attribute contains the key and the value of the span attribute.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// String returns the string attribute.
+func String(key, val string) Attribute {
+	return Attribute{Key: key, Value: val}
+}
+
+// Int returns the integer attribute.
+func Int(key string, val int) Attribute {
+	return Attribute{Key: key, Value: val}
+}
+
+// Span contains data of the single operation of the trace.
+type Span struct {
+	mu     sync.Mutex
+	name   string
+	kind   int
+	sc     SpanContext
+	parent [8]byte
+	start  time.Time
+	end    time.Time
+	attrs  []Attribute
+	err    string
+	ended  bool
+}
+
+type (
+	spanKey   struct{}
+	remoteKey struct{}
+)
+
+// Start starts new span which is the child of the span or of the remote span
+// context carried by the context, otherwise the span starts new trace.
+// It returns copy of the context carrying the new span.
+func Start(ctx context.Context, name string, kind int, attrs ...Attribute) (context.Context, *Span) {
+	s := &Span{
+		name:  name,
+		kind:  kind,
+		start: time.Now(),
+		attrs: attrs,
+	}
+
+	parent := SpanContextFrom(ctx)
+	if parent.IsValid() {
+		s.sc.TraceID = parent.TraceID
+		s.parent = parent.SpanID
+	} else {
+		rand.Read(s.sc.TraceID[:])
+	}
+	rand.Read(s.sc.SpanID[:])
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// ContextWithRemote returns copy of the context carrying the span context
+// received from the other process or stored for the later use.
+func ContextWithRemote(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// SpanContextFrom returns the span context of the span carried by the context
+// or the remote span context if the context does not carry the span.
+func SpanContextFrom(ctx context.Context) SpanContext {
+	if s, ok := ctx.Value(spanKey{}).(*Span); ok {
+		return s.sc
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// SpanContext returns the span context of the span.
+func (s *Span) SpanContext() SpanContext {
+	return s.sc
+}
+
+// TraceParent returns the span context in the form of the W3C traceparent header.
+func (s *Span) TraceParent() string {
+	return s.sc.TraceParent()
+}
+
+// SetAttributes adds the attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// SetError marks the span as failed with the error, nil error is ignored.
+func (s *Span) SetError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err.Error()
+}
+
+// End ends the span and passes it to the exporter, the repeated calls
+// and the calls on nil span do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	data := s.data()
+	s.mu.Unlock()
+
+	exporterMu.RLock()
+	e := exporter
+	exporterMu.RUnlock()
+	if e != nil {
+		e.add(data)
+	}
+}
+
+// data returns the span in OTLP/JSON format.
+func (s *Span) data() otlpSpan {
+	data := otlpSpan{
+		TraceID:           hex.EncodeToString(s.sc.TraceID[:]),
+		SpanID:            hex.EncodeToString(s.sc.SpanID[:]),
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Attributes:        otlpAttributes(s.attrs),
+	}
+	if s.parent != [8]byte{} {
+		data.ParentSpanID = hex.EncodeToString(s.parent[:])
+	}
+	if s.err != "" {
+		data.Status = &otlpStatus{Code: statusError, Message: s.err}
+	}
+
+	return data
+}
@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		wantOK bool
+	}{
+		{
+			name:   "correct",
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantOK: true,
+		},
+		{
+			name:   "not_sampled",
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			wantOK: true,
+		},
+		{
+			name:   "zero_trace_id",
+			header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		},
+		{
+			name:   "unknown_version",
+			header: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		{
+			name:   "upper_case",
+			header: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01",
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, ok := ParseTraceParent(tt.header)
+			require.Equal(t, tt.wantOK, ok)
+			if ok {
+				require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.TraceParent())
+			}
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	ctx := context.Background()
+
+	remote, ok := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+
+	// Remote parent
+	ctx, parent := Start(ContextWithRemote(ctx, remote), "parent", KindServer)
+	require.Equal(t, remote.TraceID, parent.SpanContext().TraceID)
+	require.Equal(t, remote.SpanID, parent.parent)
+	require.NotEqual(t, remote.SpanID, parent.SpanContext().SpanID)
+
+	// Child of the span of the context
+	_, child := Start(ctx, "child", KindInternal)
+	require.Equal(t, remote.TraceID, child.SpanContext().TraceID)
+	require.Equal(t, parent.SpanContext().SpanID, child.parent)
+
+	// New trace
+	_, root := Start(context.Background(), "root", KindInternal)
+	require.True(t, root.SpanContext().IsValid())
+	require.NotEqual(t, remote.TraceID, root.SpanContext().TraceID)
+	require.Equal(t, [8]byte{}, root.parent)
+}
@@ -0,0 +1,205 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/pavlegich/scripts-hub/internal/service/agent (interfaces: Service)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/pavlegich/scripts-hub/internal/entities"
+	agent "github.com/pavlegich/scripts-hub/internal/service/agent"
+)
+
+// MockAgentService is a mock of Service interface.
+type MockAgentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgentServiceMockRecorder
+}
+
+// MockAgentServiceMockRecorder is the mock recorder for MockAgentService.
+type MockAgentServiceMockRecorder struct {
+	mock *MockAgentService
+}
+
+// NewMockAgentService creates a new mock instance.
+func NewMockAgentService(ctrl *gomock.Controller) *MockAgentService {
+	mock := &MockAgentService{ctrl: ctrl}
+	mock.recorder = &MockAgentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgentService) EXPECT() *MockAgentServiceMockRecorder {
+	return m.recorder
+}
+
+// Acquire mocks base method.
+func (m *MockAgentService) Acquire(arg0 context.Context, arg1 []string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Acquire", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Acquire indicates an expected call of Acquire.
+func (mr *MockAgentServiceMockRecorder) Acquire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Acquire", reflect.TypeOf((*MockAgentService)(nil).Acquire), arg0, arg1)
+}
+
+// Authorize mocks base method.
+func (m *MockAgentService) Authorize(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockAgentServiceMockRecorder) Authorize(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockAgentService)(nil).Authorize), arg0)
+}
+
+// Exit mocks base method.
+func (m *MockAgentService) Exit(arg0 string, arg1 *entities.AgentExit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exit", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Exit indicates an expected call of Exit.
+func (mr *MockAgentServiceMockRecorder) Exit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exit", reflect.TypeOf((*MockAgentService)(nil).Exit), arg0, arg1)
+}
+
+// Expire mocks base method.
+func (m *MockAgentService) Expire(arg0 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Expire", arg0)
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockAgentServiceMockRecorder) Expire(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockAgentService)(nil).Expire), arg0)
+}
+
+// List mocks base method.
+func (m *MockAgentService) List(arg0 context.Context) []*entities.Agent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0)
+	ret0, _ := ret[0].([]*entities.Agent)
+	return ret0
+}
+
+// List indicates an expected call of List.
+func (mr *MockAgentServiceMockRecorder) List(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAgentService)(nil).List), arg0)
+}
+
+// Output mocks base method.
+func (m *MockAgentService) Output(arg0 string, arg1 int, arg2 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Output", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Output indicates an expected call of Output.
+func (mr *MockAgentServiceMockRecorder) Output(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Output", reflect.TypeOf((*MockAgentService)(nil).Output), arg0, arg1, arg2)
+}
+
+// Poll mocks base method.
+func (m *MockAgentService) Poll(arg0 context.Context, arg1 string, arg2 time.Duration) (*entities.AgentPoll, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Poll", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.AgentPoll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Poll indicates an expected call of Poll.
+func (mr *MockAgentServiceMockRecorder) Poll(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Poll", reflect.TypeOf((*MockAgentService)(nil).Poll), arg0, arg1, arg2)
+}
+
+// Register mocks base method.
+func (m *MockAgentService) Register(arg0 context.Context, arg1 *entities.Agent) (*entities.Agent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Agent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockAgentServiceMockRecorder) Register(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAgentService)(nil).Register), arg0, arg1)
+}
+
+// Release mocks base method.
+func (m *MockAgentService) Release(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Release", arg0)
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockAgentServiceMockRecorder) Release(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockAgentService)(nil).Release), arg0)
+}
+
+// RunExpiry mocks base method.
+func (m *MockAgentService) RunExpiry(arg0 context.Context, arg1 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunExpiry", arg0, arg1)
+}
+
+// RunExpiry indicates an expected call of RunExpiry.
+func (mr *MockAgentServiceMockRecorder) RunExpiry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunExpiry", reflect.TypeOf((*MockAgentService)(nil).RunExpiry), arg0, arg1)
+}
+
+// Signal mocks base method.
+func (m *MockAgentService) Signal(arg0, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Signal", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Signal indicates an expected call of Signal.
+func (mr *MockAgentServiceMockRecorder) Signal(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Signal", reflect.TypeOf((*MockAgentService)(nil).Signal), arg0, arg1)
+}
+
+// Start mocks base method.
+func (m *MockAgentService) Start(arg0 string, arg1 *entities.AgentJob, arg2 io.Writer) (*agent.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*agent.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockAgentServiceMockRecorder) Start(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockAgentService)(nil).Start), arg0, arg1, arg2)
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	entities "github.com/pavlegich/scripts-hub/internal/entities"
@@ -49,6 +50,35 @@ func (mr *MockRepositoryMockRecorder) AppendCommandOutputByName(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendCommandOutputByName", reflect.TypeOf((*MockRepository)(nil).AppendCommandOutputByName), arg0, arg1)
 }
 
+// AppendRunOutput mocks base method.
+func (m *MockRepository) AppendRunOutput(arg0 context.Context, arg1 *entities.Run) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendRunOutput", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendRunOutput indicates an expected call of AppendRunOutput.
+func (mr *MockRepositoryMockRecorder) AppendRunOutput(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendRunOutput", reflect.TypeOf((*MockRepository)(nil).AppendRunOutput), arg0, arg1)
+}
+
+// ClaimDueDeliveries mocks base method.
+func (m *MockRepository) ClaimDueDeliveries(arg0 context.Context, arg1 time.Time, arg2 time.Duration, arg3 int) ([]*entities.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueDeliveries", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*entities.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueDeliveries indicates an expected call of ClaimDueDeliveries.
+func (mr *MockRepositoryMockRecorder) ClaimDueDeliveries(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueDeliveries", reflect.TypeOf((*MockRepository)(nil).ClaimDueDeliveries), arg0, arg1, arg2, arg3)
+}
+
 // CreateCommand mocks base method.
 func (m *MockRepository) CreateCommand(arg0 context.Context, arg1 *entities.Command) (*entities.Command, error) {
 	m.ctrl.T.Helper()
@@ -64,6 +94,125 @@ func (mr *MockRepositoryMockRecorder) CreateCommand(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommand", reflect.TypeOf((*MockRepository)(nil).CreateCommand), arg0, arg1)
 }
 
+// CreateDeliveries mocks base method.
+func (m *MockRepository) CreateDeliveries(arg0 context.Context, arg1 []*entities.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeliveries", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeliveries indicates an expected call of CreateDeliveries.
+func (mr *MockRepositoryMockRecorder) CreateDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeliveries", reflect.TypeOf((*MockRepository)(nil).CreateDeliveries), arg0, arg1)
+}
+
+// CreatePipeline mocks base method.
+func (m *MockRepository) CreatePipeline(arg0 context.Context, arg1 *entities.Pipeline) (*entities.Pipeline, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePipeline", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Pipeline)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePipeline indicates an expected call of CreatePipeline.
+func (mr *MockRepositoryMockRecorder) CreatePipeline(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePipeline", reflect.TypeOf((*MockRepository)(nil).CreatePipeline), arg0, arg1)
+}
+
+// CreateRule mocks base method.
+func (m *MockRepository) CreateRule(arg0 context.Context, arg1 *entities.Rule) (*entities.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRule", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRule indicates an expected call of CreateRule.
+func (mr *MockRepositoryMockRecorder) CreateRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRule", reflect.TypeOf((*MockRepository)(nil).CreateRule), arg0, arg1)
+}
+
+// CreateRun mocks base method.
+func (m *MockRepository) CreateRun(arg0 context.Context, arg1 *entities.Run) (*entities.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRun", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRun indicates an expected call of CreateRun.
+func (mr *MockRepositoryMockRecorder) CreateRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRun", reflect.TypeOf((*MockRepository)(nil).CreateRun), arg0, arg1)
+}
+
+// CreateSchedule mocks base method.
+func (m *MockRepository) CreateSchedule(arg0 context.Context, arg1 *entities.Schedule) (*entities.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSchedule", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSchedule indicates an expected call of CreateSchedule.
+func (mr *MockRepositoryMockRecorder) CreateSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedule", reflect.TypeOf((*MockRepository)(nil).CreateSchedule), arg0, arg1)
+}
+
+// CreateTrigger mocks base method.
+func (m *MockRepository) CreateTrigger(arg0 context.Context, arg1 *entities.Trigger) (*entities.Trigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTrigger", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Trigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTrigger indicates an expected call of CreateTrigger.
+func (mr *MockRepositoryMockRecorder) CreateTrigger(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTrigger", reflect.TypeOf((*MockRepository)(nil).CreateTrigger), arg0, arg1)
+}
+
+// CreateWorkflow mocks base method.
+func (m *MockRepository) CreateWorkflow(arg0 context.Context, arg1 *entities.Workflow) (*entities.Workflow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkflow", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Workflow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWorkflow indicates an expected call of CreateWorkflow.
+func (mr *MockRepositoryMockRecorder) CreateWorkflow(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkflow", reflect.TypeOf((*MockRepository)(nil).CreateWorkflow), arg0, arg1)
+}
+
+// CreateWorkflowRun mocks base method.
+func (m *MockRepository) CreateWorkflowRun(arg0 context.Context, arg1 *entities.WorkflowRun) (*entities.WorkflowRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkflowRun", arg0, arg1)
+	ret0, _ := ret[0].(*entities.WorkflowRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWorkflowRun indicates an expected call of CreateWorkflowRun.
+func (mr *MockRepositoryMockRecorder) CreateWorkflowRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkflowRun", reflect.TypeOf((*MockRepository)(nil).CreateWorkflowRun), arg0, arg1)
+}
+
 // DeleteCommandByName mocks base method.
 func (m *MockRepository) DeleteCommandByName(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -78,6 +227,77 @@ func (mr *MockRepositoryMockRecorder) DeleteCommandByName(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCommandByName", reflect.TypeOf((*MockRepository)(nil).DeleteCommandByName), arg0, arg1)
 }
 
+// DeleteScheduleByName mocks base method.
+func (m *MockRepository) DeleteScheduleByName(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteScheduleByName", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteScheduleByName indicates an expected call of DeleteScheduleByName.
+func (mr *MockRepositoryMockRecorder) DeleteScheduleByName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteScheduleByName", reflect.TypeOf((*MockRepository)(nil).DeleteScheduleByName), arg0, arg1)
+}
+
+// DeleteWorkflowByName mocks base method.
+func (m *MockRepository) DeleteWorkflowByName(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkflowByName", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkflowByName indicates an expected call of DeleteWorkflowByName.
+func (mr *MockRepositoryMockRecorder) DeleteWorkflowByName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkflowByName", reflect.TypeOf((*MockRepository)(nil).DeleteWorkflowByName), arg0, arg1)
+}
+
+// FinishRun mocks base method.
+func (m *MockRepository) FinishRun(arg0 context.Context, arg1 *entities.Run) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishRun", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishRun indicates an expected call of FinishRun.
+func (mr *MockRepositoryMockRecorder) FinishRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishRun", reflect.TypeOf((*MockRepository)(nil).FinishRun), arg0, arg1)
+}
+
+// FinishWorkflowRun mocks base method.
+func (m *MockRepository) FinishWorkflowRun(arg0 context.Context, arg1 *entities.WorkflowRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishWorkflowRun", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishWorkflowRun indicates an expected call of FinishWorkflowRun.
+func (mr *MockRepositoryMockRecorder) FinishWorkflowRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishWorkflowRun", reflect.TypeOf((*MockRepository)(nil).FinishWorkflowRun), arg0, arg1)
+}
+
+// GetActiveTriggers mocks base method.
+func (m *MockRepository) GetActiveTriggers(arg0 context.Context, arg1 string) ([]*entities.Trigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveTriggers", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Trigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveTriggers indicates an expected call of GetActiveTriggers.
+func (mr *MockRepositoryMockRecorder) GetActiveTriggers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveTriggers", reflect.TypeOf((*MockRepository)(nil).GetActiveTriggers), arg0, arg1)
+}
+
 // GetAllCommands mocks base method.
 func (m *MockRepository) GetAllCommands(arg0 context.Context) ([]*entities.Command, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +313,51 @@ func (mr *MockRepositoryMockRecorder) GetAllCommands(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCommands", reflect.TypeOf((*MockRepository)(nil).GetAllCommands), arg0)
 }
 
+// GetAllRules mocks base method.
+func (m *MockRepository) GetAllRules(arg0 context.Context) ([]*entities.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllRules", arg0)
+	ret0, _ := ret[0].([]*entities.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllRules indicates an expected call of GetAllRules.
+func (mr *MockRepositoryMockRecorder) GetAllRules(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRules", reflect.TypeOf((*MockRepository)(nil).GetAllRules), arg0)
+}
+
+// GetAllSchedules mocks base method.
+func (m *MockRepository) GetAllSchedules(arg0 context.Context) ([]*entities.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllSchedules", arg0)
+	ret0, _ := ret[0].([]*entities.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllSchedules indicates an expected call of GetAllSchedules.
+func (mr *MockRepositoryMockRecorder) GetAllSchedules(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllSchedules", reflect.TypeOf((*MockRepository)(nil).GetAllSchedules), arg0)
+}
+
+// GetAllWorkflows mocks base method.
+func (m *MockRepository) GetAllWorkflows(arg0 context.Context) ([]*entities.Workflow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllWorkflows", arg0)
+	ret0, _ := ret[0].([]*entities.Workflow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllWorkflows indicates an expected call of GetAllWorkflows.
+func (mr *MockRepositoryMockRecorder) GetAllWorkflows(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllWorkflows", reflect.TypeOf((*MockRepository)(nil).GetAllWorkflows), arg0)
+}
+
 // GetCommandByName mocks base method.
 func (m *MockRepository) GetCommandByName(arg0 context.Context, arg1 string) (*entities.Command, error) {
 	m.ctrl.T.Helper()
@@ -107,3 +372,428 @@ func (mr *MockRepositoryMockRecorder) GetCommandByName(arg0, arg1 interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommandByName", reflect.TypeOf((*MockRepository)(nil).GetCommandByName), arg0, arg1)
 }
+
+// GetDeadDeliveries mocks base method.
+func (m *MockRepository) GetDeadDeliveries(arg0 context.Context) ([]*entities.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadDeliveries", arg0)
+	ret0, _ := ret[0].([]*entities.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadDeliveries indicates an expected call of GetDeadDeliveries.
+func (mr *MockRepositoryMockRecorder) GetDeadDeliveries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadDeliveries", reflect.TypeOf((*MockRepository)(nil).GetDeadDeliveries), arg0)
+}
+
+// GetDueRuns mocks base method.
+func (m *MockRepository) GetDueRuns(arg0 context.Context, arg1 time.Time) ([]*entities.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueRuns", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueRuns indicates an expected call of GetDueRuns.
+func (mr *MockRepositoryMockRecorder) GetDueRuns(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueRuns", reflect.TypeOf((*MockRepository)(nil).GetDueRuns), arg0, arg1)
+}
+
+// GetDueSchedules mocks base method.
+func (m *MockRepository) GetDueSchedules(arg0 context.Context, arg1 time.Time) ([]*entities.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueSchedules", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueSchedules indicates an expected call of GetDueSchedules.
+func (mr *MockRepositoryMockRecorder) GetDueSchedules(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueSchedules", reflect.TypeOf((*MockRepository)(nil).GetDueSchedules), arg0, arg1)
+}
+
+// GetEnabledRules mocks base method.
+func (m *MockRepository) GetEnabledRules(arg0 context.Context, arg1, arg2 string) ([]*entities.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnabledRules", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entities.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEnabledRules indicates an expected call of GetEnabledRules.
+func (mr *MockRepositoryMockRecorder) GetEnabledRules(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnabledRules", reflect.TypeOf((*MockRepository)(nil).GetEnabledRules), arg0, arg1, arg2)
+}
+
+// GetMigrationVersion mocks base method.
+func (m *MockRepository) GetMigrationVersion(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMigrationVersion", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMigrationVersion indicates an expected call of GetMigrationVersion.
+func (mr *MockRepositoryMockRecorder) GetMigrationVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMigrationVersion", reflect.TypeOf((*MockRepository)(nil).GetMigrationVersion), arg0)
+}
+
+// GetPendingRuns mocks base method.
+func (m *MockRepository) GetPendingRuns(arg0 context.Context) ([]*entities.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingRuns", arg0)
+	ret0, _ := ret[0].([]*entities.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingRuns indicates an expected call of GetPendingRuns.
+func (mr *MockRepositoryMockRecorder) GetPendingRuns(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingRuns", reflect.TypeOf((*MockRepository)(nil).GetPendingRuns), arg0)
+}
+
+// GetPipelineByID mocks base method.
+func (m *MockRepository) GetPipelineByID(arg0 context.Context, arg1 int) (*entities.Pipeline, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPipelineByID", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Pipeline)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPipelineByID indicates an expected call of GetPipelineByID.
+func (mr *MockRepositoryMockRecorder) GetPipelineByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPipelineByID", reflect.TypeOf((*MockRepository)(nil).GetPipelineByID), arg0, arg1)
+}
+
+// GetRuleByID mocks base method.
+func (m *MockRepository) GetRuleByID(arg0 context.Context, arg1 int) (*entities.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRuleByID", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRuleByID indicates an expected call of GetRuleByID.
+func (mr *MockRepositoryMockRecorder) GetRuleByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRuleByID", reflect.TypeOf((*MockRepository)(nil).GetRuleByID), arg0, arg1)
+}
+
+// GetRunAttempts mocks base method.
+func (m *MockRepository) GetRunAttempts(arg0 context.Context, arg1 int) ([]*entities.Attempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunAttempts", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Attempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunAttempts indicates an expected call of GetRunAttempts.
+func (mr *MockRepositoryMockRecorder) GetRunAttempts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunAttempts", reflect.TypeOf((*MockRepository)(nil).GetRunAttempts), arg0, arg1)
+}
+
+// GetRunByID mocks base method.
+func (m *MockRepository) GetRunByID(arg0 context.Context, arg1 int) (*entities.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunByID", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunByID indicates an expected call of GetRunByID.
+func (mr *MockRepositoryMockRecorder) GetRunByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunByID", reflect.TypeOf((*MockRepository)(nil).GetRunByID), arg0, arg1)
+}
+
+// GetRunsByCommandName mocks base method.
+func (m *MockRepository) GetRunsByCommandName(arg0 context.Context, arg1 string) ([]*entities.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunsByCommandName", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunsByCommandName indicates an expected call of GetRunsByCommandName.
+func (mr *MockRepositoryMockRecorder) GetRunsByCommandName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunsByCommandName", reflect.TypeOf((*MockRepository)(nil).GetRunsByCommandName), arg0, arg1)
+}
+
+// GetTriggerByID mocks base method.
+func (m *MockRepository) GetTriggerByID(arg0 context.Context, arg1 int) (*entities.Trigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTriggerByID", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Trigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTriggerByID indicates an expected call of GetTriggerByID.
+func (mr *MockRepositoryMockRecorder) GetTriggerByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerByID", reflect.TypeOf((*MockRepository)(nil).GetTriggerByID), arg0, arg1)
+}
+
+// GetTriggersByCommandName mocks base method.
+func (m *MockRepository) GetTriggersByCommandName(arg0 context.Context, arg1 string) ([]*entities.Trigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTriggersByCommandName", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Trigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTriggersByCommandName indicates an expected call of GetTriggersByCommandName.
+func (mr *MockRepositoryMockRecorder) GetTriggersByCommandName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggersByCommandName", reflect.TypeOf((*MockRepository)(nil).GetTriggersByCommandName), arg0, arg1)
+}
+
+// GetWorkflowByName mocks base method.
+func (m *MockRepository) GetWorkflowByName(arg0 context.Context, arg1 string) (*entities.Workflow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkflowByName", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Workflow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkflowByName indicates an expected call of GetWorkflowByName.
+func (mr *MockRepositoryMockRecorder) GetWorkflowByName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkflowByName", reflect.TypeOf((*MockRepository)(nil).GetWorkflowByName), arg0, arg1)
+}
+
+// GetWorkflowRunByID mocks base method.
+func (m *MockRepository) GetWorkflowRunByID(arg0 context.Context, arg1 int) (*entities.WorkflowRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkflowRunByID", arg0, arg1)
+	ret0, _ := ret[0].(*entities.WorkflowRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkflowRunByID indicates an expected call of GetWorkflowRunByID.
+func (mr *MockRepositoryMockRecorder) GetWorkflowRunByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkflowRunByID", reflect.TypeOf((*MockRepository)(nil).GetWorkflowRunByID), arg0, arg1)
+}
+
+// Listen mocks base method.
+func (m *MockRepository) Listen(arg0 context.Context, arg1 []string, arg2 chan<- *entities.Notification) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Listen", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Listen indicates an expected call of Listen.
+func (mr *MockRepositoryMockRecorder) Listen(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockRepository)(nil).Listen), arg0, arg1, arg2)
+}
+
+// MoveRunStatus mocks base method.
+func (m *MockRepository) MoveRunStatus(arg0 context.Context, arg1 *entities.Run, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveRunStatus", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveRunStatus indicates an expected call of MoveRunStatus.
+func (mr *MockRepositoryMockRecorder) MoveRunStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveRunStatus", reflect.TypeOf((*MockRepository)(nil).MoveRunStatus), arg0, arg1, arg2)
+}
+
+// MoveScheduleNextRun mocks base method.
+func (m *MockRepository) MoveScheduleNextRun(arg0 context.Context, arg1 *entities.Schedule, arg2 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveScheduleNextRun", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveScheduleNextRun indicates an expected call of MoveScheduleNextRun.
+func (mr *MockRepositoryMockRecorder) MoveScheduleNextRun(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveScheduleNextRun", reflect.TypeOf((*MockRepository)(nil).MoveScheduleNextRun), arg0, arg1, arg2)
+}
+
+// Ping mocks base method.
+func (m *MockRepository) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockRepositoryMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRepository)(nil).Ping), arg0)
+}
+
+// ReplayDelivery mocks base method.
+func (m *MockRepository) ReplayDelivery(arg0 context.Context, arg1 int, arg2 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayDelivery", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplayDelivery indicates an expected call of ReplayDelivery.
+func (mr *MockRepositoryMockRecorder) ReplayDelivery(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayDelivery", reflect.TypeOf((*MockRepository)(nil).ReplayDelivery), arg0, arg1, arg2)
+}
+
+// RetryRun mocks base method.
+func (m *MockRepository) RetryRun(arg0 context.Context, arg1 *entities.Run) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryRun", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryRun indicates an expected call of RetryRun.
+func (mr *MockRepositoryMockRecorder) RetryRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryRun", reflect.TypeOf((*MockRepository)(nil).RetryRun), arg0, arg1)
+}
+
+// RevokeTrigger mocks base method.
+func (m *MockRepository) RevokeTrigger(arg0 context.Context, arg1 int, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeTrigger", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeTrigger indicates an expected call of RevokeTrigger.
+func (mr *MockRepositoryMockRecorder) RevokeTrigger(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeTrigger", reflect.TypeOf((*MockRepository)(nil).RevokeTrigger), arg0, arg1, arg2)
+}
+
+// RotateTriggerToken mocks base method.
+func (m *MockRepository) RotateTriggerToken(arg0 context.Context, arg1 *entities.Trigger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateTriggerToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateTriggerToken indicates an expected call of RotateTriggerToken.
+func (mr *MockRepositoryMockRecorder) RotateTriggerToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateTriggerToken", reflect.TypeOf((*MockRepository)(nil).RotateTriggerToken), arg0, arg1)
+}
+
+// SetRuleEnabled mocks base method.
+func (m *MockRepository) SetRuleEnabled(arg0 context.Context, arg1 int, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRuleEnabled", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRuleEnabled indicates an expected call of SetRuleEnabled.
+func (mr *MockRepositoryMockRecorder) SetRuleEnabled(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRuleEnabled", reflect.TypeOf((*MockRepository)(nil).SetRuleEnabled), arg0, arg1, arg2)
+}
+
+// StartRun mocks base method.
+func (m *MockRepository) StartRun(arg0 context.Context, arg1 *entities.Run) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartRun", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartRun indicates an expected call of StartRun.
+func (mr *MockRepositoryMockRecorder) StartRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartRun", reflect.TypeOf((*MockRepository)(nil).StartRun), arg0, arg1)
+}
+
+// SuspendRun mocks base method.
+func (m *MockRepository) SuspendRun(arg0 context.Context, arg1 *entities.Run) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuspendRun", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SuspendRun indicates an expected call of SuspendRun.
+func (mr *MockRepositoryMockRecorder) SuspendRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuspendRun", reflect.TypeOf((*MockRepository)(nil).SuspendRun), arg0, arg1)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockRepository) UpdateDelivery(arg0 context.Context, arg1 *entities.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockRepositoryMockRecorder) UpdateDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockRepository)(nil).UpdateDelivery), arg0, arg1)
+}
+
+// UpdatePipeline mocks base method.
+func (m *MockRepository) UpdatePipeline(arg0 context.Context, arg1 *entities.Pipeline) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePipeline", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePipeline indicates an expected call of UpdatePipeline.
+func (mr *MockRepositoryMockRecorder) UpdatePipeline(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePipeline", reflect.TypeOf((*MockRepository)(nil).UpdatePipeline), arg0, arg1)
+}
+
+// UpdateWorkflowNodeRun mocks base method.
+func (m *MockRepository) UpdateWorkflowNodeRun(arg0 context.Context, arg1 int, arg2 *entities.NodeRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkflowNodeRun", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkflowNodeRun indicates an expected call of UpdateWorkflowNodeRun.
+func (mr *MockRepositoryMockRecorder) UpdateWorkflowNodeRun(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkflowNodeRun", reflect.TypeOf((*MockRepository)(nil).UpdateWorkflowNodeRun), arg0, arg1, arg2)
+}
@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/pavlegich/scripts-hub/internal/service/rule (interfaces: Service)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/pavlegich/scripts-hub/internal/entities"
+)
+
+// MockRuleService is a mock of Service interface.
+type MockRuleService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRuleServiceMockRecorder
+}
+
+// MockRuleServiceMockRecorder is the mock recorder for MockRuleService.
+type MockRuleServiceMockRecorder struct {
+	mock *MockRuleService
+}
+
+// NewMockRuleService creates a new mock instance.
+func NewMockRuleService(ctrl *gomock.Controller) *MockRuleService {
+	mock := &MockRuleService{ctrl: ctrl}
+	mock.recorder = &MockRuleServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRuleService) EXPECT() *MockRuleServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRuleService) Create(arg0 context.Context, arg1 *entities.Rule) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRuleServiceMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRuleService)(nil).Create), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockRuleService) List(arg0 context.Context) ([]*entities.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0)
+	ret0, _ := ret[0].([]*entities.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRuleServiceMockRecorder) List(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRuleService)(nil).List), arg0)
+}
+
+// Match mocks base method.
+func (m *MockRuleService) Match(arg0 context.Context, arg1 *entities.Run) ([]*entities.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Match", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Match indicates an expected call of Match.
+func (mr *MockRuleServiceMockRecorder) Match(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Match", reflect.TypeOf((*MockRuleService)(nil).Match), arg0, arg1)
+}
+
+// SetEnabled mocks base method.
+func (m *MockRuleService) SetEnabled(arg0 context.Context, arg1 int, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEnabled", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEnabled indicates an expected call of SetEnabled.
+func (mr *MockRuleServiceMockRecorder) SetEnabled(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEnabled", reflect.TypeOf((*MockRuleService)(nil).SetEnabled), arg0, arg1, arg2)
+}
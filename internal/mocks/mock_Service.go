@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	entities "github.com/pavlegich/scripts-hub/internal/entities"
@@ -49,6 +50,49 @@ func (mr *MockServiceMockRecorder) AppendOutput(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendOutput", reflect.TypeOf((*MockService)(nil).AppendOutput), arg0, arg1)
 }
 
+// AppendRunOutput mocks base method.
+func (m *MockService) AppendRunOutput(arg0 context.Context, arg1 *entities.Run) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendRunOutput", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendRunOutput indicates an expected call of AppendRunOutput.
+func (mr *MockServiceMockRecorder) AppendRunOutput(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendRunOutput", reflect.TypeOf((*MockService)(nil).AppendRunOutput), arg0, arg1)
+}
+
+// CancelPendingRun mocks base method.
+func (m *MockService) CancelPendingRun(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPendingRun", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelPendingRun indicates an expected call of CancelPendingRun.
+func (mr *MockServiceMockRecorder) CancelPendingRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPendingRun", reflect.TypeOf((*MockService)(nil).CancelPendingRun), arg0, arg1)
+}
+
+// ClaimRun mocks base method.
+func (m *MockService) ClaimRun(arg0 context.Context, arg1 *entities.Run) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimRun", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimRun indicates an expected call of ClaimRun.
+func (mr *MockServiceMockRecorder) ClaimRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimRun", reflect.TypeOf((*MockService)(nil).ClaimRun), arg0, arg1)
+}
+
 // Create mocks base method.
 func (m *MockService) Create(arg0 context.Context, arg1 *entities.Command) (int, error) {
 	m.ctrl.T.Helper()
@@ -64,6 +108,36 @@ func (mr *MockServiceMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockService)(nil).Create), arg0, arg1)
 }
 
+// CreatePipeline mocks base method.
+func (m *MockService) CreatePipeline(arg0 context.Context, arg1 []*entities.Command) (*entities.Pipeline, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePipeline", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Pipeline)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePipeline indicates an expected call of CreatePipeline.
+func (mr *MockServiceMockRecorder) CreatePipeline(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePipeline", reflect.TypeOf((*MockService)(nil).CreatePipeline), arg0, arg1)
+}
+
+// CreateRun mocks base method.
+func (m *MockService) CreateRun(arg0 context.Context, arg1 *entities.Run) (*entities.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRun", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRun indicates an expected call of CreateRun.
+func (mr *MockServiceMockRecorder) CreateRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRun", reflect.TypeOf((*MockService)(nil).CreateRun), arg0, arg1)
+}
+
 // Delete mocks base method.
 func (m *MockService) Delete(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -78,6 +152,49 @@ func (mr *MockServiceMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), arg0, arg1)
 }
 
+// DueRuns mocks base method.
+func (m *MockService) DueRuns(arg0 context.Context, arg1 time.Time) ([]*entities.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DueRuns", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DueRuns indicates an expected call of DueRuns.
+func (mr *MockServiceMockRecorder) DueRuns(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DueRuns", reflect.TypeOf((*MockService)(nil).DueRuns), arg0, arg1)
+}
+
+// FinishPipeline mocks base method.
+func (m *MockService) FinishPipeline(arg0 context.Context, arg1 *entities.Pipeline) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishPipeline", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishPipeline indicates an expected call of FinishPipeline.
+func (mr *MockServiceMockRecorder) FinishPipeline(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPipeline", reflect.TypeOf((*MockService)(nil).FinishPipeline), arg0, arg1)
+}
+
+// FinishRun mocks base method.
+func (m *MockService) FinishRun(arg0 context.Context, arg1 *entities.Run) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishRun", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishRun indicates an expected call of FinishRun.
+func (mr *MockServiceMockRecorder) FinishRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishRun", reflect.TypeOf((*MockService)(nil).FinishRun), arg0, arg1)
+}
+
 // List mocks base method.
 func (m *MockService) List(arg0 context.Context) ([]*entities.Command, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +210,92 @@ func (mr *MockServiceMockRecorder) List(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockService)(nil).List), arg0)
 }
 
+// ListPendingRuns mocks base method.
+func (m *MockService) ListPendingRuns(arg0 context.Context) ([]*entities.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingRuns", arg0)
+	ret0, _ := ret[0].([]*entities.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingRuns indicates an expected call of ListPendingRuns.
+func (mr *MockServiceMockRecorder) ListPendingRuns(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingRuns", reflect.TypeOf((*MockService)(nil).ListPendingRuns), arg0)
+}
+
+// ListRuns mocks base method.
+func (m *MockService) ListRuns(arg0 context.Context, arg1 string) ([]*entities.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRuns", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRuns indicates an expected call of ListRuns.
+func (mr *MockServiceMockRecorder) ListRuns(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRuns", reflect.TypeOf((*MockService)(nil).ListRuns), arg0, arg1)
+}
+
+// RetryRun mocks base method.
+func (m *MockService) RetryRun(arg0 context.Context, arg1 *entities.Run) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryRun", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryRun indicates an expected call of RetryRun.
+func (mr *MockServiceMockRecorder) RetryRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryRun", reflect.TypeOf((*MockService)(nil).RetryRun), arg0, arg1)
+}
+
+// StartPipeline mocks base method.
+func (m *MockService) StartPipeline(arg0 context.Context, arg1 *entities.Pipeline) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartPipeline", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartPipeline indicates an expected call of StartPipeline.
+func (mr *MockServiceMockRecorder) StartPipeline(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartPipeline", reflect.TypeOf((*MockService)(nil).StartPipeline), arg0, arg1)
+}
+
+// StartRun mocks base method.
+func (m *MockService) StartRun(arg0 context.Context, arg1 *entities.Run) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartRun", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartRun indicates an expected call of StartRun.
+func (mr *MockServiceMockRecorder) StartRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartRun", reflect.TypeOf((*MockService)(nil).StartRun), arg0, arg1)
+}
+
+// SuspendRun mocks base method.
+func (m *MockService) SuspendRun(arg0 context.Context, arg1 *entities.Run) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuspendRun", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SuspendRun indicates an expected call of SuspendRun.
+func (mr *MockServiceMockRecorder) SuspendRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuspendRun", reflect.TypeOf((*MockService)(nil).SuspendRun), arg0, arg1)
+}
+
 // Unload mocks base method.
 func (m *MockService) Unload(arg0 context.Context, arg1 string) (*entities.Command, error) {
 	m.ctrl.T.Helper()
@@ -107,3 +310,33 @@ func (mr *MockServiceMockRecorder) Unload(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unload", reflect.TypeOf((*MockService)(nil).Unload), arg0, arg1)
 }
+
+// UnloadPipeline mocks base method.
+func (m *MockService) UnloadPipeline(arg0 context.Context, arg1 int) (*entities.Pipeline, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnloadPipeline", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Pipeline)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnloadPipeline indicates an expected call of UnloadPipeline.
+func (mr *MockServiceMockRecorder) UnloadPipeline(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnloadPipeline", reflect.TypeOf((*MockService)(nil).UnloadPipeline), arg0, arg1)
+}
+
+// UnloadRun mocks base method.
+func (m *MockService) UnloadRun(arg0 context.Context, arg1 int) (*entities.Run, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnloadRun", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Run)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnloadRun indicates an expected call of UnloadRun.
+func (mr *MockServiceMockRecorder) UnloadRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnloadRun", reflect.TypeOf((*MockService)(nil).UnloadRun), arg0, arg1)
+}
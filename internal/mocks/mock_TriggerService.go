@@ -0,0 +1,155 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/pavlegich/scripts-hub/internal/service/trigger (interfaces: Service)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entities "github.com/pavlegich/scripts-hub/internal/entities"
+)
+
+// MockTriggerService is a mock of Service interface.
+type MockTriggerService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTriggerServiceMockRecorder
+}
+
+// MockTriggerServiceMockRecorder is the mock recorder for MockTriggerService.
+type MockTriggerServiceMockRecorder struct {
+	mock *MockTriggerService
+}
+
+// NewMockTriggerService creates a new mock instance.
+func NewMockTriggerService(ctrl *gomock.Controller) *MockTriggerService {
+	mock := &MockTriggerService{ctrl: ctrl}
+	mock.recorder = &MockTriggerServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTriggerService) EXPECT() *MockTriggerServiceMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockTriggerService) Authorize(arg0 context.Context, arg1 int, arg2 string) (*entities.Trigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entities.Trigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockTriggerServiceMockRecorder) Authorize(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockTriggerService)(nil).Authorize), arg0, arg1, arg2)
+}
+
+// Create mocks base method.
+func (m *MockTriggerService) Create(arg0 context.Context, arg1 *entities.Trigger) (*entities.Trigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Trigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTriggerServiceMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTriggerService)(nil).Create), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockTriggerService) List(arg0 context.Context, arg1 string) ([]*entities.Trigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]*entities.Trigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTriggerServiceMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTriggerService)(nil).List), arg0, arg1)
+}
+
+// Listen mocks base method.
+func (m *MockTriggerService) Listen(arg0 context.Context, arg1 []string, arg2 chan<- *entities.Notification) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Listen", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Listen indicates an expected call of Listen.
+func (mr *MockTriggerServiceMockRecorder) Listen(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockTriggerService)(nil).Listen), arg0, arg1, arg2)
+}
+
+// Notifies mocks base method.
+func (m *MockTriggerService) Notifies(arg0 context.Context) ([]*entities.Trigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notifies", arg0)
+	ret0, _ := ret[0].([]*entities.Trigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Notifies indicates an expected call of Notifies.
+func (mr *MockTriggerServiceMockRecorder) Notifies(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notifies", reflect.TypeOf((*MockTriggerService)(nil).Notifies), arg0)
+}
+
+// Revoke mocks base method.
+func (m *MockTriggerService) Revoke(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockTriggerServiceMockRecorder) Revoke(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockTriggerService)(nil).Revoke), arg0, arg1)
+}
+
+// Rotate mocks base method.
+func (m *MockTriggerService) Rotate(arg0 context.Context, arg1 int) (*entities.Trigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", arg0, arg1)
+	ret0, _ := ret[0].(*entities.Trigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *MockTriggerServiceMockRecorder) Rotate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*MockTriggerService)(nil).Rotate), arg0, arg1)
+}
+
+// Watches mocks base method.
+func (m *MockTriggerService) Watches(arg0 context.Context) ([]*entities.Trigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watches", arg0)
+	ret0, _ := ret[0].([]*entities.Trigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watches indicates an expected call of Watches.
+func (mr *MockTriggerServiceMockRecorder) Watches(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watches", reflect.TypeOf((*MockTriggerService)(nil).Watches), arg0)
+}
@@ -7,6 +7,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -24,6 +27,61 @@ type Repository interface {
 	GetCommandByName(ctx context.Context, name string) (*entities.Command, error)
 	AppendCommandOutputByName(ctx context.Context, command *entities.Command) error
 	DeleteCommandByName(ctx context.Context, name string) error
+
+	CreateRun(ctx context.Context, run *entities.Run) (*entities.Run, error)
+	StartRun(ctx context.Context, run *entities.Run) error
+	FinishRun(ctx context.Context, run *entities.Run) error
+	AppendRunOutput(ctx context.Context, run *entities.Run) error
+	GetRunsByCommandName(ctx context.Context, name string) ([]*entities.Run, error)
+	GetRunByID(ctx context.Context, id int) (*entities.Run, error)
+	GetPendingRuns(ctx context.Context) ([]*entities.Run, error)
+	GetDueRuns(ctx context.Context, now time.Time) ([]*entities.Run, error)
+	MoveRunStatus(ctx context.Context, run *entities.Run, from string) (bool, error)
+	RetryRun(ctx context.Context, run *entities.Run) error
+	SuspendRun(ctx context.Context, run *entities.Run) error
+	GetRunAttempts(ctx context.Context, runID int) ([]*entities.Attempt, error)
+
+	CreatePipeline(ctx context.Context, pipeline *entities.Pipeline) (*entities.Pipeline, error)
+	UpdatePipeline(ctx context.Context, pipeline *entities.Pipeline) error
+	GetPipelineByID(ctx context.Context, id int) (*entities.Pipeline, error)
+
+	CreateSchedule(ctx context.Context, schedule *entities.Schedule) (*entities.Schedule, error)
+	GetAllSchedules(ctx context.Context) ([]*entities.Schedule, error)
+	GetDueSchedules(ctx context.Context, now time.Time) ([]*entities.Schedule, error)
+	MoveScheduleNextRun(ctx context.Context, schedule *entities.Schedule, next time.Time) (bool, error)
+	DeleteScheduleByName(ctx context.Context, name string) error
+
+	CreateWorkflow(ctx context.Context, workflow *entities.Workflow) (*entities.Workflow, error)
+	GetAllWorkflows(ctx context.Context) ([]*entities.Workflow, error)
+	GetWorkflowByName(ctx context.Context, name string) (*entities.Workflow, error)
+	DeleteWorkflowByName(ctx context.Context, name string) error
+	CreateWorkflowRun(ctx context.Context, run *entities.WorkflowRun) (*entities.WorkflowRun, error)
+	UpdateWorkflowNodeRun(ctx context.Context, runID int, node *entities.NodeRun) error
+	FinishWorkflowRun(ctx context.Context, run *entities.WorkflowRun) error
+	GetWorkflowRunByID(ctx context.Context, id int) (*entities.WorkflowRun, error)
+
+	CreateDeliveries(ctx context.Context, deliveries []*entities.Delivery) error
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery *entities.Delivery) error
+	GetDeadDeliveries(ctx context.Context) ([]*entities.Delivery, error)
+	ReplayDelivery(ctx context.Context, id int, now time.Time) (bool, error)
+
+	CreateTrigger(ctx context.Context, trigger *entities.Trigger) (*entities.Trigger, error)
+	GetTriggersByCommandName(ctx context.Context, name string) ([]*entities.Trigger, error)
+	GetTriggerByID(ctx context.Context, id int) (*entities.Trigger, error)
+	GetActiveTriggers(ctx context.Context, triggerType string) ([]*entities.Trigger, error)
+	Listen(ctx context.Context, channels []string, notifications chan<- *entities.Notification) (bool, error)
+
+	CreateRule(ctx context.Context, rule *entities.Rule) (*entities.Rule, error)
+	GetAllRules(ctx context.Context) ([]*entities.Rule, error)
+	GetEnabledRules(ctx context.Context, name string, event string) ([]*entities.Rule, error)
+	GetRuleByID(ctx context.Context, id int) (*entities.Rule, error)
+	SetRuleEnabled(ctx context.Context, id int, enabled bool) error
+	RotateTriggerToken(ctx context.Context, trigger *entities.Trigger) error
+	RevokeTrigger(ctx context.Context, id int, now time.Time) error
+
+	Ping(ctx context.Context) error
+	GetMigrationVersion(ctx context.Context) (int64, error)
 }
 
 // CommandRepository contains storage objects for storing the commands.
@@ -40,8 +98,12 @@ func NewCommandRepository(ctx context.Context, db *sql.DB) *CommandRepository {
 
 // CreateCommand stores new command into the storage.
 func (r *CommandRepository) CreateCommand(ctx context.Context, c *entities.Command) (*entities.Command, error) {
-	row := r.db.QueryRowContext(ctx, `INSERT INTO commands (name, script) 
-	VALUES ($1, $2) RETURNING id`, c.Name, c.Script)
+	retry := newRetryColumns(c.Retry)
+	row := r.db.QueryRowContext(ctx, `INSERT INTO commands (name, script, priority, queue, groups, 
+	max_attempts, retry_backoff, retry_max_backoff, retry_exit_codes, webhooks, detached, labels, host, executor) 
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id`, c.Name, c.Script, c.Priority, c.Queue,
+		strings.Join(c.Groups, ","), retry.maxAttempts, retry.backoff, retry.maxBackoff, retry.exitCodes,
+		strings.Join(c.Webhooks, " "), c.Detached, strings.Join(c.Labels, ","), c.Host, c.Executor)
 
 	var id int
 	err := row.Scan(&id)
@@ -66,7 +128,8 @@ func (r *CommandRepository) CreateCommand(ctx context.Context, c *entities.Comma
 
 // GetAllCommands gets and returns all the commands from the storage.
 func (r *CommandRepository) GetAllCommands(ctx context.Context) ([]*entities.Command, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id, name, script, output FROM commands`)
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, script, output, priority, queue, groups, 
+	max_attempts, retry_backoff, retry_max_backoff, retry_exit_codes, webhooks, detached, labels, host, executor FROM commands`)
 	if err != nil {
 		return nil, fmt.Errorf("GetAllCommands: read rows from table failed %w", err)
 	}
@@ -75,10 +138,17 @@ func (r *CommandRepository) GetAllCommands(ctx context.Context) ([]*entities.Com
 	cmdsList := make([]*entities.Command, 0)
 	for rows.Next() {
 		var c entities.Command
-		err = rows.Scan(&c.ID, &c.Name, &c.Script, &c.Output)
+		var groups, webhooks, labels string
+		var retry retryColumns
+		err = rows.Scan(&c.ID, &c.Name, &c.Script, &c.Output, &c.Priority, &c.Queue, &groups,
+			&retry.maxAttempts, &retry.backoff, &retry.maxBackoff, &retry.exitCodes, &webhooks, &c.Detached, &labels, &c.Host, &c.Executor)
 		if err != nil {
 			return nil, fmt.Errorf("GetAllCommands: scan row failed %w", err)
 		}
+		c.Groups = splitList(groups)
+		c.Webhooks = splitURLs(webhooks)
+		c.Labels = splitList(labels)
+		c.Retry = retry.policy()
 		cmdsList = append(cmdsList, &c)
 	}
 
@@ -96,16 +166,24 @@ func (r *CommandRepository) GetAllCommands(ctx context.Context) ([]*entities.Com
 
 // GetCommandByName gets and returns the requested by name command from the storage.
 func (r *CommandRepository) GetCommandByName(ctx context.Context, name string) (*entities.Command, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, name, script, output FROM commands WHERE name = $1`, name)
+	row := r.db.QueryRowContext(ctx, `SELECT id, name, script, output, priority, queue, groups, 
+	max_attempts, retry_backoff, retry_max_backoff, retry_exit_codes, webhooks, detached, labels, host, executor FROM commands WHERE name = $1`, name)
 
 	var c entities.Command
-	err := row.Scan(&c.ID, &c.Name, &c.Script, &c.Output)
+	var groups, webhooks, labels string
+	var retry retryColumns
+	err := row.Scan(&c.ID, &c.Name, &c.Script, &c.Output, &c.Priority, &c.Queue, &groups,
+		&retry.maxAttempts, &retry.backoff, &retry.maxBackoff, &retry.exitCodes, &webhooks, &c.Detached, &labels, &c.Host, &c.Executor)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("GetCommandByName: nothing to get, %w", errs.ErrCmdNotFound)
 		}
 		return nil, fmt.Errorf("GetCommandByName: scan row failed %w", err)
 	}
+	c.Groups = splitList(groups)
+	c.Retry = retry.policy()
+	c.Webhooks = splitURLs(webhooks)
+	c.Labels = splitList(labels)
 
 	err = row.Err()
 	if err != nil {
@@ -169,3 +247,70 @@ func (r *CommandRepository) DeleteCommandByName(ctx context.Context, name string
 
 	return nil
 }
+
+// splitList splits the comma separated values stored in the table.
+func splitList(groups string) []string {
+	if groups == "" {
+		return nil
+	}
+	return strings.Split(groups, ",")
+}
+
+// splitURLs splits the space separated webhook URLs stored in the table.
+func splitURLs(urls string) []string {
+	fields := strings.Fields(urls)
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// retryColumns contains the retry policy of the command as it is stored in the table.
+type retryColumns struct {
+	maxAttempts int
+	backoff     string
+	maxBackoff  string
+	exitCodes   string
+}
+
+// newRetryColumns converts the retry policy of the command into the table columns,
+// the command without retry policy has only one attempt.
+func newRetryColumns(p *entities.RetryPolicy) retryColumns {
+	if p == nil {
+		return retryColumns{maxAttempts: 1}
+	}
+
+	codes := make([]string, 0, len(p.ExitCodes))
+	for _, code := range p.ExitCodes {
+		codes = append(codes, strconv.Itoa(code))
+	}
+
+	return retryColumns{
+		maxAttempts: p.MaxAttempts,
+		backoff:     p.Backoff,
+		maxBackoff:  p.MaxBackoff,
+		exitCodes:   strings.Join(codes, ","),
+	}
+}
+
+// policy returns the retry policy stored in the table columns
+// or nil if the command has only one attempt.
+func (c retryColumns) policy() *entities.RetryPolicy {
+	if c.maxAttempts <= 1 {
+		return nil
+	}
+
+	p := &entities.RetryPolicy{
+		MaxAttempts: c.maxAttempts,
+		Backoff:     c.backoff,
+		MaxBackoff:  c.maxBackoff,
+	}
+	for _, code := range splitList(c.exitCodes) {
+		n, err := strconv.Atoi(code)
+		if err == nil {
+			p.ExitCodes = append(p.ExitCodes, n)
+		}
+	}
+
+	return p
+}
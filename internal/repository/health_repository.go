@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// Ping checks the connection with the storage.
+func (r *CommandRepository) Ping(ctx context.Context) error {
+	err := r.db.PingContext(ctx)
+	if err != nil {
+		return fmt.Errorf("Ping: ping database failed %w", err)
+	}
+
+	return nil
+}
+
+// GetMigrationVersion returns the version of the latest applied migration,
+// the version rolled back by the later down migration is not considered applied.
+func (r *CommandRepository) GetMigrationVersion(ctx context.Context) (int64, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version_id), 0) FROM (
+		SELECT DISTINCT ON (version_id) version_id, is_applied FROM goose_db_version ORDER BY version_id, id DESC
+	) v WHERE is_applied`)
+
+	var version int64
+	err := row.Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("GetMigrationVersion: scan row failed %w", err)
+	}
+
+	err = row.Err()
+	if err != nil {
+		return 0, fmt.Errorf("GetMigrationVersion: row.Err %w", err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+)
+
+// listenLockKey is the key of the advisory lock held by the server
+// listening the channels of the notify triggers.
+const listenLockKey = 7402391
+
+// Listen listens the channels on the dedicated connection and sends the received
+// notifications until the context is done or the connection fails. Only one server
+// listens the channels at a time, so it returns false without listening
+// if the listener lock is held by another server.
+func (r *CommandRepository) Listen(ctx context.Context, channels []string, notifications chan<- *entities.Notification) (bool, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("Listen: get connection failed %w", err)
+	}
+	defer conn.Close()
+
+	var locked bool
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, listenLockKey).Scan(&locked)
+	if err != nil {
+		return false, fmt.Errorf("Listen: try listener lock failed %w", err)
+	}
+	if !locked {
+		return false, nil
+	}
+	defer func() {
+		// The connection is returned to the pool, so the session state is cleared.
+		conn.ExecContext(context.Background(), `UNLISTEN *`)
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, listenLockKey)
+	}()
+
+	for _, ch := range channels {
+		_, err = conn.ExecContext(ctx, `LISTEN `+pgx.Identifier{ch}.Sanitize())
+		if err != nil {
+			return true, fmt.Errorf("Listen: listen channel %s failed %w", ch, err)
+		}
+	}
+
+	for {
+		var n *entities.Notification
+		err = conn.Raw(func(driverConn any) error {
+			pgConn, ok := driverConn.(*stdlib.Conn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", driverConn)
+			}
+
+			received, err := pgConn.Conn().WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+			n = &entities.Notification{Channel: received.Channel, Payload: received.Payload}
+			return nil
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return true, nil
+			}
+			return true, fmt.Errorf("Listen: wait notification failed %w", err)
+		}
+
+		select {
+		case notifications <- n:
+		case <-ctx.Done():
+			return true, nil
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+)
+
+// CreatePipeline stores new pipeline with the runs of its steps into the storage.
+func (r *CommandRepository) CreatePipeline(ctx context.Context, p *entities.Pipeline) (*entities.Pipeline, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePipeline: begin transaction failed %w", err)
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, `INSERT INTO pipelines (status) VALUES ($1) RETURNING id, created_at`, p.Status)
+	err = row.Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePipeline: scan row failed %w", err)
+	}
+
+	for i, run := range p.Steps {
+		row = tx.QueryRowContext(ctx, `INSERT INTO runs (command_id, trigger, request_id, status) 
+		VALUES ($1, $2, $3, $4) RETURNING id, created_at`, run.CommandID, run.Trigger, run.RequestID, run.Status)
+		err = row.Scan(&run.ID, &run.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("CreatePipeline: scan step %d run failed %w", i, err)
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO pipeline_steps (pipeline_id, step, run_id) 
+		VALUES ($1, $2, $3)`, p.ID, i, run.ID)
+		if err != nil {
+			return nil, fmt.Errorf("CreatePipeline: insert step %d failed %w", i, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("CreatePipeline: commit transaction failed %w", err)
+	}
+
+	return p, nil
+}
+
+// UpdatePipeline stores the status and finish time of the pipeline.
+func (r *CommandRepository) UpdatePipeline(ctx context.Context, p *entities.Pipeline) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE pipelines SET status = $1, finished_at = $2 WHERE id = $3`,
+		p.Status, p.FinishedAt, p.ID)
+	if err != nil {
+		return fmt.Errorf("UpdatePipeline: update pipeline failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("UpdatePipeline: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("UpdatePipeline: nothing to update, %w", errs.ErrPipelineNotFound)
+	}
+
+	return nil
+}
+
+// GetPipelineByID gets and returns the requested pipeline with the runs
+// of its steps from the storage.
+func (r *CommandRepository) GetPipelineByID(ctx context.Context, id int) (*entities.Pipeline, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT id, status, created_at, finished_at FROM pipelines WHERE id = $1`, id)
+
+	var p entities.Pipeline
+	var finishedAt sql.NullTime
+	err := row.Scan(&p.ID, &p.Status, &p.CreatedAt, &finishedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("GetPipelineByID: nothing to get, %w", errs.ErrPipelineNotFound)
+		}
+		return nil, fmt.Errorf("GetPipelineByID: scan row failed %w", err)
+	}
+	if finishedAt.Valid {
+		p.FinishedAt = &finishedAt.Time
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT r.id, r.command_id, c.name, r.trigger, r.trigger_id, r.request_id, r.status, r.attempt, 
+	r.exit_code, r.output, r.created_at, r.run_at, r.started_at, r.finished_at 
+	FROM pipeline_steps s JOIN runs r ON r.id = s.run_id JOIN commands c ON c.id = r.command_id 
+	WHERE s.pipeline_id = $1 ORDER BY s.step`, id)
+	if err != nil {
+		return nil, fmt.Errorf("GetPipelineByID: read rows from table failed %w", err)
+	}
+	defer rows.Close()
+
+	p.Steps, err = scanRuns(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetPipelineByID: %w", err)
+	}
+
+	return &p, nil
+}
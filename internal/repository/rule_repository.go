@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+)
+
+// CreateRule stores new rule into the storage.
+func (r *CommandRepository) CreateRule(ctx context.Context, rule *entities.Rule) (*entities.Rule, error) {
+	row := r.db.QueryRowContext(ctx, `INSERT INTO rules (command_id, event, target_id, enabled) 
+	SELECT c.id, $2, t.id, $4 FROM commands c, commands t WHERE c.name = $1 AND t.name = $3 
+	RETURNING id, created_at`, rule.Command, rule.On, rule.Target, rule.Enabled)
+
+	err := row.Scan(&rule.ID, &rule.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("CreateRule: %w", errs.ErrCmdNotFound)
+		}
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return nil, fmt.Errorf("CreateRule: %w", errs.ErrRuleAlreadyExists)
+		}
+
+		return nil, fmt.Errorf("CreateRule: scan row failed %w", err)
+	}
+
+	err = row.Err()
+	if err != nil {
+		return nil, fmt.Errorf("CreateRule: row.Err %w", err)
+	}
+
+	return rule, nil
+}
+
+// GetAllRules gets and returns all the rules from the storage.
+func (r *CommandRepository) GetAllRules(ctx context.Context) ([]*entities.Rule, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT r.id, c.name, r.event, t.name, r.enabled, r.created_at 
+	FROM rules r JOIN commands c ON c.id = r.command_id JOIN commands t ON t.id = r.target_id 
+	ORDER BY r.id`)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllRules: read rows from table failed %w", err)
+	}
+
+	rules, err := scanRules(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllRules: %w", err)
+	}
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("GetAllRules: nothing to return %w", errs.ErrRuleNotFound)
+	}
+
+	return rules, nil
+}
+
+// GetEnabledRules gets and returns the enabled rules of the command event from the storage.
+func (r *CommandRepository) GetEnabledRules(ctx context.Context, name string, event string) ([]*entities.Rule, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT r.id, c.name, r.event, t.name, r.enabled, r.created_at 
+	FROM rules r JOIN commands c ON c.id = r.command_id JOIN commands t ON t.id = r.target_id 
+	WHERE c.name = $1 AND r.event = $2 AND r.enabled ORDER BY r.id`, name, event)
+	if err != nil {
+		return nil, fmt.Errorf("GetEnabledRules: read rows from table failed %w", err)
+	}
+
+	rules, err := scanRules(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetEnabledRules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// GetRuleByID gets and returns the requested rule from the storage.
+func (r *CommandRepository) GetRuleByID(ctx context.Context, id int) (*entities.Rule, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT r.id, c.name, r.event, t.name, r.enabled, r.created_at 
+	FROM rules r JOIN commands c ON c.id = r.command_id JOIN commands t ON t.id = r.target_id 
+	WHERE r.id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("GetRuleByID: read rows from table failed %w", err)
+	}
+
+	rules, err := scanRules(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetRuleByID: %w", err)
+	}
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("GetRuleByID: nothing to get, %w", errs.ErrRuleNotFound)
+	}
+
+	return rules[0], nil
+}
+
+// SetRuleEnabled enables or disables the rule.
+func (r *CommandRepository) SetRuleEnabled(ctx context.Context, id int, enabled bool) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE rules SET enabled = $1 WHERE id = $2`, enabled, id)
+	if err != nil {
+		return fmt.Errorf("SetRuleEnabled: update rule failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("SetRuleEnabled: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("SetRuleEnabled: nothing to update, %w", errs.ErrRuleNotFound)
+	}
+
+	return nil
+}
+
+// scanRules scans the rules from the rows and closes them.
+func scanRules(rows *sql.Rows) ([]*entities.Rule, error) {
+	defer rows.Close()
+
+	rules := make([]*entities.Rule, 0)
+	for rows.Next() {
+		var rule entities.Rule
+		err := rows.Scan(&rule.ID, &rule.Command, &rule.On, &rule.Target, &rule.Enabled, &rule.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scanRules: scan row failed %w", err)
+		}
+		rules = append(rules, &rule)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanRules: rows.Err %w", err)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,424 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+)
+
+// CreateRun stores new run of the command into the storage.
+func (r *CommandRepository) CreateRun(ctx context.Context, run *entities.Run) (*entities.Run, error) {
+	row := r.db.QueryRowContext(ctx, `INSERT INTO runs (command_id, trigger, trigger_id, request_id, status, run_at) 
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`, run.CommandID, run.Trigger, run.TriggerID, run.RequestID,
+		run.Status, run.RunAt)
+
+	err := row.Scan(&run.ID, &run.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("CreateRun: scan row failed %w", err)
+	}
+
+	err = row.Err()
+	if err != nil {
+		return nil, fmt.Errorf("CreateRun: row.Err %w", err)
+	}
+
+	return run, nil
+}
+
+// StartRun marks the run as running, stores its new attempt and clears the output
+// of the run and of the command, so they contain the output of the latest attempt.
+func (r *CommandRepository) StartRun(ctx context.Context, run *entities.Run) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("StartRun: begin transaction failed %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE runs SET status = $1, started_at = COALESCE(started_at, $2), attempt = $3, 
+	exit_code = NULL, output = ''::bytea WHERE id = $4`, run.Status, run.StartedAt, run.Attempt, run.ID)
+	if err != nil {
+		return fmt.Errorf("StartRun: update run failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("StartRun: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("StartRun: nothing to update, %w", errs.ErrRunNotFound)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO attempts (run_id, attempt, status, started_at) 
+	VALUES ($1, $2, $3, $4)`, run.ID, run.Attempt, run.Status, run.StartedAt)
+	if err != nil {
+		return fmt.Errorf("StartRun: insert attempt failed %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE commands SET output = ''::bytea WHERE id = $1`, run.CommandID)
+	if err != nil {
+		return fmt.Errorf("StartRun: clear command output failed %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("StartRun: commit transaction failed %w", err)
+	}
+
+	return nil
+}
+
+// FinishRun stores the final status, exit code and finish time of the run
+// and of its last attempt.
+func (r *CommandRepository) FinishRun(ctx context.Context, run *entities.Run) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("FinishRun: begin transaction failed %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE runs SET status = $1, exit_code = $2, finished_at = $3 
+	WHERE id = $4`, run.Status, run.ExitCode, run.FinishedAt, run.ID)
+	if err != nil {
+		return fmt.Errorf("FinishRun: update run failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("FinishRun: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("FinishRun: nothing to update, %w", errs.ErrRunNotFound)
+	}
+
+	err = finishAttempt(ctx, tx, run, run.Status)
+	if err != nil {
+		return fmt.Errorf("FinishRun: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("FinishRun: commit transaction failed %w", err)
+	}
+
+	return nil
+}
+
+// RetryRun stores the failed last attempt of the run and marks the run
+// as waiting for the next attempt.
+func (r *CommandRepository) RetryRun(ctx context.Context, run *entities.Run) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("RetryRun: begin transaction failed %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE runs SET status = $1, exit_code = $2 WHERE id = $3`,
+		run.Status, run.ExitCode, run.ID)
+	if err != nil {
+		return fmt.Errorf("RetryRun: update run failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("RetryRun: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("RetryRun: nothing to update, %w", errs.ErrRunNotFound)
+	}
+
+	err = finishAttempt(ctx, tx, run, entities.RunFailed)
+	if err != nil {
+		return fmt.Errorf("RetryRun: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("RetryRun: commit transaction failed %w", err)
+	}
+
+	return nil
+}
+
+// SuspendRun returns the queued or retrying run into the pending runs
+// with the run time of the run, so it is queued again as the delayed run.
+func (r *CommandRepository) SuspendRun(ctx context.Context, run *entities.Run) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE runs SET status = $1, run_at = $2 
+	WHERE id = $3 AND status IN ($4, $5)`, run.Status, run.RunAt, run.ID, entities.RunQueued, entities.RunRetrying)
+	if err != nil {
+		return fmt.Errorf("SuspendRun: update run failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("SuspendRun: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("SuspendRun: nothing to update, %w", errs.ErrRunNotFound)
+	}
+
+	return nil
+}
+
+// finishAttempt stores the status, exit code and finish time of the last attempt of the run.
+func finishAttempt(ctx context.Context, tx *sql.Tx, run *entities.Run, status string) error {
+	if run.Attempt == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `UPDATE attempts SET status = $1, exit_code = $2, finished_at = $3 
+	WHERE run_id = $4 AND attempt = $5`, status, run.ExitCode, run.FinishedAt, run.ID, run.Attempt)
+	if err != nil {
+		return fmt.Errorf("finishAttempt: update attempt failed %w", err)
+	}
+
+	return nil
+}
+
+// AppendRunOutput appends the output to the run and to its command in the storage.
+func (r *CommandRepository) AppendRunOutput(ctx context.Context, run *entities.Run) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("AppendRunOutput: begin transaction failed %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE runs SET output = output || $1::bytea WHERE id = $2`,
+		[]byte(run.Output), run.ID)
+	if err != nil {
+		return fmt.Errorf("AppendRunOutput: update run failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("AppendRunOutput: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("AppendRunOutput: nothing to update, %w", errs.ErrRunNotFound)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE attempts SET output = output || $1::bytea 
+	WHERE run_id = $2 AND attempt = $3`, []byte(run.Output), run.ID, run.Attempt)
+	if err != nil {
+		return fmt.Errorf("AppendRunOutput: update attempt failed %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE commands SET output = output || $1::bytea WHERE id = $2`,
+		[]byte(run.Output), run.CommandID)
+	if err != nil {
+		return fmt.Errorf("AppendRunOutput: update command failed %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("AppendRunOutput: commit transaction failed %w", err)
+	}
+
+	return nil
+}
+
+// GetRunsByCommandName gets and returns the runs of the requested by name command
+// from the storage, the latest runs first.
+func (r *CommandRepository) GetRunsByCommandName(ctx context.Context, name string) ([]*entities.Run, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT r.id, r.command_id, c.name, r.trigger, r.trigger_id, r.request_id, r.status, r.attempt, 
+	r.exit_code, r.output, r.created_at, r.run_at, r.started_at, r.finished_at 
+	FROM runs r JOIN commands c ON c.id = r.command_id 
+	WHERE c.name = $1 ORDER BY r.id DESC`, name)
+	if err != nil {
+		return nil, fmt.Errorf("GetRunsByCommandName: read rows from table failed %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]*entities.Run, 0)
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("GetRunsByCommandName: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("GetRunsByCommandName: rows.Err %w", err)
+	}
+
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("GetRunsByCommandName: nothing to return %w", errs.ErrRunNotFound)
+	}
+
+	return runs, nil
+}
+
+// GetRunByID gets and returns the requested run from the storage.
+func (r *CommandRepository) GetRunByID(ctx context.Context, id int) (*entities.Run, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT r.id, r.command_id, c.name, r.trigger, r.trigger_id, r.request_id, r.status, r.attempt, 
+	r.exit_code, r.output, r.created_at, r.run_at, r.started_at, r.finished_at 
+	FROM runs r JOIN commands c ON c.id = r.command_id WHERE r.id = $1`, id)
+
+	run, err := scanRun(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("GetRunByID: nothing to get, %w", errs.ErrRunNotFound)
+		}
+		return nil, fmt.Errorf("GetRunByID: %w", err)
+	}
+
+	return run, nil
+}
+
+// GetPendingRuns gets and returns the delayed runs waiting for their run time
+// from the storage, the earliest runs first.
+func (r *CommandRepository) GetPendingRuns(ctx context.Context) ([]*entities.Run, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT r.id, r.command_id, c.name, r.trigger, r.trigger_id, r.request_id, r.status, r.attempt, 
+	r.exit_code, r.output, r.created_at, r.run_at, r.started_at, r.finished_at 
+	FROM runs r JOIN commands c ON c.id = r.command_id 
+	WHERE r.status = $1 ORDER BY r.run_at, r.id`, entities.RunPending)
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingRuns: read rows from table failed %w", err)
+	}
+	defer rows.Close()
+
+	runs, err := scanRuns(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingRuns: %w", err)
+	}
+
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("GetPendingRuns: nothing to return %w", errs.ErrRunNotFound)
+	}
+
+	return runs, nil
+}
+
+// GetDueRuns gets and returns the delayed runs which run time has come.
+func (r *CommandRepository) GetDueRuns(ctx context.Context, now time.Time) ([]*entities.Run, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT r.id, r.command_id, c.name, r.trigger, r.trigger_id, r.request_id, r.status, r.attempt, 
+	r.exit_code, r.output, r.created_at, r.run_at, r.started_at, r.finished_at 
+	FROM runs r JOIN commands c ON c.id = r.command_id 
+	WHERE r.status = $1 AND r.run_at <= $2 ORDER BY r.run_at, r.id`, entities.RunPending, now)
+	if err != nil {
+		return nil, fmt.Errorf("GetDueRuns: read rows from table failed %w", err)
+	}
+	defer rows.Close()
+
+	runs, err := scanRuns(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetDueRuns: %w", err)
+	}
+
+	return runs, nil
+}
+
+// MoveRunStatus changes the status of the run only if the run still has
+// the expected status. It returns false if the status was already changed
+// by someone else.
+func (r *CommandRepository) MoveRunStatus(ctx context.Context, run *entities.Run, from string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE runs SET status = $1, finished_at = $2 
+	WHERE id = $3 AND status = $4`, run.Status, run.FinishedAt, run.ID, from)
+	if err != nil {
+		return false, fmt.Errorf("MoveRunStatus: update run failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("MoveRunStatus: couldn't get rows affected %w", err)
+	}
+
+	return rowsCount > 0, nil
+}
+
+// GetRunAttempts gets and returns the attempts of the run from the storage.
+func (r *CommandRepository) GetRunAttempts(ctx context.Context, runID int) ([]*entities.Attempt, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT attempt, status, exit_code, output, started_at, finished_at 
+	FROM attempts WHERE run_id = $1 ORDER BY attempt`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("GetRunAttempts: read rows from table failed %w", err)
+	}
+	defer rows.Close()
+
+	attempts := make([]*entities.Attempt, 0)
+	for rows.Next() {
+		var a entities.Attempt
+		var exitCode sql.NullInt32
+		var finishedAt sql.NullTime
+
+		err = rows.Scan(&a.Number, &a.Status, &exitCode, &a.Output, &a.StartedAt, &finishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("GetRunAttempts: scan row failed %w", err)
+		}
+
+		if exitCode.Valid {
+			code := int(exitCode.Int32)
+			a.ExitCode = &code
+		}
+		if finishedAt.Valid {
+			a.FinishedAt = &finishedAt.Time
+		}
+		attempts = append(attempts, &a)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("GetRunAttempts: rows.Err %w", err)
+	}
+
+	return attempts, nil
+}
+
+// scanner describes the row or rows to scan the values from.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRuns scans all the runs from the rows.
+func scanRuns(rows *sql.Rows) ([]*entities.Run, error) {
+	runs := make([]*entities.Run, 0)
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanRuns: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanRuns: rows.Err %w", err)
+	}
+
+	return runs, nil
+}
+
+// scanRun scans the run from the row.
+func scanRun(row scanner) (*entities.Run, error) {
+	var run entities.Run
+	var exitCode, triggerID sql.NullInt32
+	var runAt, startedAt, finishedAt sql.NullTime
+
+	err := row.Scan(&run.ID, &run.CommandID, &run.Name, &run.Trigger, &triggerID, &run.RequestID, &run.Status, &run.Attempt,
+		&exitCode, &run.Output, &run.CreatedAt, &runAt, &startedAt, &finishedAt)
+	if err != nil {
+		return nil, fmt.Errorf("scanRun: scan row failed %w", err)
+	}
+
+	if exitCode.Valid {
+		code := int(exitCode.Int32)
+		run.ExitCode = &code
+	}
+	if triggerID.Valid {
+		id := int(triggerID.Int32)
+		run.TriggerID = &id
+	}
+	if runAt.Valid {
+		run.RunAt = &runAt.Time
+	}
+	if startedAt.Valid {
+		run.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+
+	return &run, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+)
+
+// CreateSchedule stores new schedule of the command into the storage.
+func (r *CommandRepository) CreateSchedule(ctx context.Context, s *entities.Schedule) (*entities.Schedule, error) {
+	row := r.db.QueryRowContext(ctx, `INSERT INTO schedules (command_id, cron, timezone, overlap, next_run_at) 
+	SELECT id, $2, $3, $4, $5 FROM commands WHERE name = $1 RETURNING id`,
+		s.Name, s.Cron, s.Timezone, s.Overlap, s.NextRunAt)
+
+	var id int
+	err := row.Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("CreateSchedule: %w", errs.ErrCmdNotFound)
+		}
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return nil, fmt.Errorf("CreateSchedule: %w", errs.ErrScheduleAlreadyExists)
+		}
+
+		return nil, fmt.Errorf("CreateSchedule: scan row failed %w", err)
+	}
+
+	s.ID = id
+
+	err = row.Err()
+	if err != nil {
+		return nil, fmt.Errorf("CreateSchedule: row.Err %w", err)
+	}
+
+	return s, nil
+}
+
+// GetAllSchedules gets and returns all the schedules from the storage.
+func (r *CommandRepository) GetAllSchedules(ctx context.Context) ([]*entities.Schedule, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT s.id, c.name, s.cron, s.timezone, s.overlap, s.next_run_at 
+	FROM schedules s JOIN commands c ON c.id = s.command_id ORDER BY c.name`)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllSchedules: read rows from table failed %w", err)
+	}
+
+	schedules, err := scanSchedules(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllSchedules: %w", err)
+	}
+
+	if len(schedules) == 0 {
+		return nil, fmt.Errorf("GetAllSchedules: nothing to return %w", errs.ErrScheduleNotFound)
+	}
+
+	return schedules, nil
+}
+
+// GetDueSchedules gets and returns the schedules which next run time has come.
+func (r *CommandRepository) GetDueSchedules(ctx context.Context, now time.Time) ([]*entities.Schedule, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT s.id, c.name, s.cron, s.timezone, s.overlap, s.next_run_at 
+	FROM schedules s JOIN commands c ON c.id = s.command_id WHERE s.next_run_at <= $1`, now)
+	if err != nil {
+		return nil, fmt.Errorf("GetDueSchedules: read rows from table failed %w", err)
+	}
+
+	schedules, err := scanSchedules(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetDueSchedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// MoveScheduleNextRun sets the next run time of the schedule if it was not
+// changed by someone else. It returns false if the schedule was already moved.
+func (r *CommandRepository) MoveScheduleNextRun(ctx context.Context, s *entities.Schedule, next time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE schedules SET next_run_at = $1 
+	WHERE id = $2 AND next_run_at = $3`, next, s.ID, s.NextRunAt)
+	if err != nil {
+		return false, fmt.Errorf("MoveScheduleNextRun: update schedule failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("MoveScheduleNextRun: couldn't get rows affected %w", err)
+	}
+
+	return rowsCount == 1, nil
+}
+
+// DeleteScheduleByName deletes the schedule of the command from the storage.
+func (r *CommandRepository) DeleteScheduleByName(ctx context.Context, name string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM schedules 
+	WHERE command_id = (SELECT id FROM commands WHERE name = $1)`, name)
+	if err != nil {
+		return fmt.Errorf("DeleteScheduleByName: delete schedule failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("DeleteScheduleByName: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("DeleteScheduleByName: nothing to delete, %w", errs.ErrScheduleNotFound)
+	}
+
+	return nil
+}
+
+// scanSchedules scans the schedules from the rows and closes them.
+func scanSchedules(rows *sql.Rows) ([]*entities.Schedule, error) {
+	defer rows.Close()
+
+	schedules := make([]*entities.Schedule, 0)
+	for rows.Next() {
+		var s entities.Schedule
+		err := rows.Scan(&s.ID, &s.Name, &s.Cron, &s.Timezone, &s.Overlap, &s.NextRunAt)
+		if err != nil {
+			return nil, fmt.Errorf("scanSchedules: scan row failed %w", err)
+		}
+		schedules = append(schedules, &s)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanSchedules: rows.Err %w", err)
+	}
+
+	return schedules, nil
+}
@@ -0,0 +1,537 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/infra/tracing"
+)
+
+// TracedRepository records the span of every call of the repository.
+type TracedRepository struct {
+	Repository
+}
+
+// NewTracedRepository returns the repository recording the spans of the calls.
+// The long-lived Listen calls are not traced.
+func NewTracedRepository(repo Repository) *TracedRepository {
+	return &TracedRepository{
+		Repository: repo,
+	}
+}
+
+// start starts the span of the repository call.
+func start(ctx context.Context, method string) (context.Context, *tracing.Span) {
+	return tracing.Start(ctx, "repository."+method, tracing.KindClient, tracing.String("db.system", "postgresql"))
+}
+
+// CreateCommand records the span of the CreateCommand call.
+func (r *TracedRepository) CreateCommand(ctx context.Context, command *entities.Command) (*entities.Command, error) {
+	ctx, span := start(ctx, "CreateCommand")
+	defer span.End()
+
+	res, err := r.Repository.CreateCommand(ctx, command)
+	span.SetError(err)
+	return res, err
+}
+
+// GetAllCommands records the span of the GetAllCommands call.
+func (r *TracedRepository) GetAllCommands(ctx context.Context) ([]*entities.Command, error) {
+	ctx, span := start(ctx, "GetAllCommands")
+	defer span.End()
+
+	res, err := r.Repository.GetAllCommands(ctx)
+	span.SetError(err)
+	return res, err
+}
+
+// GetCommandByName records the span of the GetCommandByName call.
+func (r *TracedRepository) GetCommandByName(ctx context.Context, name string) (*entities.Command, error) {
+	ctx, span := start(ctx, "GetCommandByName")
+	defer span.End()
+
+	res, err := r.Repository.GetCommandByName(ctx, name)
+	span.SetError(err)
+	return res, err
+}
+
+// AppendCommandOutputByName records the span of the AppendCommandOutputByName call.
+func (r *TracedRepository) AppendCommandOutputByName(ctx context.Context, command *entities.Command) error {
+	ctx, span := start(ctx, "AppendCommandOutputByName")
+	defer span.End()
+
+	err := r.Repository.AppendCommandOutputByName(ctx, command)
+	span.SetError(err)
+	return err
+}
+
+// DeleteCommandByName records the span of the DeleteCommandByName call.
+func (r *TracedRepository) DeleteCommandByName(ctx context.Context, name string) error {
+	ctx, span := start(ctx, "DeleteCommandByName")
+	defer span.End()
+
+	err := r.Repository.DeleteCommandByName(ctx, name)
+	span.SetError(err)
+	return err
+}
+
+// CreateRun records the span of the CreateRun call.
+func (r *TracedRepository) CreateRun(ctx context.Context, run *entities.Run) (*entities.Run, error) {
+	ctx, span := start(ctx, "CreateRun")
+	defer span.End()
+
+	res, err := r.Repository.CreateRun(ctx, run)
+	span.SetError(err)
+	return res, err
+}
+
+// StartRun records the span of the StartRun call.
+func (r *TracedRepository) StartRun(ctx context.Context, run *entities.Run) error {
+	ctx, span := start(ctx, "StartRun")
+	defer span.End()
+
+	err := r.Repository.StartRun(ctx, run)
+	span.SetError(err)
+	return err
+}
+
+// FinishRun records the span of the FinishRun call.
+func (r *TracedRepository) FinishRun(ctx context.Context, run *entities.Run) error {
+	ctx, span := start(ctx, "FinishRun")
+	defer span.End()
+
+	err := r.Repository.FinishRun(ctx, run)
+	span.SetError(err)
+	return err
+}
+
+// AppendRunOutput records the span of the AppendRunOutput call.
+func (r *TracedRepository) AppendRunOutput(ctx context.Context, run *entities.Run) error {
+	ctx, span := start(ctx, "AppendRunOutput")
+	defer span.End()
+
+	err := r.Repository.AppendRunOutput(ctx, run)
+	span.SetError(err)
+	return err
+}
+
+// GetRunsByCommandName records the span of the GetRunsByCommandName call.
+func (r *TracedRepository) GetRunsByCommandName(ctx context.Context, name string) ([]*entities.Run, error) {
+	ctx, span := start(ctx, "GetRunsByCommandName")
+	defer span.End()
+
+	res, err := r.Repository.GetRunsByCommandName(ctx, name)
+	span.SetError(err)
+	return res, err
+}
+
+// GetRunByID records the span of the GetRunByID call.
+func (r *TracedRepository) GetRunByID(ctx context.Context, id int) (*entities.Run, error) {
+	ctx, span := start(ctx, "GetRunByID")
+	defer span.End()
+
+	res, err := r.Repository.GetRunByID(ctx, id)
+	span.SetError(err)
+	return res, err
+}
+
+// GetPendingRuns records the span of the GetPendingRuns call.
+func (r *TracedRepository) GetPendingRuns(ctx context.Context) ([]*entities.Run, error) {
+	ctx, span := start(ctx, "GetPendingRuns")
+	defer span.End()
+
+	res, err := r.Repository.GetPendingRuns(ctx)
+	span.SetError(err)
+	return res, err
+}
+
+// GetDueRuns records the span of the GetDueRuns call.
+func (r *TracedRepository) GetDueRuns(ctx context.Context, now time.Time) ([]*entities.Run, error) {
+	ctx, span := start(ctx, "GetDueRuns")
+	defer span.End()
+
+	res, err := r.Repository.GetDueRuns(ctx, now)
+	span.SetError(err)
+	return res, err
+}
+
+// MoveRunStatus records the span of the MoveRunStatus call.
+func (r *TracedRepository) MoveRunStatus(ctx context.Context, run *entities.Run, from string) (bool, error) {
+	ctx, span := start(ctx, "MoveRunStatus")
+	defer span.End()
+
+	res, err := r.Repository.MoveRunStatus(ctx, run, from)
+	span.SetError(err)
+	return res, err
+}
+
+// RetryRun records the span of the RetryRun call.
+func (r *TracedRepository) RetryRun(ctx context.Context, run *entities.Run) error {
+	ctx, span := start(ctx, "RetryRun")
+	defer span.End()
+
+	err := r.Repository.RetryRun(ctx, run)
+	span.SetError(err)
+	return err
+}
+
+// SuspendRun records the span of the SuspendRun call.
+func (r *TracedRepository) SuspendRun(ctx context.Context, run *entities.Run) error {
+	ctx, span := start(ctx, "SuspendRun")
+	defer span.End()
+
+	err := r.Repository.SuspendRun(ctx, run)
+	span.SetError(err)
+	return err
+}
+
+// GetRunAttempts records the span of the GetRunAttempts call.
+func (r *TracedRepository) GetRunAttempts(ctx context.Context, runID int) ([]*entities.Attempt, error) {
+	ctx, span := start(ctx, "GetRunAttempts")
+	defer span.End()
+
+	res, err := r.Repository.GetRunAttempts(ctx, runID)
+	span.SetError(err)
+	return res, err
+}
+
+// CreatePipeline records the span of the CreatePipeline call.
+func (r *TracedRepository) CreatePipeline(ctx context.Context, pipeline *entities.Pipeline) (*entities.Pipeline, error) {
+	ctx, span := start(ctx, "CreatePipeline")
+	defer span.End()
+
+	res, err := r.Repository.CreatePipeline(ctx, pipeline)
+	span.SetError(err)
+	return res, err
+}
+
+// UpdatePipeline records the span of the UpdatePipeline call.
+func (r *TracedRepository) UpdatePipeline(ctx context.Context, pipeline *entities.Pipeline) error {
+	ctx, span := start(ctx, "UpdatePipeline")
+	defer span.End()
+
+	err := r.Repository.UpdatePipeline(ctx, pipeline)
+	span.SetError(err)
+	return err
+}
+
+// GetPipelineByID records the span of the GetPipelineByID call.
+func (r *TracedRepository) GetPipelineByID(ctx context.Context, id int) (*entities.Pipeline, error) {
+	ctx, span := start(ctx, "GetPipelineByID")
+	defer span.End()
+
+	res, err := r.Repository.GetPipelineByID(ctx, id)
+	span.SetError(err)
+	return res, err
+}
+
+// CreateSchedule records the span of the CreateSchedule call.
+func (r *TracedRepository) CreateSchedule(ctx context.Context, schedule *entities.Schedule) (*entities.Schedule, error) {
+	ctx, span := start(ctx, "CreateSchedule")
+	defer span.End()
+
+	res, err := r.Repository.CreateSchedule(ctx, schedule)
+	span.SetError(err)
+	return res, err
+}
+
+// GetAllSchedules records the span of the GetAllSchedules call.
+func (r *TracedRepository) GetAllSchedules(ctx context.Context) ([]*entities.Schedule, error) {
+	ctx, span := start(ctx, "GetAllSchedules")
+	defer span.End()
+
+	res, err := r.Repository.GetAllSchedules(ctx)
+	span.SetError(err)
+	return res, err
+}
+
+// GetDueSchedules records the span of the GetDueSchedules call.
+func (r *TracedRepository) GetDueSchedules(ctx context.Context, now time.Time) ([]*entities.Schedule, error) {
+	ctx, span := start(ctx, "GetDueSchedules")
+	defer span.End()
+
+	res, err := r.Repository.GetDueSchedules(ctx, now)
+	span.SetError(err)
+	return res, err
+}
+
+// MoveScheduleNextRun records the span of the MoveScheduleNextRun call.
+func (r *TracedRepository) MoveScheduleNextRun(ctx context.Context, schedule *entities.Schedule, next time.Time) (bool, error) {
+	ctx, span := start(ctx, "MoveScheduleNextRun")
+	defer span.End()
+
+	res, err := r.Repository.MoveScheduleNextRun(ctx, schedule, next)
+	span.SetError(err)
+	return res, err
+}
+
+// DeleteScheduleByName records the span of the DeleteScheduleByName call.
+func (r *TracedRepository) DeleteScheduleByName(ctx context.Context, name string) error {
+	ctx, span := start(ctx, "DeleteScheduleByName")
+	defer span.End()
+
+	err := r.Repository.DeleteScheduleByName(ctx, name)
+	span.SetError(err)
+	return err
+}
+
+// CreateWorkflow records the span of the CreateWorkflow call.
+func (r *TracedRepository) CreateWorkflow(ctx context.Context, workflow *entities.Workflow) (*entities.Workflow, error) {
+	ctx, span := start(ctx, "CreateWorkflow")
+	defer span.End()
+
+	res, err := r.Repository.CreateWorkflow(ctx, workflow)
+	span.SetError(err)
+	return res, err
+}
+
+// GetAllWorkflows records the span of the GetAllWorkflows call.
+func (r *TracedRepository) GetAllWorkflows(ctx context.Context) ([]*entities.Workflow, error) {
+	ctx, span := start(ctx, "GetAllWorkflows")
+	defer span.End()
+
+	res, err := r.Repository.GetAllWorkflows(ctx)
+	span.SetError(err)
+	return res, err
+}
+
+// GetWorkflowByName records the span of the GetWorkflowByName call.
+func (r *TracedRepository) GetWorkflowByName(ctx context.Context, name string) (*entities.Workflow, error) {
+	ctx, span := start(ctx, "GetWorkflowByName")
+	defer span.End()
+
+	res, err := r.Repository.GetWorkflowByName(ctx, name)
+	span.SetError(err)
+	return res, err
+}
+
+// DeleteWorkflowByName records the span of the DeleteWorkflowByName call.
+func (r *TracedRepository) DeleteWorkflowByName(ctx context.Context, name string) error {
+	ctx, span := start(ctx, "DeleteWorkflowByName")
+	defer span.End()
+
+	err := r.Repository.DeleteWorkflowByName(ctx, name)
+	span.SetError(err)
+	return err
+}
+
+// CreateWorkflowRun records the span of the CreateWorkflowRun call.
+func (r *TracedRepository) CreateWorkflowRun(ctx context.Context, run *entities.WorkflowRun) (*entities.WorkflowRun, error) {
+	ctx, span := start(ctx, "CreateWorkflowRun")
+	defer span.End()
+
+	res, err := r.Repository.CreateWorkflowRun(ctx, run)
+	span.SetError(err)
+	return res, err
+}
+
+// UpdateWorkflowNodeRun records the span of the UpdateWorkflowNodeRun call.
+func (r *TracedRepository) UpdateWorkflowNodeRun(ctx context.Context, runID int, node *entities.NodeRun) error {
+	ctx, span := start(ctx, "UpdateWorkflowNodeRun")
+	defer span.End()
+
+	err := r.Repository.UpdateWorkflowNodeRun(ctx, runID, node)
+	span.SetError(err)
+	return err
+}
+
+// FinishWorkflowRun records the span of the FinishWorkflowRun call.
+func (r *TracedRepository) FinishWorkflowRun(ctx context.Context, run *entities.WorkflowRun) error {
+	ctx, span := start(ctx, "FinishWorkflowRun")
+	defer span.End()
+
+	err := r.Repository.FinishWorkflowRun(ctx, run)
+	span.SetError(err)
+	return err
+}
+
+// GetWorkflowRunByID records the span of the GetWorkflowRunByID call.
+func (r *TracedRepository) GetWorkflowRunByID(ctx context.Context, id int) (*entities.WorkflowRun, error) {
+	ctx, span := start(ctx, "GetWorkflowRunByID")
+	defer span.End()
+
+	res, err := r.Repository.GetWorkflowRunByID(ctx, id)
+	span.SetError(err)
+	return res, err
+}
+
+// CreateDeliveries records the span of the CreateDeliveries call.
+func (r *TracedRepository) CreateDeliveries(ctx context.Context, deliveries []*entities.Delivery) error {
+	ctx, span := start(ctx, "CreateDeliveries")
+	defer span.End()
+
+	err := r.Repository.CreateDeliveries(ctx, deliveries)
+	span.SetError(err)
+	return err
+}
+
+// ClaimDueDeliveries records the span of the ClaimDueDeliveries call.
+func (r *TracedRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.Delivery, error) {
+	ctx, span := start(ctx, "ClaimDueDeliveries")
+	defer span.End()
+
+	res, err := r.Repository.ClaimDueDeliveries(ctx, now, lease, limit)
+	span.SetError(err)
+	return res, err
+}
+
+// UpdateDelivery records the span of the UpdateDelivery call.
+func (r *TracedRepository) UpdateDelivery(ctx context.Context, delivery *entities.Delivery) error {
+	ctx, span := start(ctx, "UpdateDelivery")
+	defer span.End()
+
+	err := r.Repository.UpdateDelivery(ctx, delivery)
+	span.SetError(err)
+	return err
+}
+
+// GetDeadDeliveries records the span of the GetDeadDeliveries call.
+func (r *TracedRepository) GetDeadDeliveries(ctx context.Context) ([]*entities.Delivery, error) {
+	ctx, span := start(ctx, "GetDeadDeliveries")
+	defer span.End()
+
+	res, err := r.Repository.GetDeadDeliveries(ctx)
+	span.SetError(err)
+	return res, err
+}
+
+// ReplayDelivery records the span of the ReplayDelivery call.
+func (r *TracedRepository) ReplayDelivery(ctx context.Context, id int, now time.Time) (bool, error) {
+	ctx, span := start(ctx, "ReplayDelivery")
+	defer span.End()
+
+	res, err := r.Repository.ReplayDelivery(ctx, id, now)
+	span.SetError(err)
+	return res, err
+}
+
+// CreateTrigger records the span of the CreateTrigger call.
+func (r *TracedRepository) CreateTrigger(ctx context.Context, trigger *entities.Trigger) (*entities.Trigger, error) {
+	ctx, span := start(ctx, "CreateTrigger")
+	defer span.End()
+
+	res, err := r.Repository.CreateTrigger(ctx, trigger)
+	span.SetError(err)
+	return res, err
+}
+
+// GetTriggersByCommandName records the span of the GetTriggersByCommandName call.
+func (r *TracedRepository) GetTriggersByCommandName(ctx context.Context, name string) ([]*entities.Trigger, error) {
+	ctx, span := start(ctx, "GetTriggersByCommandName")
+	defer span.End()
+
+	res, err := r.Repository.GetTriggersByCommandName(ctx, name)
+	span.SetError(err)
+	return res, err
+}
+
+// GetTriggerByID records the span of the GetTriggerByID call.
+func (r *TracedRepository) GetTriggerByID(ctx context.Context, id int) (*entities.Trigger, error) {
+	ctx, span := start(ctx, "GetTriggerByID")
+	defer span.End()
+
+	res, err := r.Repository.GetTriggerByID(ctx, id)
+	span.SetError(err)
+	return res, err
+}
+
+// GetActiveTriggers records the span of the GetActiveTriggers call.
+func (r *TracedRepository) GetActiveTriggers(ctx context.Context, triggerType string) ([]*entities.Trigger, error) {
+	ctx, span := start(ctx, "GetActiveTriggers")
+	defer span.End()
+
+	res, err := r.Repository.GetActiveTriggers(ctx, triggerType)
+	span.SetError(err)
+	return res, err
+}
+
+// CreateRule records the span of the CreateRule call.
+func (r *TracedRepository) CreateRule(ctx context.Context, rule *entities.Rule) (*entities.Rule, error) {
+	ctx, span := start(ctx, "CreateRule")
+	defer span.End()
+
+	res, err := r.Repository.CreateRule(ctx, rule)
+	span.SetError(err)
+	return res, err
+}
+
+// GetAllRules records the span of the GetAllRules call.
+func (r *TracedRepository) GetAllRules(ctx context.Context) ([]*entities.Rule, error) {
+	ctx, span := start(ctx, "GetAllRules")
+	defer span.End()
+
+	res, err := r.Repository.GetAllRules(ctx)
+	span.SetError(err)
+	return res, err
+}
+
+// GetEnabledRules records the span of the GetEnabledRules call.
+func (r *TracedRepository) GetEnabledRules(ctx context.Context, name string, event string) ([]*entities.Rule, error) {
+	ctx, span := start(ctx, "GetEnabledRules")
+	defer span.End()
+
+	res, err := r.Repository.GetEnabledRules(ctx, name, event)
+	span.SetError(err)
+	return res, err
+}
+
+// GetRuleByID records the span of the GetRuleByID call.
+func (r *TracedRepository) GetRuleByID(ctx context.Context, id int) (*entities.Rule, error) {
+	ctx, span := start(ctx, "GetRuleByID")
+	defer span.End()
+
+	res, err := r.Repository.GetRuleByID(ctx, id)
+	span.SetError(err)
+	return res, err
+}
+
+// SetRuleEnabled records the span of the SetRuleEnabled call.
+func (r *TracedRepository) SetRuleEnabled(ctx context.Context, id int, enabled bool) error {
+	ctx, span := start(ctx, "SetRuleEnabled")
+	defer span.End()
+
+	err := r.Repository.SetRuleEnabled(ctx, id, enabled)
+	span.SetError(err)
+	return err
+}
+
+// RotateTriggerToken records the span of the RotateTriggerToken call.
+func (r *TracedRepository) RotateTriggerToken(ctx context.Context, trigger *entities.Trigger) error {
+	ctx, span := start(ctx, "RotateTriggerToken")
+	defer span.End()
+
+	err := r.Repository.RotateTriggerToken(ctx, trigger)
+	span.SetError(err)
+	return err
+}
+
+// RevokeTrigger records the span of the RevokeTrigger call.
+func (r *TracedRepository) RevokeTrigger(ctx context.Context, id int, now time.Time) error {
+	ctx, span := start(ctx, "RevokeTrigger")
+	defer span.End()
+
+	err := r.Repository.RevokeTrigger(ctx, id, now)
+	span.SetError(err)
+	return err
+}
+
+// Ping records the span of the Ping call.
+func (r *TracedRepository) Ping(ctx context.Context) error {
+	ctx, span := start(ctx, "Ping")
+	defer span.End()
+
+	err := r.Repository.Ping(ctx)
+	span.SetError(err)
+	return err
+}
+
+// GetMigrationVersion records the span of the GetMigrationVersion call.
+func (r *TracedRepository) GetMigrationVersion(ctx context.Context) (int64, error) {
+	ctx, span := start(ctx, "GetMigrationVersion")
+	defer span.End()
+
+	res, err := r.Repository.GetMigrationVersion(ctx)
+	span.SetError(err)
+	return res, err
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+)
+
+// CreateTrigger stores new trigger of the command into the storage.
+func (r *CommandRepository) CreateTrigger(ctx context.Context, t *entities.Trigger) (*entities.Trigger, error) {
+	row := r.db.QueryRowContext(ctx, `INSERT INTO triggers (command_id, type, input, token_hash, 
+	path, glob, debounce, concurrency, pass, channel) 
+	SELECT id, $2, $3, $4, $5, $6, $7, $8, $9, $10 FROM commands WHERE name = $1 RETURNING id, created_at`,
+		t.Name, t.Type, t.Input, t.TokenHash, t.Path, t.Glob, t.Debounce, t.Concurrency, t.Pass, t.Channel)
+
+	err := row.Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("CreateTrigger: %w", errs.ErrCmdNotFound)
+		}
+		return nil, fmt.Errorf("CreateTrigger: scan row failed %w", err)
+	}
+
+	err = row.Err()
+	if err != nil {
+		return nil, fmt.Errorf("CreateTrigger: row.Err %w", err)
+	}
+
+	return t, nil
+}
+
+// GetTriggersByCommandName gets and returns the triggers of the requested
+// by name command from the storage including the revoked ones.
+func (r *CommandRepository) GetTriggersByCommandName(ctx context.Context, name string) ([]*entities.Trigger, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT t.id, c.name, t.type, t.input, t.token_hash, 
+	t.path, t.glob, t.debounce, t.concurrency, t.pass, t.channel, t.created_at, t.rotated_at, t.revoked_at 
+	FROM triggers t JOIN commands c ON c.id = t.command_id 
+	WHERE c.name = $1 ORDER BY t.id`, name)
+	if err != nil {
+		return nil, fmt.Errorf("GetTriggersByCommandName: read rows from table failed %w", err)
+	}
+	defer rows.Close()
+
+	triggers := make([]*entities.Trigger, 0)
+	for rows.Next() {
+		t, err := scanTrigger(rows)
+		if err != nil {
+			return nil, fmt.Errorf("GetTriggersByCommandName: %w", err)
+		}
+		triggers = append(triggers, t)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("GetTriggersByCommandName: rows.Err %w", err)
+	}
+
+	if len(triggers) == 0 {
+		return nil, fmt.Errorf("GetTriggersByCommandName: nothing to return %w", errs.ErrTriggerNotFound)
+	}
+
+	return triggers, nil
+}
+
+// GetTriggerByID gets and returns the requested trigger from the storage.
+func (r *CommandRepository) GetTriggerByID(ctx context.Context, id int) (*entities.Trigger, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT t.id, c.name, t.type, t.input, t.token_hash, 
+	t.path, t.glob, t.debounce, t.concurrency, t.pass, t.channel, t.created_at, t.rotated_at, t.revoked_at 
+	FROM triggers t JOIN commands c ON c.id = t.command_id WHERE t.id = $1`, id)
+
+	t, err := scanTrigger(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("GetTriggerByID: nothing to get, %w", errs.ErrTriggerNotFound)
+		}
+		return nil, fmt.Errorf("GetTriggerByID: %w", err)
+	}
+
+	return t, nil
+}
+
+// GetActiveTriggers gets and returns the triggers of the requested type
+// which are not revoked from the storage.
+func (r *CommandRepository) GetActiveTriggers(ctx context.Context, triggerType string) ([]*entities.Trigger, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT t.id, c.name, t.type, t.input, t.token_hash, 
+	t.path, t.glob, t.debounce, t.concurrency, t.pass, t.channel, t.created_at, t.rotated_at, t.revoked_at 
+	FROM triggers t JOIN commands c ON c.id = t.command_id 
+	WHERE t.type = $1 AND t.revoked_at IS NULL ORDER BY t.id`, triggerType)
+	if err != nil {
+		return nil, fmt.Errorf("GetActiveTriggers: read rows from table failed %w", err)
+	}
+	defer rows.Close()
+
+	triggers := make([]*entities.Trigger, 0)
+	for rows.Next() {
+		t, err := scanTrigger(rows)
+		if err != nil {
+			return nil, fmt.Errorf("GetActiveTriggers: %w", err)
+		}
+		triggers = append(triggers, t)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("GetActiveTriggers: rows.Err %w", err)
+	}
+
+	return triggers, nil
+}
+
+// RotateTriggerToken replaces the token hash of the trigger which is not revoked.
+func (r *CommandRepository) RotateTriggerToken(ctx context.Context, t *entities.Trigger) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE triggers SET token_hash = $1, rotated_at = $2 
+	WHERE id = $3 AND revoked_at IS NULL`, t.TokenHash, t.RotatedAt, t.ID)
+	if err != nil {
+		return fmt.Errorf("RotateTriggerToken: update trigger failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("RotateTriggerToken: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("RotateTriggerToken: nothing to update, %w", errs.ErrTriggerNotFound)
+	}
+
+	return nil
+}
+
+// RevokeTrigger marks the trigger as revoked, the trigger is kept
+// in the storage for the history of its runs.
+func (r *CommandRepository) RevokeTrigger(ctx context.Context, id int, now time.Time) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE triggers SET revoked_at = $1 
+	WHERE id = $2 AND revoked_at IS NULL`, now, id)
+	if err != nil {
+		return fmt.Errorf("RevokeTrigger: update trigger failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("RevokeTrigger: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("RevokeTrigger: nothing to revoke, %w", errs.ErrTriggerNotFound)
+	}
+
+	return nil
+}
+
+// scanTrigger scans the trigger from the row.
+func scanTrigger(row scanner) (*entities.Trigger, error) {
+	var t entities.Trigger
+	var rotatedAt, revokedAt sql.NullTime
+
+	err := row.Scan(&t.ID, &t.Name, &t.Type, &t.Input, &t.TokenHash, &t.Path, &t.Glob,
+		&t.Debounce, &t.Concurrency, &t.Pass, &t.Channel, &t.CreatedAt, &rotatedAt, &revokedAt)
+	if err != nil {
+		return nil, fmt.Errorf("scanTrigger: scan row failed %w", err)
+	}
+
+	if rotatedAt.Valid {
+		t.RotatedAt = &rotatedAt.Time
+	}
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+)
+
+// CreateDeliveries stores the webhook deliveries of the event into the outbox.
+func (r *CommandRepository) CreateDeliveries(ctx context.Context, deliveries []*entities.Delivery) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("CreateDeliveries: begin transaction failed %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, d := range deliveries {
+		row := tx.QueryRowContext(ctx, `INSERT INTO deliveries (url, event, payload, status, next_attempt_at) 
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`, d.URL, d.Event, string(d.Payload), d.Status, d.NextAttemptAt)
+		err = row.Scan(&d.ID, &d.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("CreateDeliveries: scan row failed %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("CreateDeliveries: commit transaction failed %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDueDeliveries gets the pending deliveries which attempt time has come
+// and postpones their next attempt for the lease duration, so the deliveries
+// are not sent twice even if several servers share the storage.
+func (r *CommandRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration,
+	limit int) ([]*entities.Delivery, error) {
+	rows, err := r.db.QueryContext(ctx, `UPDATE deliveries SET next_attempt_at = $1 
+	WHERE id IN (SELECT id FROM deliveries WHERE status = $2 AND next_attempt_at <= $3 
+	ORDER BY next_attempt_at, id LIMIT $4 FOR UPDATE SKIP LOCKED) 
+	RETURNING id, url, event, payload, status, attempts, last_error, created_at, next_attempt_at, delivered_at`,
+		now.Add(lease), entities.DeliveryPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ClaimDueDeliveries: update rows failed %w", err)
+	}
+	defer rows.Close()
+
+	deliveries, err := scanDeliveries(rows)
+	if err != nil {
+		return nil, fmt.Errorf("ClaimDueDeliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// UpdateDelivery stores the result of the delivery attempt.
+func (r *CommandRepository) UpdateDelivery(ctx context.Context, d *entities.Delivery) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE deliveries SET status = $1, attempts = $2, last_error = $3, 
+	next_attempt_at = $4, delivered_at = $5 WHERE id = $6`,
+		d.Status, d.Attempts, d.LastError, d.NextAttemptAt, d.DeliveredAt, d.ID)
+	if err != nil {
+		return fmt.Errorf("UpdateDelivery: update delivery failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("UpdateDelivery: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("UpdateDelivery: nothing to update, %w", errs.ErrDeliveryNotFound)
+	}
+
+	return nil
+}
+
+// GetDeadDeliveries gets and returns the deliveries which attempts are exhausted,
+// the latest deliveries first.
+func (r *CommandRepository) GetDeadDeliveries(ctx context.Context) ([]*entities.Delivery, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, url, event, payload, status, attempts, last_error, 
+	created_at, next_attempt_at, delivered_at FROM deliveries WHERE status = $1 ORDER BY id DESC`,
+		entities.DeliveryDead)
+	if err != nil {
+		return nil, fmt.Errorf("GetDeadDeliveries: read rows from table failed %w", err)
+	}
+	defer rows.Close()
+
+	deliveries, err := scanDeliveries(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetDeadDeliveries: %w", err)
+	}
+
+	if len(deliveries) == 0 {
+		return nil, fmt.Errorf("GetDeadDeliveries: nothing to return %w", errs.ErrDeliveryNotFound)
+	}
+
+	return deliveries, nil
+}
+
+// ReplayDelivery moves the dead delivery back into the outbox with the attempts reset.
+// It returns false if the delivery is not dead.
+func (r *CommandRepository) ReplayDelivery(ctx context.Context, id int, now time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE deliveries SET status = $1, attempts = 0, last_error = '', 
+	next_attempt_at = $2 WHERE id = $3 AND status = $4`, entities.DeliveryPending, now, id, entities.DeliveryDead)
+	if err != nil {
+		return false, fmt.Errorf("ReplayDelivery: update delivery failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ReplayDelivery: couldn't get rows affected %w", err)
+	}
+
+	return rowsCount > 0, nil
+}
+
+// scanDeliveries scans all the deliveries from the rows.
+func scanDeliveries(rows *sql.Rows) ([]*entities.Delivery, error) {
+	deliveries := make([]*entities.Delivery, 0)
+	for rows.Next() {
+		var d entities.Delivery
+		var payload string
+		var deliveredAt sql.NullTime
+
+		err := rows.Scan(&d.ID, &d.URL, &d.Event, &payload, &d.Status, &d.Attempts, &d.LastError,
+			&d.CreatedAt, &d.NextAttemptAt, &deliveredAt)
+		if err != nil {
+			return nil, fmt.Errorf("scanDeliveries: scan row failed %w", err)
+		}
+
+		d.Payload = []byte(payload)
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanDeliveries: rows.Err %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+)
+
+// CreateWorkflow stores new workflow with its nodes into the storage.
+func (r *CommandRepository) CreateWorkflow(ctx context.Context, wf *entities.Workflow) (*entities.Workflow, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("CreateWorkflow: begin transaction failed %w", err)
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, `INSERT INTO workflows (name) VALUES ($1) RETURNING id`, wf.Name)
+	err = row.Scan(&wf.ID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return nil, fmt.Errorf("CreateWorkflow: %w", errs.ErrWorkflowAlreadyExists)
+		}
+
+		return nil, fmt.Errorf("CreateWorkflow: scan row failed %w", err)
+	}
+
+	for _, node := range wf.Nodes {
+		_, err = tx.ExecContext(ctx, `INSERT INTO workflow_nodes (workflow_id, name, command, needs)
+		VALUES ($1, $2, $3, $4)`, wf.ID, node.Name, node.Command, joinNeeds(node.Needs))
+		if err != nil {
+			return nil, fmt.Errorf("CreateWorkflow: insert node %s failed %w", node.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("CreateWorkflow: commit transaction failed %w", err)
+	}
+
+	return wf, nil
+}
+
+// GetAllWorkflows gets and returns all the workflows with their nodes from the storage.
+func (r *CommandRepository) GetAllWorkflows(ctx context.Context) ([]*entities.Workflow, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT w.id, w.name, n.name, n.command, n.needs
+	FROM workflows w JOIN workflow_nodes n ON n.workflow_id = w.id ORDER BY w.name, n.id`)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllWorkflows: read rows from table failed %w", err)
+	}
+	defer rows.Close()
+
+	workflows, err := scanWorkflows(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllWorkflows: %w", err)
+	}
+
+	if len(workflows) == 0 {
+		return nil, fmt.Errorf("GetAllWorkflows: nothing to return %w", errs.ErrWorkflowNotFound)
+	}
+
+	return workflows, nil
+}
+
+// GetWorkflowByName gets and returns the requested by name workflow with its nodes from the storage.
+func (r *CommandRepository) GetWorkflowByName(ctx context.Context, name string) (*entities.Workflow, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT w.id, w.name, n.name, n.command, n.needs
+	FROM workflows w JOIN workflow_nodes n ON n.workflow_id = w.id WHERE w.name = $1 ORDER BY n.id`, name)
+	if err != nil {
+		return nil, fmt.Errorf("GetWorkflowByName: read rows from table failed %w", err)
+	}
+	defer rows.Close()
+
+	workflows, err := scanWorkflows(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetWorkflowByName: %w", err)
+	}
+
+	if len(workflows) == 0 {
+		return nil, fmt.Errorf("GetWorkflowByName: nothing to get, %w", errs.ErrWorkflowNotFound)
+	}
+
+	return workflows[0], nil
+}
+
+// DeleteWorkflowByName deletes the workflow with its nodes and runs from the storage.
+func (r *CommandRepository) DeleteWorkflowByName(ctx context.Context, name string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM workflows WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("DeleteWorkflowByName: delete workflow failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("DeleteWorkflowByName: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("DeleteWorkflowByName: nothing to delete, %w", errs.ErrWorkflowNotFound)
+	}
+
+	return nil
+}
+
+// CreateWorkflowRun stores new run of the workflow with the statuses of its nodes into the storage.
+func (r *CommandRepository) CreateWorkflowRun(ctx context.Context, run *entities.WorkflowRun) (*entities.WorkflowRun, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("CreateWorkflowRun: begin transaction failed %w", err)
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, `INSERT INTO workflow_runs (workflow_id, status)
+	VALUES ($1, $2) RETURNING id, created_at`, run.WorkflowID, run.Status)
+	err = row.Scan(&run.ID, &run.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("CreateWorkflowRun: scan row failed %w", err)
+	}
+
+	for _, node := range run.Nodes {
+		_, err = tx.ExecContext(ctx, `INSERT INTO workflow_node_runs (workflow_run_id, node, command, status)
+		VALUES ($1, $2, $3, $4)`, run.ID, node.Node, node.Command, node.Status)
+		if err != nil {
+			return nil, fmt.Errorf("CreateWorkflowRun: insert node %s failed %w", node.Node, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("CreateWorkflowRun: commit transaction failed %w", err)
+	}
+
+	return run, nil
+}
+
+// UpdateWorkflowNodeRun stores the status and the command run of the node in the workflow run.
+func (r *CommandRepository) UpdateWorkflowNodeRun(ctx context.Context, runID int, node *entities.NodeRun) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE workflow_node_runs SET status = $1, run_id = $2
+	WHERE workflow_run_id = $3 AND node = $4`, node.Status, node.RunID, runID, node.Node)
+	if err != nil {
+		return fmt.Errorf("UpdateWorkflowNodeRun: update node run failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("UpdateWorkflowNodeRun: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("UpdateWorkflowNodeRun: nothing to update, %w", errs.ErrWorkflowRunNotFound)
+	}
+
+	return nil
+}
+
+// FinishWorkflowRun stores the final status and finish time of the workflow run.
+func (r *CommandRepository) FinishWorkflowRun(ctx context.Context, run *entities.WorkflowRun) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE workflow_runs SET status = $1, finished_at = $2 WHERE id = $3`,
+		run.Status, run.FinishedAt, run.ID)
+	if err != nil {
+		return fmt.Errorf("FinishWorkflowRun: update workflow run failed %w", err)
+	}
+
+	rowsCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("FinishWorkflowRun: couldn't get rows affected %w", err)
+	}
+	if rowsCount == 0 {
+		return fmt.Errorf("FinishWorkflowRun: nothing to update, %w", errs.ErrWorkflowRunNotFound)
+	}
+
+	return nil
+}
+
+// GetWorkflowRunByID gets and returns the requested workflow run with the statuses
+// of its nodes from the storage.
+func (r *CommandRepository) GetWorkflowRunByID(ctx context.Context, id int) (*entities.WorkflowRun, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT r.id, r.workflow_id, w.name, r.status, r.created_at, r.finished_at
+	FROM workflow_runs r JOIN workflows w ON w.id = r.workflow_id WHERE r.id = $1`, id)
+
+	var run entities.WorkflowRun
+	var finishedAt sql.NullTime
+	err := row.Scan(&run.ID, &run.WorkflowID, &run.Name, &run.Status, &run.CreatedAt, &finishedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("GetWorkflowRunByID: nothing to get, %w", errs.ErrWorkflowRunNotFound)
+		}
+		return nil, fmt.Errorf("GetWorkflowRunByID: scan row failed %w", err)
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT node, command, status, run_id FROM workflow_node_runs
+	WHERE workflow_run_id = $1 ORDER BY id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("GetWorkflowRunByID: read rows from table failed %w", err)
+	}
+	defer rows.Close()
+
+	run.Nodes = make([]*entities.NodeRun, 0)
+	for rows.Next() {
+		var node entities.NodeRun
+		var runID sql.NullInt32
+		err = rows.Scan(&node.Node, &node.Command, &node.Status, &runID)
+		if err != nil {
+			return nil, fmt.Errorf("GetWorkflowRunByID: scan row failed %w", err)
+		}
+		if runID.Valid {
+			id := int(runID.Int32)
+			node.RunID = &id
+		}
+		run.Nodes = append(run.Nodes, &node)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("GetWorkflowRunByID: rows.Err %w", err)
+	}
+
+	return &run, nil
+}
+
+// scanWorkflows scans the workflows from the rows of their nodes ordered by workflow.
+func scanWorkflows(rows *sql.Rows) ([]*entities.Workflow, error) {
+	workflows := make([]*entities.Workflow, 0)
+
+	var wf *entities.Workflow
+	for rows.Next() {
+		var id int
+		var name, needs string
+		var node entities.WorkflowNode
+
+		err := rows.Scan(&id, &name, &node.Name, &node.Command, &needs)
+		if err != nil {
+			return nil, fmt.Errorf("scanWorkflows: scan row failed %w", err)
+		}
+		node.Needs = splitNeeds(needs)
+
+		if wf == nil || wf.ID != id {
+			wf = &entities.Workflow{ID: id, Name: name}
+			workflows = append(workflows, wf)
+		}
+		wf.Nodes = append(wf.Nodes, &node)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("scanWorkflows: rows.Err %w", err)
+	}
+
+	return workflows, nil
+}
+
+// joinNeeds joins the dependencies of the node in the form "node:condition,node:condition".
+func joinNeeds(needs []*entities.Dependency) string {
+	pairs := make([]string, 0, len(needs))
+	for _, dep := range needs {
+		pairs = append(pairs, dep.Node+":"+dep.On)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// splitNeeds splits the dependencies of the node stored in the table.
+func splitNeeds(needs string) []*entities.Dependency {
+	deps := make([]*entities.Dependency, 0)
+	for _, pair := range splitList(needs) {
+		node, on, _ := strings.Cut(pair, ":")
+		deps = append(deps, &entities.Dependency{Node: node, On: on})
+	}
+	if len(deps) == 0 {
+		return nil
+	}
+	return deps
+}
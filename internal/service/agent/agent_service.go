@@ -0,0 +1,447 @@
+// Package agent contains agent service object and methods
+// for registering the remote agents and dispatching the command runs to them.
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// PollWait is the maximum duration of the poll request waiting for the jobs.
+	PollWait = 20 * time.Second
+
+	// MaxCapacity is the maximum number of the commands run by the agent at once.
+	MaxCapacity = 100
+
+	// TokenHeader is the header of the agent request containing the agents token.
+	TokenHeader = "X-Agent-Token"
+	// IDHeader is the header of the agent request containing the agent identifier.
+	IDHeader = "X-Agent-ID"
+)
+
+// Service describes methods for the remote agents.
+//
+//go:generate mockgen -destination=../../mocks/mock_AgentService.go -package=mocks -mock_names=Service=MockAgentService github.com/pavlegich/scripts-hub/internal/service/agent Service
+type Service interface {
+	Authorize(token string) error
+	Register(ctx context.Context, a *entities.Agent) (*entities.Agent, error)
+	List(ctx context.Context) []*entities.Agent
+	Acquire(ctx context.Context, labels []string) (string, error)
+	Release(id string)
+	Start(id string, job *entities.AgentJob, w io.Writer) (*Task, error)
+	Signal(runID int, sig int) error
+	Poll(ctx context.Context, id string, wait time.Duration) (*entities.AgentPoll, error)
+	Output(id string, runID int, data []byte) error
+	Exit(id string, exit *entities.AgentExit) error
+	Expire(now time.Time)
+	RunExpiry(ctx context.Context, interval time.Duration)
+}
+
+// Task contains the command run started by the agent.
+type Task struct {
+	RunID   int
+	AgentID string
+
+	w    io.Writer
+	done chan *entities.AgentExit
+}
+
+// Done returns the channel receiving the exit status of the command.
+func (t *Task) Done() <-chan *entities.AgentExit {
+	return t.done
+}
+
+// remoteAgent contains the registered agent with its started runs,
+// the reserved slots and the jobs and signals waiting for its poll.
+type remoteAgent struct {
+	info     entities.Agent
+	reserved int
+	tasks    map[int]*Task
+	jobs     []*entities.AgentJob
+	signals  []*entities.AgentSignal
+}
+
+// AgentService contains objects for agent service.
+type AgentService struct {
+	token   string
+	timeout time.Duration
+
+	mu      sync.Mutex
+	agents  map[string]*remoteAgent
+	tasks   map[int]*Task
+	changed chan struct{}
+}
+
+// NewAgentService returns new agent service. The agents authorize with the token,
+// no agent is authorized if it is empty. The agent which has not polled
+// for the timeout is lost.
+func NewAgentService(ctx context.Context, token string, timeout time.Duration) *AgentService {
+	return &AgentService{
+		token:   token,
+		timeout: timeout,
+		agents:  make(map[string]*remoteAgent),
+		tasks:   make(map[int]*Task),
+		changed: make(chan struct{}),
+	}
+}
+
+// Authorize checks the token of the agent request. The agents are refused
+// without the configured token, as they receive the input and the environment
+// of the commands and report their exit statuses.
+func (s *AgentService) Authorize(token string) error {
+	if s.token == "" {
+		return fmt.Errorf("Authorize: %w", errs.ErrAgentsDisabled)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		return fmt.Errorf("Authorize: %w", errs.ErrAgentUnauthorized)
+	}
+
+	return nil
+}
+
+// Register adds new agent and returns it with the generated identifier.
+func (s *AgentService) Register(ctx context.Context, a *entities.Agent) (*entities.Agent, error) {
+	if a.Name == "" {
+		return nil, fmt.Errorf("Register: empty name %w", errs.ErrAgentIncorrect)
+	}
+	if a.Capacity < 1 || a.Capacity > MaxCapacity {
+		return nil, fmt.Errorf("Register: capacity out of range %w", errs.ErrAgentIncorrect)
+	}
+	for _, l := range a.Labels {
+		if l == "" {
+			return nil, fmt.Errorf("Register: empty label %w", errs.ErrAgentIncorrect)
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, fmt.Errorf("Register: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ra := &remoteAgent{
+		info: entities.Agent{
+			ID:       id,
+			Name:     a.Name,
+			Labels:   a.Labels,
+			Capacity: a.Capacity,
+			SeenAt:   time.Now(),
+		},
+		tasks: make(map[int]*Task),
+	}
+	s.agents[id] = ra
+	s.broadcast()
+
+	logger.Log.Info("Register: agent registered",
+		zap.String("agent_id", id), zap.String("agent_name", a.Name), zap.Strings("labels", a.Labels))
+
+	info := ra.info
+	return &info, nil
+}
+
+// List returns the registered agents sorted by the name.
+func (s *AgentService) List(ctx context.Context) []*entities.Agent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agents := make([]*entities.Agent, 0, len(s.agents))
+	for _, ra := range s.agents {
+		info := ra.info
+		info.Running = len(ra.tasks) + ra.reserved
+		agents = append(agents, &info)
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		if agents[i].Name != agents[j].Name {
+			return agents[i].Name < agents[j].Name
+		}
+		return agents[i].ID < agents[j].ID
+	})
+
+	return agents
+}
+
+// Acquire waits for the agent having all the labels and the free slot
+// until the context is done. It reserves the slot of the least busy agent
+// and returns its identifier, the slot is taken by Start or freed by Release.
+func (s *AgentService) Acquire(ctx context.Context, labels []string) (string, error) {
+	for {
+		s.mu.Lock()
+		ra := s.pick(labels)
+		if ra != nil {
+			ra.reserved++
+			s.mu.Unlock()
+			return ra.info.ID, nil
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("Acquire: wait for agent interrupted %w", ctx.Err())
+		case <-changed:
+		}
+	}
+}
+
+// Release frees the slot reserved on the agent.
+func (s *AgentService) Release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ra, ok := s.agents[id]
+	if !ok || ra.reserved == 0 {
+		return
+	}
+	ra.reserved--
+	s.broadcast()
+}
+
+// Start passes the job into the reserved slot of the agent. The output of
+// the command received from the agent is written into the writer.
+func (s *AgentService) Start(id string, job *entities.AgentJob, w io.Writer) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ra, ok := s.agents[id]
+	if !ok {
+		return nil, fmt.Errorf("Start: %w", errs.ErrAgentNotFound)
+	}
+	if ra.reserved > 0 {
+		ra.reserved--
+	}
+
+	t := &Task{
+		RunID:   job.RunID,
+		AgentID: id,
+		w:       w,
+		done:    make(chan *entities.AgentExit, 1),
+	}
+	ra.tasks[job.RunID] = t
+	ra.jobs = append(ra.jobs, job)
+	s.tasks[job.RunID] = t
+	s.broadcast()
+
+	return t, nil
+}
+
+// Signal passes the signal to the agent running the command of the run.
+func (s *AgentService) Signal(runID int, sig int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tasks[runID]
+	if !ok {
+		return fmt.Errorf("Signal: %w", errs.ErrRunNotFound)
+	}
+
+	ra := s.agents[t.AgentID]
+	ra.signals = append(ra.signals, &entities.AgentSignal{RunID: runID, Signal: sig})
+	s.broadcast()
+
+	return nil
+}
+
+// Poll returns the jobs and the signals waiting for the agent. If there are
+// none, it waits for them for the wait duration or until the context is done
+// and returns the empty poll then. The wait is limited to the half of the agent
+// timeout, so the waiting agent is not lost.
+func (s *AgentService) Poll(ctx context.Context, id string, wait time.Duration) (*entities.AgentPoll, error) {
+	if s.timeout > 0 && wait > s.timeout/2 {
+		wait = s.timeout / 2
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		ra, ok := s.agents[id]
+		if !ok {
+			s.mu.Unlock()
+			return nil, fmt.Errorf("Poll: %w", errs.ErrAgentNotFound)
+		}
+		ra.info.SeenAt = time.Now()
+
+		if len(ra.jobs) > 0 || len(ra.signals) > 0 {
+			poll := &entities.AgentPoll{
+				Jobs:    ra.jobs,
+				Signals: ra.signals,
+			}
+			ra.jobs, ra.signals = nil, nil
+			s.mu.Unlock()
+			return poll, nil
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return &entities.AgentPoll{}, nil
+		case <-timer.C:
+			return &entities.AgentPoll{}, nil
+		case <-changed:
+		}
+	}
+}
+
+// Output writes the output of the command received from the agent.
+func (s *AgentService) Output(id string, runID int, data []byte) error {
+	s.mu.Lock()
+	t, err := s.task(id, runID)
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("Output: %w", err)
+	}
+
+	_, err = t.w.Write(data)
+	if err != nil {
+		return fmt.Errorf("Output: %w", err)
+	}
+
+	return nil
+}
+
+// Exit completes the run of the agent with the exit status of its command.
+func (s *AgentService) Exit(id string, exit *entities.AgentExit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.task(id, exit.RunID)
+	if err != nil {
+		return fmt.Errorf("Exit: %w", err)
+	}
+	s.complete(t, exit)
+
+	return nil
+}
+
+// Expire removes the agents which have not polled for the timeout,
+// the runs of the removed agents are completed as failed.
+func (s *AgentService) Expire(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, ra := range s.agents {
+		if now.Sub(ra.info.SeenAt) <= s.timeout {
+			continue
+		}
+
+		logger.Log.Warn("Expire: agent lost",
+			zap.String("agent_id", id), zap.String("agent_name", ra.info.Name),
+			zap.Int("running", len(ra.tasks)))
+
+		for _, t := range ra.tasks {
+			s.complete(t, &entities.AgentExit{
+				RunID:    t.RunID,
+				ExitCode: -1,
+				Error:    errs.ErrAgentLost.Error(),
+			})
+		}
+		delete(s.agents, id)
+		s.broadcast()
+	}
+}
+
+// RunExpiry removes the lost agents every interval until the context is done.
+func (s *AgentService) RunExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.Expire(now)
+		}
+	}
+}
+
+// task returns the run started by the agent.
+// It is called with the mutex locked.
+func (s *AgentService) task(id string, runID int) (*Task, error) {
+	t, ok := s.tasks[runID]
+	if !ok || t.AgentID != id {
+		return nil, fmt.Errorf("task: %w", errs.ErrRunNotFound)
+	}
+
+	return t, nil
+}
+
+// complete removes the run from the agent and sends its exit status.
+// It is called with the mutex locked.
+func (s *AgentService) complete(t *Task, exit *entities.AgentExit) {
+	if ra, ok := s.agents[t.AgentID]; ok {
+		delete(ra.tasks, t.RunID)
+	}
+	delete(s.tasks, t.RunID)
+	s.broadcast()
+
+	t.done <- exit
+}
+
+// pick returns the least busy agent having all the labels and the free slot.
+// It is called with the mutex locked.
+func (s *AgentService) pick(labels []string) *remoteAgent {
+	var best *remoteAgent
+	bestFree := 0
+	for _, ra := range s.agents {
+		free := ra.info.Capacity - ra.reserved - len(ra.tasks)
+		if free <= 0 || !hasLabels(ra.info.Labels, labels) {
+			continue
+		}
+		if best == nil || free > bestFree || (free == bestFree && ra.info.ID < best.info.ID) {
+			best, bestFree = ra, free
+		}
+	}
+	return best
+}
+
+// broadcast wakes the requests waiting for the change of the agents.
+// It is called with the mutex locked.
+func (s *AgentService) broadcast() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// hasLabels reports whether the agent labels contain all the wanted ones.
+func hasLabels(have, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			if h == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// newID returns the random identifier of the agent.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("newID: read random bytes failed %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
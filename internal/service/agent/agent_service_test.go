@@ -0,0 +1,188 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentService_Register(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		agent   entities.Agent
+		wantErr bool
+	}{
+		{
+			name:  "success",
+			agent: entities.Agent{Name: "build-1", Labels: []string{"linux", "docker"}, Capacity: 2},
+		},
+		{
+			name:    "empty_name",
+			agent:   entities.Agent{Capacity: 1},
+			wantErr: true,
+		},
+		{
+			name:    "zero_capacity",
+			agent:   entities.Agent{Name: "build-1"},
+			wantErr: true,
+		},
+		{
+			name:    "empty_label",
+			agent:   entities.Agent{Name: "build-1", Labels: []string{""}, Capacity: 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewAgentService(ctx, "", time.Minute)
+
+			got, err := s.Register(ctx, &tt.agent)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrAgentIncorrect)
+				require.Empty(t, s.List(ctx))
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, got.ID)
+			require.Equal(t, []*entities.Agent{got}, s.List(ctx))
+		})
+	}
+}
+
+func TestAgentService_Authorize(t *testing.T) {
+	ctx := context.Background()
+
+	require.ErrorIs(t, NewAgentService(ctx, "", time.Minute).Authorize(""), errs.ErrAgentsDisabled)
+
+	s := NewAgentService(ctx, "secret", time.Minute)
+	require.NoError(t, s.Authorize("secret"))
+	require.ErrorIs(t, s.Authorize("wrong"), errs.ErrAgentUnauthorized)
+	require.ErrorIs(t, s.Authorize(""), errs.ErrAgentUnauthorized)
+}
+
+func TestAgentService_Acquire(t *testing.T) {
+	ctx := context.Background()
+	s := NewAgentService(ctx, "", time.Minute)
+
+	linux, err := s.Register(ctx, &entities.Agent{Name: "linux", Labels: []string{"linux"}, Capacity: 1})
+	require.NoError(t, err)
+	gpu, err := s.Register(ctx, &entities.Agent{Name: "gpu", Labels: []string{"linux", "gpu"}, Capacity: 1})
+	require.NoError(t, err)
+
+	// The agent has to have all the labels
+	id, err := s.Acquire(ctx, []string{"gpu"})
+	require.NoError(t, err)
+	require.Equal(t, gpu.ID, id)
+
+	id, err = s.Acquire(ctx, []string{"linux"})
+	require.NoError(t, err)
+	require.Equal(t, linux.ID, id)
+
+	// No free slots are left
+	ctxTimeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = s.Acquire(ctxTimeout, []string{"linux"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The released slot is taken by the waiting run
+	acquired := make(chan string, 1)
+	go func() {
+		id, _ := s.Acquire(ctx, []string{"linux"})
+		acquired <- id
+	}()
+	s.Release(gpu.ID)
+
+	select {
+	case id := <-acquired:
+		require.Equal(t, gpu.ID, id)
+	case <-time.After(time.Second):
+		t.Fatal("slot is not acquired")
+	}
+}
+
+func TestAgentService_Poll(t *testing.T) {
+	ctx := context.Background()
+	s := NewAgentService(ctx, "", time.Minute)
+
+	a, err := s.Register(ctx, &entities.Agent{Name: "linux", Capacity: 1})
+	require.NoError(t, err)
+
+	// The empty poll is returned after the wait
+	poll, err := s.Poll(ctx, a.ID, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Empty(t, poll.Jobs)
+
+	_, err = s.Poll(ctx, "unknown", 10*time.Millisecond)
+	require.ErrorIs(t, err, errs.ErrAgentNotFound)
+
+	// The waiting poll receives the started job
+	polled := make(chan *entities.AgentPoll, 1)
+	go func() {
+		poll, _ := s.Poll(ctx, a.ID, time.Second)
+		polled <- poll
+	}()
+
+	id, err := s.Acquire(ctx, nil)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	task, err := s.Start(id, &entities.AgentJob{RunID: 7, Path: "echo"}, &out)
+	require.NoError(t, err)
+
+	select {
+	case poll := <-polled:
+		require.Equal(t, []*entities.AgentJob{{RunID: 7, Path: "echo"}}, poll.Jobs)
+	case <-time.After(time.Second):
+		t.Fatal("job is not polled")
+	}
+
+	require.NoError(t, s.Signal(7, 15))
+	poll, err = s.Poll(ctx, a.ID, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, []*entities.AgentSignal{{RunID: 7, Signal: 15}}, poll.Signals)
+
+	// The output and the exit status are accepted from the agent of the run only
+	require.ErrorIs(t, s.Output("other", 7, []byte("hello\n")), errs.ErrRunNotFound)
+	require.NoError(t, s.Output(a.ID, 7, []byte("hello\n")))
+	require.Equal(t, "hello\n", out.String())
+
+	require.NoError(t, s.Exit(a.ID, &entities.AgentExit{RunID: 7, ExitCode: 3}))
+	require.Equal(t, &entities.AgentExit{RunID: 7, ExitCode: 3}, <-task.Done())
+
+	require.ErrorIs(t, s.Exit(a.ID, &entities.AgentExit{RunID: 7}), errs.ErrRunNotFound)
+	require.ErrorIs(t, s.Signal(7, 9), errs.ErrRunNotFound)
+}
+
+func TestAgentService_Expire(t *testing.T) {
+	ctx := context.Background()
+	s := NewAgentService(ctx, "", time.Minute)
+
+	a, err := s.Register(ctx, &entities.Agent{Name: "linux", Capacity: 1})
+	require.NoError(t, err)
+
+	id, err := s.Acquire(ctx, nil)
+	require.NoError(t, err)
+	task, err := s.Start(id, &entities.AgentJob{RunID: 7, Path: "sleep"}, &bytes.Buffer{})
+	require.NoError(t, err)
+
+	s.Expire(time.Now())
+	require.Len(t, s.List(ctx), 1)
+
+	// The runs of the lost agent fail
+	s.Expire(time.Now().Add(2 * time.Minute))
+	require.Empty(t, s.List(ctx))
+
+	exit := <-task.Done()
+	require.Equal(t, -1, exit.ExitCode)
+	require.Equal(t, errs.ErrAgentLost.Error(), exit.Error)
+
+	_, err = s.Poll(ctx, a.ID, 10*time.Millisecond)
+	require.ErrorIs(t, err, errs.ErrAgentNotFound)
+}
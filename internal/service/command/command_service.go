@@ -5,8 +5,11 @@ package command
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
 	repo "github.com/pavlegich/scripts-hub/internal/repository"
 )
 
@@ -20,6 +23,24 @@ type Service interface {
 	Unload(ctx context.Context, name string) (*entities.Command, error)
 	AppendOutput(ctx context.Context, command *entities.Command) error
 	Delete(ctx context.Context, name string) error
+
+	CreateRun(ctx context.Context, run *entities.Run) (*entities.Run, error)
+	StartRun(ctx context.Context, run *entities.Run) error
+	FinishRun(ctx context.Context, run *entities.Run) error
+	RetryRun(ctx context.Context, run *entities.Run) error
+	SuspendRun(ctx context.Context, run *entities.Run) error
+	AppendRunOutput(ctx context.Context, run *entities.Run) error
+	ListRuns(ctx context.Context, name string) ([]*entities.Run, error)
+	UnloadRun(ctx context.Context, id int) (*entities.Run, error)
+	ListPendingRuns(ctx context.Context) ([]*entities.Run, error)
+	DueRuns(ctx context.Context, now time.Time) ([]*entities.Run, error)
+	ClaimRun(ctx context.Context, run *entities.Run) (bool, error)
+	CancelPendingRun(ctx context.Context, id int) error
+
+	CreatePipeline(ctx context.Context, commands []*entities.Command) (*entities.Pipeline, error)
+	StartPipeline(ctx context.Context, pipeline *entities.Pipeline) error
+	FinishPipeline(ctx context.Context, pipeline *entities.Pipeline) error
+	UnloadPipeline(ctx context.Context, id int) (*entities.Pipeline, error)
 }
 
 // CommandService contains objects for command service.
@@ -83,3 +104,244 @@ func (s *CommandService) Delete(ctx context.Context, name string) error {
 
 	return nil
 }
+
+// CreateRun requests repository to put new run of the command into the storage.
+// The run is correlated with the request of the context, the runs created
+// outside of the requests get new request identifier.
+func (s *CommandService) CreateRun(ctx context.Context, run *entities.Run) (*entities.Run, error) {
+	if run.RequestID == "" {
+		run.RequestID = requestID(ctx)
+	}
+
+	run, err := s.repo.CreateRun(ctx, run)
+	if err != nil {
+		return nil, fmt.Errorf("CreateRun: create run failed %w", err)
+	}
+
+	return run, nil
+}
+
+// StartRun marks the run as running its next attempt from the current time.
+func (s *CommandService) StartRun(ctx context.Context, run *entities.Run) error {
+	now := time.Now()
+	run.Status = entities.RunRunning
+	run.Attempt++
+	if run.StartedAt == nil {
+		run.StartedAt = &now
+	}
+
+	err := s.repo.StartRun(ctx, run)
+	if err != nil {
+		return fmt.Errorf("StartRun: start run failed %w", err)
+	}
+
+	return nil
+}
+
+// FinishRun stores the final status and exit code of the run finished at the current time.
+func (s *CommandService) FinishRun(ctx context.Context, run *entities.Run) error {
+	now := time.Now()
+	run.FinishedAt = &now
+
+	err := s.repo.FinishRun(ctx, run)
+	if err != nil {
+		return fmt.Errorf("FinishRun: finish run failed %w", err)
+	}
+
+	return nil
+}
+
+// RetryRun stores the failed attempt of the run finished at the current time
+// and marks the run as waiting for the next attempt.
+func (s *CommandService) RetryRun(ctx context.Context, run *entities.Run) error {
+	now := time.Now()
+	run.Status = entities.RunRetrying
+	run.FinishedAt = &now
+
+	err := s.repo.RetryRun(ctx, run)
+	run.FinishedAt = nil
+	if err != nil {
+		return fmt.Errorf("RetryRun: retry run failed %w", err)
+	}
+
+	return nil
+}
+
+// SuspendRun returns the queued run which has not been started before
+// the server shutdown into the pending runs due now, so the next server start
+// queues it again.
+func (s *CommandService) SuspendRun(ctx context.Context, run *entities.Run) error {
+	now := time.Now()
+	run.Status = entities.RunPending
+	run.RunAt = &now
+
+	err := s.repo.SuspendRun(ctx, run)
+	if err != nil {
+		return fmt.Errorf("SuspendRun: suspend run failed %w", err)
+	}
+
+	return nil
+}
+
+// AppendRunOutput appends output for the run and its command.
+func (s *CommandService) AppendRunOutput(ctx context.Context, run *entities.Run) error {
+	err := s.repo.AppendRunOutput(ctx, run)
+	if err != nil {
+		return fmt.Errorf("AppendRunOutput: append run output failed %w", err)
+	}
+
+	return nil
+}
+
+// ListRuns returns the runs of the command by command's name, the latest runs first.
+func (s *CommandService) ListRuns(ctx context.Context, name string) ([]*entities.Run, error) {
+	runs, err := s.repo.GetRunsByCommandName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("ListRuns: get runs list failed %w", err)
+	}
+
+	return runs, nil
+}
+
+// UnloadRun gets run by its identifier and returns it.
+func (s *CommandService) UnloadRun(ctx context.Context, id int) (*entities.Run, error) {
+	run, err := s.repo.GetRunByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("UnloadRun: get run failed %w", err)
+	}
+
+	run.Attempts, err = s.repo.GetRunAttempts(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("UnloadRun: get run attempts failed %w", err)
+	}
+
+	return run, nil
+}
+
+// ListPendingRuns returns the delayed runs waiting for their run time, the earliest runs first.
+func (s *CommandService) ListPendingRuns(ctx context.Context) ([]*entities.Run, error) {
+	runs, err := s.repo.GetPendingRuns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ListPendingRuns: get pending runs list failed %w", err)
+	}
+
+	return runs, nil
+}
+
+// DueRuns returns the delayed runs which run time has come.
+func (s *CommandService) DueRuns(ctx context.Context, now time.Time) ([]*entities.Run, error) {
+	runs, err := s.repo.GetDueRuns(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("DueRuns: get due runs failed %w", err)
+	}
+
+	return runs, nil
+}
+
+// ClaimRun marks the due delayed run as queued. It returns false if the run
+// was already claimed or cancelled by someone else, so the run is queued
+// only once even if several servers share the storage.
+func (s *CommandService) ClaimRun(ctx context.Context, run *entities.Run) (bool, error) {
+	run.Status = entities.RunQueued
+
+	ok, err := s.repo.MoveRunStatus(ctx, run, entities.RunPending)
+	if err != nil {
+		return false, fmt.Errorf("ClaimRun: move run status failed %w", err)
+	}
+
+	return ok, nil
+}
+
+// CancelPendingRun cancels the delayed run before its run time has come.
+func (s *CommandService) CancelPendingRun(ctx context.Context, id int) error {
+	now := time.Now()
+	run := &entities.Run{
+		ID:         id,
+		Status:     entities.RunCancelled,
+		FinishedAt: &now,
+	}
+
+	ok, err := s.repo.MoveRunStatus(ctx, run, entities.RunPending)
+	if err != nil {
+		return fmt.Errorf("CancelPendingRun: move run status failed %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("CancelPendingRun: pending run not found %w", errs.ErrRunNotFound)
+	}
+
+	return nil
+}
+
+// CreatePipeline creates new queued pipeline of the commands with the queued run
+// of each command as its step.
+func (s *CommandService) CreatePipeline(ctx context.Context, cmds []*entities.Command) (*entities.Pipeline, error) {
+	if len(cmds) < 2 {
+		return nil, fmt.Errorf("CreatePipeline: pipeline needs at least two steps %w", errs.ErrPipelineIncorrect)
+	}
+
+	p := &entities.Pipeline{
+		Status: entities.RunQueued,
+		Steps:  make([]*entities.Run, 0, len(cmds)),
+	}
+	id := requestID(ctx)
+	for _, c := range cmds {
+		p.Steps = append(p.Steps, &entities.Run{
+			CommandID: c.ID,
+			Name:      c.Name,
+			Trigger:   entities.TriggerPipeline,
+			RequestID: id,
+			Status:    entities.RunQueued,
+		})
+	}
+
+	p, err := s.repo.CreatePipeline(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePipeline: create pipeline failed %w", err)
+	}
+
+	return p, nil
+}
+
+// StartPipeline marks the pipeline as running.
+func (s *CommandService) StartPipeline(ctx context.Context, p *entities.Pipeline) error {
+	p.Status = entities.RunRunning
+
+	err := s.repo.UpdatePipeline(ctx, p)
+	if err != nil {
+		return fmt.Errorf("StartPipeline: start pipeline failed %w", err)
+	}
+
+	return nil
+}
+
+// FinishPipeline stores the final status of the pipeline finished at the current time.
+func (s *CommandService) FinishPipeline(ctx context.Context, p *entities.Pipeline) error {
+	now := time.Now()
+	p.FinishedAt = &now
+
+	err := s.repo.UpdatePipeline(ctx, p)
+	if err != nil {
+		return fmt.Errorf("FinishPipeline: finish pipeline failed %w", err)
+	}
+
+	return nil
+}
+
+// UnloadPipeline gets pipeline with the runs of its steps by its identifier and returns it.
+func (s *CommandService) UnloadPipeline(ctx context.Context, id int) (*entities.Pipeline, error) {
+	p, err := s.repo.GetPipelineByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("UnloadPipeline: get pipeline failed %w", err)
+	}
+
+	return p, nil
+}
+
+// requestID returns the identifier of the request of the context or new one.
+func requestID(ctx context.Context) string {
+	id := logger.RequestID(ctx)
+	if id == "" {
+		id = logger.NewRequestID()
+	}
+	return id
+}
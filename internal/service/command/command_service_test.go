@@ -8,6 +8,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/pavlegich/scripts-hub/internal/entities"
 	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
 	"github.com/pavlegich/scripts-hub/internal/mocks"
 	repo "github.com/pavlegich/scripts-hub/internal/repository"
 	"github.com/stretchr/testify/require"
@@ -345,3 +346,134 @@ func TestCommandService_Update(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandService_ClaimRun(t *testing.T) {
+	ctx := context.Background()
+	mockCtrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+	s := NewCommandService(ctx, mockRepo)
+
+	tests := []struct {
+		name  string
+		moved bool
+		err   error
+		want  bool
+	}{
+		{
+			name:  "claimed",
+			moved: true,
+			want:  true,
+		},
+		{
+			name:  "already_claimed",
+			moved: false,
+			want:  false,
+		},
+		{
+			name: "storage_failed",
+			err:  errs.ErrRunNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &entities.Run{ID: 5, Status: entities.RunPending}
+
+			mockRepo.EXPECT().MoveRunStatus(gomock.Any(), run, entities.RunPending).
+				Return(tt.moved, tt.err).Times(1)
+
+			got, err := s.ClaimRun(ctx, run)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, entities.RunQueued, run.Status)
+		})
+	}
+}
+
+func TestCommandService_SuspendRun(t *testing.T) {
+	ctx := context.Background()
+	mockCtrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+	s := NewCommandService(ctx, mockRepo)
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "suspended",
+		},
+		{
+			name: "run_not_queued",
+			err:  errs.ErrRunNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &entities.Run{ID: 5, Status: entities.RunQueued}
+
+			mockRepo.EXPECT().SuspendRun(gomock.Any(), run).
+				Return(tt.err).Times(1)
+
+			err := s.SuspendRun(ctx, run)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, entities.RunPending, run.Status)
+			require.NotNil(t, run.RunAt)
+		})
+	}
+}
+
+func TestCommandService_CreateRun(t *testing.T) {
+	ctx := context.Background()
+	mockCtrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+	s := NewCommandService(ctx, mockRepo)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		run  *entities.Run
+		want string
+	}{
+		{
+			name: "request_run",
+			ctx:  logger.WithRequestID(ctx, "deploy-42"),
+			run:  &entities.Run{Name: "deploy"},
+			want: "deploy-42",
+		},
+		{
+			name: "run_request_id_kept",
+			ctx:  logger.WithRequestID(ctx, "deploy-42"),
+			run:  &entities.Run{Name: "deploy", RequestID: "parent-7"},
+			want: "parent-7",
+		},
+		{
+			name: "background_run",
+			ctx:  ctx,
+			run:  &entities.Run{Name: "deploy"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.EXPECT().CreateRun(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, run *entities.Run) (*entities.Run, error) {
+					return run, nil
+				}).Times(1)
+
+			got, err := s.CreateRun(tt.ctx, tt.run)
+			require.NoError(t, err)
+			if tt.want != "" {
+				require.Equal(t, tt.want, got.RequestID)
+			} else {
+				require.Len(t, got.RequestID, 32)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+)
+
+// paramPattern matches the {{name}} placeholders of the command script.
+var paramPattern = regexp.MustCompile(`{{\s*([A-Za-z0-9_]+)\s*}}`)
+
+// ApplyParams replaces the {{name}} placeholders of the script with the values
+// of the parameters. Every placeholder must have its parameter, the values
+// must not contain spaces because the script arguments are split by them.
+func ApplyParams(script string, params map[string]string) (string, error) {
+	for name, val := range params {
+		if strings.IndexFunc(val, unicode.IsSpace) >= 0 {
+			return "", fmt.Errorf("ApplyParams: parameter %s contains spaces %w", name, errs.ErrParamsIncorrect)
+		}
+	}
+
+	var missing []string
+	res := paramPattern.ReplaceAllStringFunc(script, func(m string) string {
+		name := paramPattern.FindStringSubmatch(m)[1]
+		val, ok := params[name]
+		if !ok {
+			missing = append(missing, name)
+			return m
+		}
+		return val
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("ApplyParams: parameters %s not specified %w",
+			strings.Join(missing, ", "), errs.ErrParamsIncorrect)
+	}
+
+	return res, nil
+}
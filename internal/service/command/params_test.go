@@ -0,0 +1,54 @@
+package command
+
+import (
+	"testing"
+
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		params  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "no_placeholders",
+			script: "echo hello",
+			params: map[string]string{"ref": "main"},
+			want:   "echo hello",
+		},
+		{
+			name:   "placeholders",
+			script: "deploy.sh {{ref}} --env={{ env }}",
+			params: map[string]string{"ref": "v1.2.0", "env": "prod"},
+			want:   "deploy.sh v1.2.0 --env=prod",
+		},
+		{
+			name:    "missing_param",
+			script:  "deploy.sh {{ref}}",
+			params:  map[string]string{"env": "prod"},
+			wantErr: true,
+		},
+		{
+			name:    "value_with_spaces",
+			script:  "deploy.sh {{ref}}",
+			params:  map[string]string{"ref": "main; rm -rf /"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyParams(tt.script, tt.params)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrParamsIncorrect)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
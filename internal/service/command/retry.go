@@ -0,0 +1,114 @@
+package command
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+)
+
+// Retry policy limits and defaults.
+const (
+	MaxAttempts       = 20
+	DefaultBackoff    = time.Second
+	DefaultMaxBackoff = time.Minute
+)
+
+// ValidateRetry checks the retry policy of the command and fills
+// its backoff durations with the default values if they are not specified.
+func ValidateRetry(p *entities.RetryPolicy) error {
+	if p == nil {
+		return nil
+	}
+
+	if p.MaxAttempts < 1 || p.MaxAttempts > MaxAttempts {
+		return fmt.Errorf("ValidateRetry: max attempts must be from 1 to %d %w", MaxAttempts, errs.ErrRetryIncorrect)
+	}
+
+	if p.Backoff == "" {
+		p.Backoff = DefaultBackoff.String()
+	}
+	if p.MaxBackoff == "" {
+		p.MaxBackoff = DefaultMaxBackoff.String()
+	}
+
+	backoff, maxBackoff, err := retryBackoff(p)
+	if err != nil {
+		return fmt.Errorf("ValidateRetry: %w", err)
+	}
+	if backoff <= 0 || maxBackoff < backoff {
+		return fmt.Errorf("ValidateRetry: backoff must be positive and not greater than max backoff %w",
+			errs.ErrRetryIncorrect)
+	}
+
+	for _, code := range p.ExitCodes {
+		if code < 1 || code > 255 {
+			return fmt.Errorf("ValidateRetry: exit code %d out of range %w", code, errs.ErrRetryIncorrect)
+		}
+	}
+
+	return nil
+}
+
+// Retryable reports whether the run finished with the exit code after the attempt
+// should be retried by the retry policy of the command.
+func Retryable(p *entities.RetryPolicy, attempt int, exitCode int) bool {
+	if p == nil || attempt >= p.MaxAttempts || exitCode == 0 {
+		return false
+	}
+
+	if len(p.ExitCodes) == 0 {
+		return true
+	}
+	for _, code := range p.ExitCodes {
+		if code == exitCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RetryDelay returns the delay before the attempt following the failed one.
+// The delay grows exponentially from the backoff up to the max backoff,
+// and the random jitter spreads the retries of the commands failed together.
+func RetryDelay(p *entities.RetryPolicy, attempt int) time.Duration {
+	return retryDelay(p, attempt, rand.Int63n)
+}
+
+// retryDelay returns the exponential delay before the attempt following
+// the failed one with the jitter from the half of the delay to the full delay.
+func retryDelay(p *entities.RetryPolicy, attempt int, jitter func(n int64) int64) time.Duration {
+	backoff, maxBackoff, err := retryBackoff(p)
+	if err != nil || backoff <= 0 {
+		backoff, maxBackoff = DefaultBackoff, DefaultMaxBackoff
+	}
+
+	delay := backoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	half := delay / 2
+	return half + time.Duration(jitter(int64(delay-half)+1))
+}
+
+// retryBackoff parses the backoff durations of the retry policy.
+func retryBackoff(p *entities.RetryPolicy) (time.Duration, time.Duration, error) {
+	backoff, err := time.ParseDuration(p.Backoff)
+	if err != nil {
+		return 0, 0, fmt.Errorf("retryBackoff: parse backoff failed %s %w", err, errs.ErrRetryIncorrect)
+	}
+
+	maxBackoff, err := time.ParseDuration(p.MaxBackoff)
+	if err != nil {
+		return 0, 0, fmt.Errorf("retryBackoff: parse max backoff failed %s %w", err, errs.ErrRetryIncorrect)
+	}
+
+	return backoff, maxBackoff, nil
+}
@@ -0,0 +1,120 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *entities.RetryPolicy
+		want    *entities.RetryPolicy
+		wantErr bool
+	}{
+		{
+			name: "no_policy",
+		},
+		{
+			name:   "defaults",
+			policy: &entities.RetryPolicy{MaxAttempts: 3},
+			want:   &entities.RetryPolicy{MaxAttempts: 3, Backoff: "1s", MaxBackoff: "1m0s"},
+		},
+		{
+			name:   "custom",
+			policy: &entities.RetryPolicy{MaxAttempts: 5, Backoff: "500ms", MaxBackoff: "10s", ExitCodes: []int{7, 28}},
+			want:   &entities.RetryPolicy{MaxAttempts: 5, Backoff: "500ms", MaxBackoff: "10s", ExitCodes: []int{7, 28}},
+		},
+		{
+			name:    "too_many_attempts",
+			policy:  &entities.RetryPolicy{MaxAttempts: 21},
+			wantErr: true,
+		},
+		{
+			name:    "incorrect_backoff",
+			policy:  &entities.RetryPolicy{MaxAttempts: 2, Backoff: "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "backoff_greater_than_max",
+			policy:  &entities.RetryPolicy{MaxAttempts: 2, Backoff: "1m", MaxBackoff: "1s"},
+			wantErr: true,
+		},
+		{
+			name:    "incorrect_exit_code",
+			policy:  &entities.RetryPolicy{MaxAttempts: 2, ExitCodes: []int{0}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRetry(tt.policy)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrRetryIncorrect)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, tt.policy)
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	anyCode := &entities.RetryPolicy{MaxAttempts: 3}
+	listed := &entities.RetryPolicy{MaxAttempts: 3, ExitCodes: []int{7}}
+
+	tests := []struct {
+		name     string
+		policy   *entities.RetryPolicy
+		attempt  int
+		exitCode int
+		want     bool
+	}{
+		{name: "no_policy", attempt: 1, exitCode: 1, want: false},
+		{name: "any_exit_code", policy: anyCode, attempt: 1, exitCode: 1, want: true},
+		{name: "success", policy: anyCode, attempt: 1, exitCode: 0, want: false},
+		{name: "last_attempt", policy: anyCode, attempt: 3, exitCode: 1, want: false},
+		{name: "listed_exit_code", policy: listed, attempt: 2, exitCode: 7, want: true},
+		{name: "not_listed_exit_code", policy: listed, attempt: 1, exitCode: 1, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Retryable(tt.policy, tt.attempt, tt.exitCode))
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := &entities.RetryPolicy{MaxAttempts: 10, Backoff: "1s", MaxBackoff: "10s"}
+
+	noJitter := func(n int64) int64 { return 0 }
+	fullJitter := func(n int64) int64 { return n - 1 }
+
+	tests := []struct {
+		name    string
+		attempt int
+		jitter  func(n int64) int64
+		want    time.Duration
+	}{
+		{name: "first_min", attempt: 1, jitter: noJitter, want: 500 * time.Millisecond},
+		{name: "first_max", attempt: 1, jitter: fullJitter, want: time.Second},
+		{name: "third_max", attempt: 3, jitter: fullJitter, want: 4 * time.Second},
+		{name: "capped_min", attempt: 8, jitter: noJitter, want: 5 * time.Second},
+		{name: "capped_max", attempt: 8, jitter: fullJitter, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, retryDelay(p, tt.attempt, tt.jitter))
+		})
+	}
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		got := RetryDelay(p, attempt)
+		require.GreaterOrEqual(t, got, 500*time.Millisecond)
+		require.LessOrEqual(t, got, 10*time.Second)
+	}
+}
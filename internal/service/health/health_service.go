@@ -0,0 +1,166 @@
+// Package health contains health service object and methods
+// for checking the readiness of the server to serve the requests.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/infra/database"
+	repo "github.com/pavlegich/scripts-hub/internal/repository"
+	"github.com/pavlegich/scripts-hub/internal/service/queue"
+)
+
+const (
+	// StatusOK is the status of the passed check and the ready server.
+	StatusOK = "ok"
+	// StatusFail is the status of the failed check and the server which is not ready.
+	StatusFail = "fail"
+
+	// CheckTimeout is the maximum duration of the storage checks.
+	CheckTimeout = 2 * time.Second
+)
+
+// Check contains the result of the single readiness check.
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report contains the results of all the readiness checks.
+type Report struct {
+	Status string  `json:"status"`
+	Checks []Check `json:"checks"`
+}
+
+// Service describes methods for checking the readiness of the server.
+//
+//go:generate mockgen -destination=../../mocks/mock_HealthService.go -package=mocks -mock_names=Service=MockHealthService github.com/pavlegich/scripts-hub/internal/service/health Service
+type Service interface {
+	Ready(ctx context.Context) *Report
+	Drain()
+}
+
+// HealthService contains objects for health service.
+type HealthService struct {
+	repo     repo.Repository
+	queues   *queue.Manager
+	stall    time.Duration
+	draining atomic.Bool
+}
+
+// NewHealthService returns new health service. The queue workers are reported
+// stuck when the job waits longer than the stall timeout while the workers
+// are idle, zero stall timeout disables the check.
+func NewHealthService(ctx context.Context, repo repo.Repository, queues *queue.Manager, stall time.Duration) *HealthService {
+	return &HealthService{
+		repo:   repo,
+		queues: queues,
+		stall:  stall,
+	}
+}
+
+// Ready checks the database connection, the migration version,
+// the queue workers and the shutdown state and returns the report,
+// the server is ready when all the checks pass.
+func (s *HealthService) Ready(ctx context.Context) *Report {
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+
+	report := &Report{
+		Status: StatusOK,
+		Checks: []Check{
+			newCheck("database", s.checkDatabase(ctx)),
+			newCheck("migrations", s.checkMigrations(ctx)),
+			newCheck("workers", s.checkWorkers(time.Now())),
+			newCheck("shutdown", s.checkShutdown()),
+		},
+	}
+
+	for _, c := range report.Checks {
+		if c.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+// Drain marks the server as shutting down, so the following readiness
+// checks fail and the load balancers stop sending the traffic.
+func (s *HealthService) Drain() {
+	s.draining.Store(true)
+}
+
+// checkDatabase checks the connection with the database.
+func (s *HealthService) checkDatabase(ctx context.Context) error {
+	err := s.repo.Ping(ctx)
+	if err != nil {
+		return fmt.Errorf("checkDatabase: %w", err)
+	}
+
+	return nil
+}
+
+// checkMigrations checks that the database is migrated to the version
+// of the latest embedded migration.
+func (s *HealthService) checkMigrations(ctx context.Context) error {
+	want, err := database.LatestVersion()
+	if err != nil {
+		return fmt.Errorf("checkMigrations: %w", err)
+	}
+
+	got, err := s.repo.GetMigrationVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("checkMigrations: %w", err)
+	}
+
+	if got != want {
+		return fmt.Errorf("checkMigrations: version %d, expected %d %w", got, want, errs.ErrMigrationVersion)
+	}
+
+	return nil
+}
+
+// checkWorkers checks that all the workers of every queue are running
+// and take the waiting jobs.
+func (s *HealthService) checkWorkers(now time.Time) error {
+	if s.queues == nil {
+		return fmt.Errorf("checkWorkers: no queues %w", errs.ErrWorkersStopped)
+	}
+
+	for _, q := range s.queues.Queues() {
+		if q.Attached() < q.Workers() {
+			return fmt.Errorf("checkWorkers: queue %s has %d of %d workers %w",
+				q.Name(), q.Attached(), q.Workers(), errs.ErrWorkersStopped)
+		}
+		if s.stall > 0 && q.Stalled(now, s.stall) {
+			return fmt.Errorf("checkWorkers: queue %s has jobs waiting longer than %s %w",
+				q.Name(), s.stall, errs.ErrWorkersStalled)
+		}
+	}
+
+	return nil
+}
+
+// checkShutdown checks that the server is not shutting down.
+func (s *HealthService) checkShutdown() error {
+	if s.draining.Load() {
+		return fmt.Errorf("checkShutdown: %w", errs.ErrShuttingDown)
+	}
+
+	return nil
+}
+
+// newCheck returns the result of the check with the specified error.
+func newCheck(name string, err error) Check {
+	if err != nil {
+		return Check{Name: name, Status: StatusFail, Error: err.Error()}
+	}
+
+	return Check{Name: name, Status: StatusOK}
+}
@@ -0,0 +1,44 @@
+package queue
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	depthDesc = prometheus.NewDesc("scripts_hub_queue_depth",
+		"Number of the jobs waiting in the queue.", []string{"queue"}, nil)
+	busyDesc = prometheus.NewDesc("scripts_hub_queue_busy_workers",
+		"Number of the workers of the queue running the jobs.", []string{"queue"}, nil)
+	workersDesc = prometheus.NewDesc("scripts_hub_queue_workers",
+		"Number of the workers of the queue.", []string{"queue"}, nil)
+)
+
+// Collector collects the depth and the busy workers of the queues of the manager.
+type Collector struct {
+	manager *Manager
+}
+
+// NewCollector returns new collector of the queue metrics.
+func NewCollector(m *Manager) *Collector {
+	return &Collector{
+		manager: m,
+	}
+}
+
+// Describe sends the descriptors of the queue metrics.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- depthDesc
+	ch <- busyDesc
+	ch <- workersDesc
+}
+
+// Collect sends the current depth, busy and total workers of every queue.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	if c.manager == nil {
+		return
+	}
+
+	for _, s := range c.manager.Stats() {
+		ch <- prometheus.MustNewConstMetric(depthDesc, prometheus.GaugeValue, float64(s.Depth), s.Name)
+		ch <- prometheus.MustNewConstMetric(busyDesc, prometheus.GaugeValue, float64(s.Active), s.Name)
+		ch <- prometheus.MustNewConstMetric(workersDesc, prometheus.GaugeValue, float64(s.Workers), s.Name)
+	}
+}
@@ -0,0 +1,221 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// GroupHolder identifies the run holding or waiting for the concurrency group.
+type GroupHolder struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// GroupStats contains the current state of the concurrency group.
+type GroupStats struct {
+	Name    string        `json:"name"`
+	Holders []GroupHolder `json:"holders"`
+	Waiting []GroupHolder `json:"waiting"`
+}
+
+// groupWaiter contains the waiting holder and the group it is blocked on.
+type groupWaiter struct {
+	holder GroupHolder
+	group  string
+}
+
+// Groups contains the counting semaphores of the named concurrency groups.
+// The command declares the groups with their capacities and waits
+// until every declared group has less holders than the declared capacity.
+// The holders are told apart by their IDs, so several runs of the same
+// command hold and wait for the groups independently.
+type Groups struct {
+	mu      sync.Mutex
+	holders map[string][]GroupHolder
+	waiting map[string]groupWaiter
+	changed chan struct{}
+}
+
+// NewGroups returns new empty concurrency groups.
+func NewGroups(ctx context.Context) *Groups {
+	return &Groups{
+		holders: make(map[string][]GroupHolder),
+		waiting: make(map[string]groupWaiter),
+		changed: make(chan struct{}),
+	}
+}
+
+// ParseGroup parses the group declaration in the form "name:capacity".
+func ParseGroup(group string) (string, int, error) {
+	name, capacity, ok := strings.Cut(group, ":")
+	if !ok || name == "" {
+		return "", 0, fmt.Errorf("ParseGroup: incorrect group %q", group)
+	}
+
+	n, err := strconv.Atoi(capacity)
+	if err != nil || n < 1 {
+		return "", 0, fmt.Errorf("ParseGroup: incorrect capacity of group %q", name)
+	}
+
+	return name, n, nil
+}
+
+// Acquire waits for the free slot in every declared group and takes
+// all of them at once, so the holders never wait for each other.
+func (g *Groups) Acquire(ctx context.Context, holder GroupHolder, groups []string) error {
+	for {
+		changed := g.Changed()
+
+		ok, err := g.TryAcquire(holder, groups)
+		if err != nil {
+			return fmt.Errorf("Acquire: %w", err)
+		}
+		if ok {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			g.Forget(holder)
+			return fmt.Errorf("Acquire: wait for groups interrupted %w", ctx.Err())
+		case <-changed:
+		}
+	}
+}
+
+// TryAcquire takes the free slot in every declared group at once without waiting.
+// If one of the groups has no free slot, nothing is taken, the holder is counted
+// as waiting for the group until it acquires the groups or is forgotten
+// and false is returned.
+func (g *Groups) TryAcquire(holder GroupHolder, groups []string) (bool, error) {
+	if len(groups) == 0 {
+		return true, nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	blocked, err := g.blockedOn(groups)
+	if err != nil {
+		return false, fmt.Errorf("TryAcquire: %w", err)
+	}
+	if blocked != "" {
+		g.waiting[holder.ID] = groupWaiter{holder: holder, group: blocked}
+		return false, nil
+	}
+
+	for _, group := range groups {
+		name, _, _ := ParseGroup(group)
+		g.holders[name] = append(g.holders[name], holder)
+	}
+	delete(g.waiting, holder.ID)
+
+	return true, nil
+}
+
+// Changed returns the channel closed when the slots are released next time.
+func (g *Groups) Changed() <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.changed
+}
+
+// Forget stops counting the holder as waiting for the groups.
+func (g *Groups) Forget(holder GroupHolder) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.waiting, holder.ID)
+}
+
+// Release frees the slots taken by the holder and wakes up the waiting ones.
+func (g *Groups) Release(holder GroupHolder, groups []string) {
+	if len(groups) == 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, group := range groups {
+		name, _, err := ParseGroup(group)
+		if err != nil {
+			continue
+		}
+
+		holders := g.holders[name]
+		for i, h := range holders {
+			if h.ID == holder.ID {
+				holders = append(holders[:i], holders[i+1:]...)
+				break
+			}
+		}
+		if len(holders) == 0 {
+			delete(g.holders, name)
+		} else {
+			g.holders[name] = holders
+		}
+	}
+
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+// Stats returns the holders and the waiting runs of every used group ordered by name.
+func (g *Groups) Stats() []GroupStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	groups := make(map[string]*GroupStats)
+	get := func(name string) *GroupStats {
+		s, ok := groups[name]
+		if !ok {
+			s = &GroupStats{Name: name, Holders: []GroupHolder{}, Waiting: []GroupHolder{}}
+			groups[name] = s
+		}
+		return s
+	}
+
+	for name, holders := range g.holders {
+		s := get(name)
+		s.Holders = append(s.Holders, holders...)
+	}
+	for _, w := range g.waiting {
+		s := get(w.group)
+		s.Waiting = append(s.Waiting, w.holder)
+	}
+
+	stats := make([]GroupStats, 0, len(groups))
+	for _, s := range groups {
+		sort.Slice(s.Waiting, func(i, j int) bool {
+			return s.Waiting[i].ID < s.Waiting[j].ID
+		})
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+
+	return stats
+}
+
+// blockedOn returns the first declared group without free slot,
+// the groups mutex must be held.
+func (g *Groups) blockedOn(groups []string) (string, error) {
+	for _, group := range groups {
+		name, capacity, err := ParseGroup(group)
+		if err != nil {
+			return "", err
+		}
+		if len(g.holders[name]) >= capacity {
+			return name, nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,189 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGroup(t *testing.T) {
+	tests := []struct {
+		name         string
+		group        string
+		wantName     string
+		wantCapacity int
+		wantErr      bool
+	}{
+		{
+			name:         "success",
+			group:        "db-migrations:1",
+			wantName:     "db-migrations",
+			wantCapacity: 1,
+		},
+		{
+			name:    "no_capacity",
+			group:   "heavy-io",
+			wantErr: true,
+		},
+		{
+			name:    "zero_capacity",
+			group:   "heavy-io:0",
+			wantErr: true,
+		},
+		{
+			name:    "empty_name",
+			group:   ":2",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotName, gotCapacity, err := ParseGroup(tt.group)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantName, gotName)
+			require.Equal(t, tt.wantCapacity, gotCapacity)
+		})
+	}
+}
+
+func TestGroups_Acquire(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroups(ctx)
+
+	first := GroupHolder{ID: "run:1", Name: "first"}
+	second := GroupHolder{ID: "run:2", Name: "second"}
+	third := GroupHolder{ID: "run:3", Name: "third"}
+
+	require.NoError(t, g.Acquire(ctx, first, []string{"heavy-io:2", "db:1"}))
+	require.NoError(t, g.Acquire(ctx, second, []string{"heavy-io:2"}))
+
+	acquired := make(chan struct{})
+	go func() {
+		require.NoError(t, g.Acquire(ctx, third, []string{"db:1"}))
+		close(acquired)
+	}()
+
+	require.Eventually(t, func() bool {
+		stats := g.Stats()
+		return len(stats) == 2 && len(stats[0].Waiting) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []GroupStats{
+		{Name: "db", Holders: []GroupHolder{first}, Waiting: []GroupHolder{third}},
+		{Name: "heavy-io", Holders: []GroupHolder{first, second}, Waiting: []GroupHolder{}},
+	}, g.Stats())
+
+	g.Release(first, []string{"heavy-io:2", "db:1"})
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("third holder did not acquire the group")
+	}
+	require.Equal(t, []GroupStats{
+		{Name: "db", Holders: []GroupHolder{third}, Waiting: []GroupHolder{}},
+		{Name: "heavy-io", Holders: []GroupHolder{second}, Waiting: []GroupHolder{}},
+	}, g.Stats())
+}
+
+func TestGroups_AcquireSameCommand(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroups(ctx)
+
+	first := GroupHolder{ID: "run:1", Name: "migrate"}
+	second := GroupHolder{ID: "run:2", Name: "migrate"}
+	third := GroupHolder{ID: "run:3", Name: "migrate"}
+
+	require.NoError(t, g.Acquire(ctx, first, []string{"x:1"}))
+
+	acquired := make(chan GroupHolder)
+	for _, holder := range []GroupHolder{second, third} {
+		go func(holder GroupHolder) {
+			require.NoError(t, g.Acquire(ctx, holder, []string{"x:1"}))
+			acquired <- holder
+		}(holder)
+	}
+
+	require.Eventually(t, func() bool {
+		stats := g.Stats()
+		return len(stats) == 1 && len(stats[0].Waiting) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []GroupStats{
+		{Name: "x", Holders: []GroupHolder{first}, Waiting: []GroupHolder{second, third}},
+	}, g.Stats())
+
+	// Every waiting run of the command acquires the group in its turn
+	g.Release(first, []string{"x:1"})
+	next := <-acquired
+	g.Release(next, []string{"x:1"})
+	last := <-acquired
+	require.NotEqual(t, next.ID, last.ID)
+
+	require.Equal(t, []GroupStats{
+		{Name: "x", Holders: []GroupHolder{last}, Waiting: []GroupHolder{}},
+	}, g.Stats())
+}
+
+func TestGroups_TryAcquire(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroups(ctx)
+
+	first := GroupHolder{ID: "run:1", Name: "migrate"}
+	second := GroupHolder{ID: "run:2", Name: "migrate"}
+
+	ok, err := g.TryAcquire(first, []string{"x:1"})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	changed := g.Changed()
+	ok, err = g.TryAcquire(second, []string{"x:1"})
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, []GroupStats{
+		{Name: "x", Holders: []GroupHolder{first}, Waiting: []GroupHolder{second}},
+	}, g.Stats())
+
+	g.Release(first, []string{"x:1"})
+	select {
+	case <-changed:
+	default:
+		t.Fatal("release is not signalled")
+	}
+
+	ok, err = g.TryAcquire(second, []string{"x:1"})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// The forgotten holder is not waiting anymore
+	_, err = g.TryAcquire(first, []string{"x:1"})
+	require.NoError(t, err)
+	g.Forget(first)
+	require.Equal(t, []GroupStats{
+		{Name: "x", Holders: []GroupHolder{second}, Waiting: []GroupHolder{}},
+	}, g.Stats())
+
+	_, err = g.TryAcquire(first, []string{"x"})
+	require.Error(t, err)
+}
+
+func TestGroups_AcquireInterrupted(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroups(ctx)
+
+	first := GroupHolder{ID: "run:1", Name: "first"}
+
+	require.NoError(t, g.Acquire(ctx, first, []string{"db:1"}))
+
+	ctxCancel, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	require.Error(t, g.Acquire(ctxCancel, GroupHolder{ID: "run:2", Name: "second"}, []string{"db:1"}))
+	require.Equal(t, []GroupStats{
+		{Name: "db", Holders: []GroupHolder{first}, Waiting: []GroupHolder{}},
+	}, g.Stats())
+}
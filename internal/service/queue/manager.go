@@ -0,0 +1,149 @@
+package queue
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+)
+
+// Manager contains the named queues, each served by its own workers.
+type Manager struct {
+	queues map[string]*Queue
+	names  []string
+}
+
+// NewManager creates the queues with the worker limits from the configuration.
+// The default queue is always created, its limit is taken from the named
+// queues limits or from the rate limit if the named queues do not contain it.
+func NewManager(ctx context.Context, cfg *config.Config) *Manager {
+	m := &Manager{
+		queues: make(map[string]*Queue, len(cfg.Queues)+1),
+	}
+
+	if _, ok := cfg.Queues[DefaultQueue]; !ok {
+		m.queues[DefaultQueue] = NewQueue(ctx, DefaultQueue, cfg.RateLimit, cfg.PriorityAging)
+	}
+	for name, workers := range cfg.Queues {
+		m.queues[name] = NewQueue(ctx, name, workers, cfg.PriorityAging)
+	}
+
+	for name, q := range m.queues {
+		q.maxDepth = cfg.QueueMaxDepth
+		q.maxPerCaller = cfg.QueueCallerLimit
+		m.names = append(m.names, name)
+	}
+	sort.Strings(m.names)
+
+	return m
+}
+
+// Get returns the queue by its name, the empty name means the default queue.
+func (m *Manager) Get(name string) (*Queue, bool) {
+	if name == "" {
+		name = DefaultQueue
+	}
+	q, ok := m.queues[name]
+	return q, ok
+}
+
+// Queues returns all the queues ordered by name.
+func (m *Manager) Queues() []*Queue {
+	queues := make([]*Queue, 0, len(m.names))
+	for _, name := range m.names {
+		queues = append(queues, m.queues[name])
+	}
+	return queues
+}
+
+// Stats returns the current state of all the queues ordered by name.
+func (m *Manager) Stats() []Stats {
+	stats := make([]Stats, 0, len(m.names))
+	for _, q := range m.Queues() {
+		stats = append(stats, q.Stats())
+	}
+	return stats
+}
+
+// Close closes all the queues.
+func (m *Manager) Close() {
+	for _, q := range m.queues {
+		q.Close()
+	}
+}
+
+// Jobs returns the waiting jobs of all the queues ordered by queue name
+// and position.
+func (m *Manager) Jobs() []JobInfo {
+	jobs := make([]JobInfo, 0)
+	for _, q := range m.Queues() {
+		jobs = append(jobs, q.Jobs()...)
+	}
+	return jobs
+}
+
+// Remove removes the waiting job from its queue.
+// It returns false if the job is not waiting in any queue.
+func (m *Manager) Remove(id int) (*Job, bool) {
+	for _, q := range m.queues {
+		if j, ok := q.Remove(id); ok {
+			return j, true
+		}
+	}
+	return nil, false
+}
+
+// Drain removes the waiting jobs from all the queues and returns them
+// ordered by queue name and position.
+func (m *Manager) Drain() []*Job {
+	jobs := make([]*Job, 0)
+	for _, q := range m.Queues() {
+		jobs = append(jobs, q.Drain()...)
+	}
+	return jobs
+}
+
+// MoveToFront moves the waiting job to the front of its queue.
+// It returns false if the job is not waiting in any queue.
+func (m *Manager) MoveToFront(id int) bool {
+	for _, q := range m.queues {
+		if q.MoveToFront(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pause pauses the queue by its name or all the queues if the name is empty.
+// It returns false if the queue is not found.
+func (m *Manager) Pause(name string) bool {
+	if name == "" {
+		for _, q := range m.queues {
+			q.Pause()
+		}
+		return true
+	}
+
+	q, ok := m.queues[name]
+	if ok {
+		q.Pause()
+	}
+	return ok
+}
+
+// Resume resumes the queue by its name or all the queues if the name is empty.
+// It returns false if the queue is not found.
+func (m *Manager) Resume(name string) bool {
+	if name == "" {
+		for _, q := range m.queues {
+			q.Resume()
+		}
+		return true
+	}
+
+	q, ok := m.queues[name]
+	if ok {
+		q.Resume()
+	}
+	return ok
+}
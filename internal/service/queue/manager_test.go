@@ -0,0 +1,76 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pavlegich/scripts-hub/internal/infra/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewManager(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want []Stats
+	}{
+		{
+			name: "default_only",
+			cfg: &config.Config{
+				RateLimit: 3,
+			},
+			want: []Stats{
+				{Name: DefaultQueue, Workers: 3},
+			},
+		},
+		{
+			name: "named_queues",
+			cfg: &config.Config{
+				RateLimit: 3,
+				Queues:    config.QueueLimits{"maintenance": 1, "heavy": 2},
+			},
+			want: []Stats{
+				{Name: DefaultQueue, Workers: 3},
+				{Name: "heavy", Workers: 2},
+				{Name: "maintenance", Workers: 1},
+			},
+		},
+		{
+			name: "default_overridden",
+			cfg: &config.Config{
+				RateLimit: 3,
+				Queues:    config.QueueLimits{DefaultQueue: 5},
+			},
+			want: []Stats{
+				{Name: DefaultQueue, Workers: 5},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(ctx, tt.cfg)
+			require.Equal(t, tt.want, m.Stats())
+		})
+	}
+}
+
+func TestManager_Get(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(ctx, &config.Config{
+		RateLimit: 1,
+		Queues:    config.QueueLimits{"heavy": 1},
+	})
+
+	q, ok := m.Get("")
+	require.True(t, ok)
+	require.Equal(t, DefaultQueue, q.Name())
+
+	q, ok = m.Get("heavy")
+	require.True(t, ok)
+	require.Equal(t, "heavy", q.Name())
+
+	_, ok = m.Get("unknown")
+	require.False(t, ok)
+}
@@ -0,0 +1,591 @@
+// Package queue contains the job queue object and methods for
+// scheduling the commands between the run workers.
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+)
+
+const (
+	// DefaultQueue is the name of the queue used when the command does not specify one.
+	DefaultQueue = "default"
+
+	// MinPriority is the lowest priority the command can be submitted with.
+	MinPriority = 0
+	// MaxPriority is the highest priority the command can be submitted with.
+	MaxPriority = 100
+)
+
+// Job contains the command waiting for the run worker.
+type Job struct {
+	ID         int
+	Caller     string
+	Reserved   bool
+	Command    entities.Command
+	EnqueuedAt time.Time
+	StartedAt  time.Time
+
+	seq     uint64
+	front   uint64
+	index   int
+	blocked bool
+}
+
+// JobInfo contains the state of the waiting job.
+type JobInfo struct {
+	ID             int        `json:"id"`
+	Name           string     `json:"name"`
+	Queue          string     `json:"queue"`
+	Caller         string     `json:"caller,omitempty"`
+	Priority       int        `json:"priority"`
+	Position       int        `json:"position"`
+	EnqueuedAt     time.Time  `json:"enqueued_at"`
+	EstimatedStart *time.Time `json:"estimated_start,omitempty"`
+}
+
+// Queue contains the waiting jobs ordered by their priority.
+// The priority of the waiting job grows by one every aging interval,
+// so the jobs with low priority are not starved by the urgent ones.
+// The number of waiting jobs is bounded by the maximum depth and
+// by the maximum number of jobs of the single caller, zero means no limit.
+type Queue struct {
+	name         string
+	workers      int
+	maxDepth     int
+	maxPerCaller int
+
+	mu       sync.Mutex
+	jobs     jobHeap
+	seq      uint64
+	fronts   uint64
+	active   int
+	attached int
+	reserved int
+	callers  map[string]int
+	avgRun   time.Duration
+	paused   bool
+	closed   bool
+	ready    chan struct{}
+}
+
+// Stats contains the current state of the queue.
+type Stats struct {
+	Name    string `json:"name"`
+	Workers int    `json:"workers"`
+	Depth   int    `json:"depth"`
+	Active  int    `json:"active"`
+	Paused  bool   `json:"paused"`
+}
+
+// NewQueue returns new empty job queue served by the specified number
+// of workers with the specified aging interval. Zero aging interval disables aging.
+func NewQueue(ctx context.Context, name string, workers int, aging time.Duration) *Queue {
+	return &Queue{
+		name:    name,
+		workers: workers,
+		jobs:    jobHeap{aging: aging},
+		callers: make(map[string]int),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+// Name returns the name of the queue.
+func (q *Queue) Name() string {
+	return q.name
+}
+
+// Workers returns the number of workers serving the queue.
+func (q *Queue) Workers() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.workers
+}
+
+// SetWorkers changes the number of workers serving the queue, the workers
+// are started or stopped by the owner of the queue.
+func (q *Queue) SetWorkers(workers int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.workers = workers
+}
+
+// Push puts the command into the queue, the command identifier is used
+// as the job identifier.
+func (q *Queue) Push(c entities.Command) error {
+	return q.PushJob(&Job{ID: c.ID, Command: c})
+}
+
+// Reserve takes the place in the queue for the job of the caller before
+// the job is created, so the job is not rejected when it is pushed.
+// The job must be pushed with the Reserved flag or the reservation cancelled.
+func (q *Queue) Reserve(caller string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	err := q.admit(caller)
+	if err != nil {
+		return fmt.Errorf("Reserve: %w", err)
+	}
+
+	q.reserved++
+	q.callers[caller]++
+
+	return nil
+}
+
+// CancelReservation frees the place reserved for the job of the caller.
+func (q *Queue) CancelReservation(caller string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.reserved--
+	q.leave(caller)
+}
+
+// PushJob puts the job into the queue. If the job enqueue time is empty,
+// the current time is used. The job without reservation is rejected
+// when the queue limits are reached.
+func (q *Queue) PushJob(j *Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if j.Reserved {
+		q.reserved--
+		j.Reserved = false
+	} else {
+		err := q.admit(j.Caller)
+		if err != nil {
+			return fmt.Errorf("PushJob: %w", err)
+		}
+		q.callers[j.Caller]++
+	}
+
+	if q.closed {
+		q.leave(j.Caller)
+		return fmt.Errorf("PushJob: %w", errs.ErrQueueClosed)
+	}
+
+	if j.EnqueuedAt.IsZero() {
+		j.EnqueuedAt = time.Now()
+	}
+	q.seq++
+	j.seq = q.seq
+
+	heap.Push(&q.jobs, j)
+	q.signal()
+
+	return nil
+}
+
+// Pop waits for the job with the highest priority, removes it from the queue
+// and counts it as active until Done is called. While the queue is paused,
+// the jobs are kept waiting. It returns false when the context is done
+// or the queue is closed.
+func (q *Queue) Pop(ctx context.Context) (*Job, bool) {
+	return q.PopFunc(ctx, nil, nil)
+}
+
+// PopFunc works as Pop, but hands out the job with the highest priority
+// accepted by the take function, the refused jobs keep waiting and let
+// the next ones pass. The take function is called with the queue mutex held.
+// The worker waiting while all the jobs are refused is woken up also
+// when the channel returned by the wake function is closed.
+func (q *Queue) PopFunc(ctx context.Context, take func(j *Job) bool, wake func() <-chan struct{}) (*Job, bool) {
+	for {
+		var woken <-chan struct{}
+		if wake != nil {
+			woken = wake()
+		}
+
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return nil, false
+		}
+		if !q.paused && q.jobs.Len() > 0 {
+			if j := q.next(take); j != nil {
+				heap.Remove(&q.jobs, j.index)
+				q.leave(j.Caller)
+				j.StartedAt = time.Now()
+				q.active++
+				if q.jobs.Len() > 0 {
+					q.signal()
+				}
+				q.mu.Unlock()
+				return j, true
+			}
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-q.ready:
+		case <-woken:
+		}
+	}
+}
+
+// next returns the first job accepted by the take function in the order
+// of the priority or nil if all the jobs are refused. The refused jobs
+// are marked as blocked, the queue mutex must be held.
+func (q *Queue) next(take func(j *Job) bool) *Job {
+	if take == nil {
+		return q.jobs.jobs[0]
+	}
+
+	jobs := make([]*Job, len(q.jobs.jobs))
+	copy(jobs, q.jobs.jobs)
+	sort.Slice(jobs, func(i, j int) bool {
+		return q.jobs.less(jobs[i], jobs[j])
+	})
+
+	for _, j := range jobs {
+		if take(j) {
+			j.blocked = false
+			return j
+		}
+		j.blocked = true
+	}
+
+	return nil
+}
+
+// Done marks the job taken by Pop as finished and takes its run duration
+// into account for estimating the start of the waiting jobs.
+func (q *Queue) Done(j *Job) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.active--
+
+	d := time.Since(j.StartedAt)
+	if q.avgRun == 0 {
+		q.avgRun = d
+	} else {
+		q.avgRun = (q.avgRun*4 + d) / 5
+	}
+}
+
+// Remove removes the waiting job from the queue.
+// It returns false if the job is not waiting in the queue.
+func (q *Queue) Remove(id int) (*Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j := q.jobs.find(id)
+	if j == nil {
+		return nil, false
+	}
+	heap.Remove(&q.jobs, j.index)
+	q.leave(j.Caller)
+
+	return j, true
+}
+
+// Drain removes all the waiting jobs from the queue and returns them
+// in the order they would be taken by the workers.
+func (q *Queue) Drain() []*Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]*Job, 0, q.jobs.Len())
+	for q.jobs.Len() > 0 {
+		j := heap.Pop(&q.jobs).(*Job)
+		q.leave(j.Caller)
+		jobs = append(jobs, j)
+	}
+
+	return jobs
+}
+
+// MoveToFront moves the waiting job to the front of the queue, so it is
+// taken by the next free worker. It returns false if the job is not waiting in the queue.
+func (q *Queue) MoveToFront(id int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j := q.jobs.find(id)
+	if j == nil {
+		return false
+	}
+	q.fronts++
+	j.front = q.fronts
+	heap.Fix(&q.jobs, j.index)
+
+	return true
+}
+
+// Pause stops handing out the waiting jobs, the running jobs are not affected.
+func (q *Queue) Pause() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.paused = true
+}
+
+// Resume continues handing out the waiting jobs.
+func (q *Queue) Resume() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.paused = false
+	q.signal()
+}
+
+// Jobs returns the waiting jobs in the order they will be run with their
+// estimated start time. The start time is estimated from the average run
+// duration of the queue and is not known while the queue is paused
+// or no jobs have finished yet.
+func (q *Queue) Jobs() []JobInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]*Job, len(q.jobs.jobs))
+	copy(jobs, q.jobs.jobs)
+	sort.Slice(jobs, func(i, j int) bool {
+		return q.jobs.less(jobs[i], jobs[j])
+	})
+
+	now := time.Now()
+	free := q.workers - q.active
+
+	infos := make([]JobInfo, 0, len(jobs))
+	for i, j := range jobs {
+		info := JobInfo{
+			ID:         j.ID,
+			Name:       j.Command.Name,
+			Queue:      q.name,
+			Caller:     j.Caller,
+			Priority:   j.Command.Priority,
+			Position:   i + 1,
+			EnqueuedAt: j.EnqueuedAt,
+		}
+
+		switch {
+		case q.paused || q.workers < 1:
+		case i < free:
+			start := now
+			info.EstimatedStart = &start
+		case q.avgRun > 0:
+			waves := (i-free)/q.workers + 1
+			start := now.Add(time.Duration(waves) * q.avgRun)
+			info.EstimatedStart = &start
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// Stats returns the current depth and the number of active jobs of the queue.
+func (q *Queue) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return Stats{
+		Name:    q.name,
+		Workers: q.workers,
+		Depth:   q.jobs.Len(),
+		Active:  q.active,
+		Paused:  q.paused,
+	}
+}
+
+// RetryAfter returns the time after which the rejected job is likely
+// to be admitted, it is estimated from the average run duration of the queue.
+func (q *Queue) RetryAfter() time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	after := time.Second
+	if q.workers > 0 && q.avgRun/time.Duration(q.workers) > after {
+		after = q.avgRun / time.Duration(q.workers)
+	}
+
+	return after.Round(time.Second)
+}
+
+// Attach counts the worker serving the queue until Detach is called.
+func (q *Queue) Attach() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.attached++
+}
+
+// Detach forgets the worker which stopped serving the queue.
+func (q *Queue) Detach() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.attached--
+}
+
+// Attached returns the number of the workers serving the queue.
+func (q *Queue) Attached() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.attached
+}
+
+// Stalled reports whether the queue is not paused, has the idle workers
+// and still has the job waiting longer than the specified duration,
+// which means that the workers do not take the jobs. The jobs refused
+// by the workers are not taken into account.
+func (q *Queue) Stalled(now time.Time, after time.Duration) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.paused || q.closed || q.active >= q.workers {
+		return false
+	}
+
+	for _, j := range q.jobs.jobs {
+		if !j.blocked && now.Sub(j.EnqueuedAt) > after {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Len returns the number of waiting jobs.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.jobs.Len()
+}
+
+// Close closes the queue and wakes up the waiting workers.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.ready)
+}
+
+// admit checks the queue limits for the new job of the caller,
+// the queue mutex must be held.
+func (q *Queue) admit(caller string) error {
+	if q.closed {
+		return errs.ErrQueueClosed
+	}
+	if q.maxDepth > 0 && q.jobs.Len()+q.reserved >= q.maxDepth {
+		return errs.ErrQueueFull
+	}
+	if q.maxPerCaller > 0 && caller != "" && q.callers[caller] >= q.maxPerCaller {
+		return errs.ErrQueueCallerLimit
+	}
+
+	return nil
+}
+
+// leave forgets the job of the caller that left the queue,
+// the queue mutex must be held.
+func (q *Queue) leave(caller string) {
+	q.callers[caller]--
+	if q.callers[caller] <= 0 {
+		delete(q.callers, caller)
+	}
+}
+
+// signal wakes up one of the waiting workers, the queue mutex must be held.
+func (q *Queue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// jobHeap implements heap.Interface for the waiting jobs.
+type jobHeap struct {
+	aging time.Duration
+	jobs  []*Job
+}
+
+// Len returns the number of jobs in the heap.
+func (h jobHeap) Len() int {
+	return len(h.jobs)
+}
+
+// Less reports whether the job i should be run before the job j.
+func (h jobHeap) Less(i, j int) bool {
+	return h.less(h.jobs[i], h.jobs[j])
+}
+
+// less reports whether the job a should be run before the job b.
+// The jobs moved to the front go first, the latest moved the earliest.
+// With aging, the job with priority p is treated as enqueued p aging
+// intervals earlier, so the order does not depend on the current time.
+func (h jobHeap) less(a, b *Job) bool {
+	if a.front != b.front {
+		return a.front > b.front
+	}
+
+	if h.aging > 0 {
+		at := a.EnqueuedAt.Add(-time.Duration(a.Command.Priority) * h.aging)
+		bt := b.EnqueuedAt.Add(-time.Duration(b.Command.Priority) * h.aging)
+		if !at.Equal(bt) {
+			return at.Before(bt)
+		}
+	} else if a.Command.Priority != b.Command.Priority {
+		return a.Command.Priority > b.Command.Priority
+	}
+
+	return a.seq < b.seq
+}
+
+// Swap swaps the jobs with indexes i and j.
+func (h jobHeap) Swap(i, j int) {
+	h.jobs[i], h.jobs[j] = h.jobs[j], h.jobs[i]
+	h.jobs[i].index = i
+	h.jobs[j].index = j
+}
+
+// Push appends new job to the heap.
+func (h *jobHeap) Push(x any) {
+	j := x.(*Job)
+	j.index = len(h.jobs)
+	h.jobs = append(h.jobs, j)
+}
+
+// Pop removes the last job from the heap.
+func (h *jobHeap) Pop() any {
+	n := len(h.jobs)
+	j := h.jobs[n-1]
+	h.jobs[n-1] = nil
+	j.index = -1
+	h.jobs = h.jobs[:n-1]
+	return j
+}
+
+// find returns the job with the specified identifier or nil if it is not in the heap.
+func (h jobHeap) find(id int) *Job {
+	for _, j := range h.jobs {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
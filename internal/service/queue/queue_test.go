@@ -0,0 +1,307 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_Pop(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		aging time.Duration
+		jobs  []*Job
+		want  []string
+	}{
+		{
+			name:  "fifo_with_equal_priority",
+			aging: 0,
+			jobs: []*Job{
+				{Command: entities.Command{Name: "first"}},
+				{Command: entities.Command{Name: "second"}},
+				{Command: entities.Command{Name: "third"}},
+			},
+			want: []string{"first", "second", "third"},
+		},
+		{
+			name:  "highest_priority_first",
+			aging: 0,
+			jobs: []*Job{
+				{Command: entities.Command{Name: "low", Priority: 1}},
+				{Command: entities.Command{Name: "high", Priority: 10}},
+				{Command: entities.Command{Name: "middle", Priority: 5}},
+			},
+			want: []string{"high", "middle", "low"},
+		},
+		{
+			name:  "aged_job_overtakes_urgent",
+			aging: time.Second,
+			jobs: []*Job{
+				{Command: entities.Command{Name: "urgent", Priority: 5}, EnqueuedAt: now},
+				{Command: entities.Command{Name: "old", Priority: 0}, EnqueuedAt: now.Add(-time.Minute)},
+			},
+			want: []string{"old", "urgent"},
+		},
+		{
+			name:  "young_job_waits_for_urgent",
+			aging: time.Minute,
+			jobs: []*Job{
+				{Command: entities.Command{Name: "urgent", Priority: 5}, EnqueuedAt: now},
+				{Command: entities.Command{Name: "young", Priority: 0}, EnqueuedAt: now.Add(-time.Minute)},
+			},
+			want: []string{"urgent", "young"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewQueue(ctx, DefaultQueue, 1, tt.aging)
+			for _, j := range tt.jobs {
+				require.NoError(t, q.PushJob(j))
+			}
+
+			got := make([]string, 0, len(tt.want))
+			for q.Len() > 0 {
+				j, ok := q.Pop(ctx)
+				require.True(t, ok)
+				got = append(got, j.Command.Name)
+			}
+
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQueue_PopWaits(t *testing.T) {
+	ctx := context.Background()
+	q := NewQueue(ctx, DefaultQueue, 1, 0)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.Push(entities.Command{Name: "late"})
+	}()
+
+	j, ok := q.Pop(ctx)
+	require.True(t, ok)
+	require.Equal(t, "late", j.Command.Name)
+}
+
+func TestQueue_PopFunc(t *testing.T) {
+	ctx := context.Background()
+	q := NewQueue(ctx, DefaultQueue, 2, 0)
+
+	require.NoError(t, q.PushJob(&Job{ID: 1, Command: entities.Command{Name: "blocked", Priority: 10}}))
+	require.NoError(t, q.PushJob(&Job{ID: 2, Command: entities.Command{Name: "free"}}))
+
+	wake := make(chan struct{})
+	blocked := true
+	take := func(j *Job) bool {
+		return j.Command.Name != "blocked" || !blocked
+	}
+	wakeFunc := func() <-chan struct{} {
+		return wake
+	}
+
+	// The refused job lets the next one pass and keeps waiting
+	j, ok := q.PopFunc(ctx, take, wakeFunc)
+	require.True(t, ok)
+	require.Equal(t, "free", j.Command.Name)
+	require.Equal(t, 1, q.Len())
+	require.False(t, q.Stalled(time.Now().Add(time.Hour), time.Minute), "refused job is not stalled")
+
+	// The worker waiting for the refused job is woken up by the wake channel
+	popped := make(chan *Job)
+	go func() {
+		j, _ := q.PopFunc(ctx, take, wakeFunc)
+		popped <- j
+	}()
+
+	select {
+	case <-popped:
+		t.Fatal("refused job is popped")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	q.mu.Lock()
+	blocked = false
+	q.mu.Unlock()
+	close(wake)
+
+	select {
+	case j := <-popped:
+		require.Equal(t, "blocked", j.Command.Name)
+	case <-time.After(time.Second):
+		t.Fatal("worker is not woken up")
+	}
+}
+
+func TestQueue_Close(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("waiting_worker_released", func(t *testing.T) {
+		q := NewQueue(ctx, DefaultQueue, 1, 0)
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			q.Close()
+		}()
+
+		_, ok := q.Pop(ctx)
+		require.False(t, ok)
+	})
+
+	t.Run("push_into_closed", func(t *testing.T) {
+		q := NewQueue(ctx, DefaultQueue, 1, 0)
+		q.Close()
+
+		require.ErrorIs(t, q.Push(entities.Command{Name: "closed"}), errs.ErrQueueClosed)
+	})
+
+	t.Run("context_done", func(t *testing.T) {
+		q := NewQueue(ctx, DefaultQueue, 1, 0)
+		ctxCancel, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		_, ok := q.Pop(ctxCancel)
+		require.False(t, ok)
+	})
+}
+
+func TestQueue_Stats(t *testing.T) {
+	ctx := context.Background()
+	q := NewQueue(ctx, "heavy", 2, 0)
+
+	q.Push(entities.Command{Name: "first"})
+	q.Push(entities.Command{Name: "second"})
+	require.Equal(t, Stats{Name: "heavy", Workers: 2, Depth: 2, Active: 0}, q.Stats())
+
+	j, ok := q.Pop(ctx)
+	require.True(t, ok)
+	require.Equal(t, Stats{Name: "heavy", Workers: 2, Depth: 1, Active: 1}, q.Stats())
+
+	q.Done(j)
+	require.Equal(t, Stats{Name: "heavy", Workers: 2, Depth: 1, Active: 0}, q.Stats())
+
+	q.SetWorkers(4)
+	require.Equal(t, 4, q.Workers())
+	require.Equal(t, Stats{Name: "heavy", Workers: 4, Depth: 1, Active: 0}, q.Stats())
+}
+
+func TestQueue_Manage(t *testing.T) {
+	ctx := context.Background()
+
+	names := func(jobs []JobInfo) []string {
+		got := make([]string, 0, len(jobs))
+		for _, j := range jobs {
+			got = append(got, j.Name)
+		}
+		return got
+	}
+
+	q := NewQueue(ctx, DefaultQueue, 1, 0)
+	q.Push(entities.Command{ID: 1, Name: "first"})
+	q.Push(entities.Command{ID: 2, Name: "second", Priority: 5})
+	q.Push(entities.Command{ID: 3, Name: "third"})
+	require.Equal(t, []string{"second", "first", "third"}, names(q.Jobs()))
+
+	require.True(t, q.MoveToFront(3))
+	require.Equal(t, []string{"third", "second", "first"}, names(q.Jobs()))
+	require.False(t, q.MoveToFront(4))
+
+	j, ok := q.Remove(2)
+	require.True(t, ok)
+	require.Equal(t, "second", j.Command.Name)
+	require.Equal(t, []string{"third", "first"}, names(q.Jobs()))
+	_, ok = q.Remove(2)
+	require.False(t, ok)
+
+	q.Pause()
+	require.Nil(t, q.Jobs()[0].EstimatedStart)
+
+	ctxPaused, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, ok = q.Pop(ctxPaused)
+	require.False(t, ok)
+
+	q.Resume()
+	require.NotNil(t, q.Jobs()[0].EstimatedStart)
+
+	j, ok = q.Pop(ctx)
+	require.True(t, ok)
+	require.Equal(t, "third", j.Command.Name)
+}
+
+func TestQueue_Limits(t *testing.T) {
+	ctx := context.Background()
+
+	q := NewQueue(ctx, DefaultQueue, 1, 0)
+	q.maxDepth = 3
+	q.maxPerCaller = 2
+
+	require.NoError(t, q.Reserve("client"))
+	require.NoError(t, q.PushJob(&Job{ID: 1, Caller: "client", Reserved: true}))
+	require.NoError(t, q.PushJob(&Job{ID: 2, Caller: "client"}))
+	require.ErrorIs(t, q.Reserve("client"), errs.ErrQueueCallerLimit)
+
+	require.NoError(t, q.Reserve("other"))
+	require.ErrorIs(t, q.PushJob(&Job{ID: 3, Caller: "another"}), errs.ErrQueueFull)
+
+	q.CancelReservation("other")
+	require.NoError(t, q.PushJob(&Job{ID: 3, Caller: "another"}))
+	require.ErrorIs(t, q.Reserve("other"), errs.ErrQueueFull)
+
+	_, ok := q.Pop(ctx)
+	require.True(t, ok)
+	require.NoError(t, q.Reserve("client"))
+
+	_, ok = q.Remove(3)
+	require.True(t, ok)
+	require.NoError(t, q.PushJob(&Job{ID: 4, Caller: "another"}))
+	require.Equal(t, 2, q.Len())
+}
+
+func TestQueue_Stalled(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	q := NewQueue(ctx, DefaultQueue, 1, 0)
+	require.False(t, q.Stalled(now, time.Minute))
+
+	require.NoError(t, q.PushJob(&Job{ID: 1, EnqueuedAt: now.Add(-time.Second)}))
+	require.False(t, q.Stalled(now, time.Minute))
+
+	require.NoError(t, q.PushJob(&Job{ID: 2, EnqueuedAt: now.Add(-2 * time.Minute)}))
+	require.True(t, q.Stalled(now, time.Minute))
+
+	q.Pause()
+	require.False(t, q.Stalled(now, time.Minute))
+	q.Resume()
+
+	_, ok := q.Pop(ctx)
+	require.True(t, ok)
+	require.False(t, q.Stalled(now, time.Minute), "all the workers are busy")
+}
+
+func TestQueue_Drain(t *testing.T) {
+	ctx := context.Background()
+
+	q := NewQueue(ctx, DefaultQueue, 1, 0)
+	require.NoError(t, q.PushJob(&Job{ID: 1, Caller: "client", Command: entities.Command{Name: "low"}}))
+	require.NoError(t, q.PushJob(&Job{ID: 2, Caller: "client", Command: entities.Command{Name: "high", Priority: 10}}))
+	q.Close()
+
+	jobs := q.Drain()
+	require.Len(t, jobs, 2)
+	require.Equal(t, "high", jobs[0].Command.Name)
+	require.Equal(t, "low", jobs[1].Command.Name)
+	require.Zero(t, q.Len())
+
+	_, ok := q.Pop(ctx)
+	require.False(t, ok)
+}
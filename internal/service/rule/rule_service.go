@@ -0,0 +1,157 @@
+// Package rule contains rule service object and methods for interaction
+// between handlers and repositories, checking the rules for the cycles
+// and matching the finished runs with the rules.
+package rule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	repo "github.com/pavlegich/scripts-hub/internal/repository"
+)
+
+// MaxChainDepth is the maximum number of the runs queued by the rules one after
+// another, it stops the chains of the rules created before the cycle check.
+const MaxChainDepth = 10
+
+// Service describes methods for communication between
+// handlers and repositories for the rules.
+//
+//go:generate mockgen -destination=../../mocks/mock_RuleService.go -package=mocks -mock_names=Service=MockRuleService github.com/pavlegich/scripts-hub/internal/service/rule Service
+type Service interface {
+	Create(ctx context.Context, rule *entities.Rule) (int, error)
+	List(ctx context.Context) ([]*entities.Rule, error)
+	SetEnabled(ctx context.Context, id int, enabled bool) error
+	Match(ctx context.Context, run *entities.Run) ([]*entities.Rule, error)
+}
+
+// RuleService contains objects for rule service.
+type RuleService struct {
+	repo repo.Repository
+}
+
+// NewRuleService returns new rule service.
+func NewRuleService(ctx context.Context, repo repo.Repository) *RuleService {
+	return &RuleService{
+		repo: repo,
+	}
+}
+
+// Create validates the rule, checks that it does not close the cycle
+// of the enabled rules and requests repository to put it into the storage.
+func (s *RuleService) Create(ctx context.Context, rule *entities.Rule) (int, error) {
+	if rule.Command == "" || rule.Target == "" {
+		return -1, fmt.Errorf("Create: command or target empty %w", errs.ErrRuleIncorrect)
+	}
+	if !isEvent(rule.On) {
+		return -1, fmt.Errorf("Create: unknown event %s %w", rule.On, errs.ErrRuleIncorrect)
+	}
+
+	err := s.checkCycle(ctx, rule)
+	if err != nil {
+		return -1, fmt.Errorf("Create: %w", err)
+	}
+
+	rule.Enabled = true
+	created, err := s.repo.CreateRule(ctx, rule)
+	if err != nil {
+		return -1, fmt.Errorf("Create: create rule failed %w", err)
+	}
+
+	return created.ID, nil
+}
+
+// List returns all the rules.
+func (s *RuleService) List(ctx context.Context) ([]*entities.Rule, error) {
+	rules, err := s.repo.GetAllRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("List: get rules failed %w", err)
+	}
+
+	return rules, nil
+}
+
+// SetEnabled enables or disables the rule, the enabled rule must not
+// close the cycle of the other enabled rules.
+func (s *RuleService) SetEnabled(ctx context.Context, id int, enabled bool) error {
+	if enabled {
+		rule, err := s.repo.GetRuleByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("SetEnabled: get rule failed %w", err)
+		}
+
+		err = s.checkCycle(ctx, rule)
+		if err != nil {
+			return fmt.Errorf("SetEnabled: %w", err)
+		}
+	}
+
+	err := s.repo.SetRuleEnabled(ctx, id, enabled)
+	if err != nil {
+		return fmt.Errorf("SetEnabled: set rule enabled failed %w", err)
+	}
+
+	return nil
+}
+
+// Match returns the enabled rules of the command of the finished run
+// which event is the status of the run.
+func (s *RuleService) Match(ctx context.Context, run *entities.Run) ([]*entities.Rule, error) {
+	if !isEvent(run.Status) {
+		return nil, nil
+	}
+
+	rules, err := s.repo.GetEnabledRules(ctx, run.Name, run.Status)
+	if err != nil {
+		return nil, fmt.Errorf("Match: get enabled rules failed %w", err)
+	}
+
+	return rules, nil
+}
+
+// checkCycle returns error if the rule and the other enabled rules form the cycle,
+// the events of the rules are not considered.
+func (s *RuleService) checkCycle(ctx context.Context, rule *entities.Rule) error {
+	rules, err := s.repo.GetAllRules(ctx)
+	if err != nil && !errors.Is(err, errs.ErrRuleNotFound) {
+		return fmt.Errorf("checkCycle: get rules failed %w", err)
+	}
+
+	targets := make(map[string][]string, len(rules))
+	for _, r := range rules {
+		if r.Enabled && r.ID != rule.ID {
+			targets[r.Command] = append(targets[r.Command], r.Target)
+		}
+	}
+
+	visited := make(map[string]bool)
+	stack := []string{rule.Target}
+	for len(stack) > 0 {
+		name := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if name == rule.Command {
+			return fmt.Errorf("checkCycle: %s is queued again by its rules %w", rule.Command, errs.ErrRuleCycle)
+		}
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+		stack = append(stack, targets[name]...)
+	}
+
+	return nil
+}
+
+// isEvent reports whether the run status is the event of the rules.
+func isEvent(status string) bool {
+	switch status {
+	case entities.RunSucceeded, entities.RunFailed, entities.RunCancelled:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,142 @@
+package rule
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleService_Create(t *testing.T) {
+	ctx := context.Background()
+
+	existing := []*entities.Rule{
+		{ID: 1, Command: "build", On: entities.RunSucceeded, Target: "test", Enabled: true},
+		{ID: 2, Command: "test", On: entities.RunSucceeded, Target: "deploy", Enabled: true},
+		{ID: 3, Command: "deploy", On: entities.RunFailed, Target: "rollback", Enabled: false},
+	}
+
+	tests := []struct {
+		name    string
+		rule    *entities.Rule
+		rules   []*entities.Rule
+		wantErr error
+	}{
+		{
+			name:  "created",
+			rule:  &entities.Rule{Command: "deploy", On: entities.RunFailed, Target: "notify"},
+			rules: existing,
+		},
+		{
+			name: "first_rule",
+			rule: &entities.Rule{Command: "build", On: entities.RunCancelled, Target: "cleanup"},
+		},
+		{
+			name:    "unknown_event",
+			rule:    &entities.Rule{Command: "build", On: "timed_out", Target: "test"},
+			wantErr: errs.ErrRuleIncorrect,
+		},
+		{
+			name:    "empty_target",
+			rule:    &entities.Rule{Command: "build", On: entities.RunSucceeded},
+			wantErr: errs.ErrRuleIncorrect,
+		},
+		{
+			name:    "self_loop",
+			rule:    &entities.Rule{Command: "build", On: entities.RunFailed, Target: "build"},
+			rules:   existing,
+			wantErr: errs.ErrRuleCycle,
+		},
+		{
+			name:    "cycle",
+			rule:    &entities.Rule{Command: "deploy", On: entities.RunFailed, Target: "build"},
+			rules:   existing,
+			wantErr: errs.ErrRuleCycle,
+		},
+		{
+			name:  "disabled_rule_not_in_cycle",
+			rule:  &entities.Rule{Command: "rollback", On: entities.RunSucceeded, Target: "deploy"},
+			rules: existing,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockRepo := mocks.NewMockRepository(mockCtrl)
+
+			if tt.wantErr != errs.ErrRuleIncorrect {
+				var err error
+				if tt.rules == nil {
+					err = errs.ErrRuleNotFound
+				}
+				mockRepo.EXPECT().GetAllRules(gomock.Any()).Return(tt.rules, err).Times(1)
+			}
+			if tt.wantErr == nil {
+				mockRepo.EXPECT().CreateRule(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, r *entities.Rule) (*entities.Rule, error) {
+						require.True(t, r.Enabled)
+						r.ID = 10
+						return r, nil
+					}).Times(1)
+			}
+
+			id, err := NewRuleService(ctx, mockRepo).Create(ctx, tt.rule)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 10, id)
+		})
+	}
+}
+
+func TestRuleService_SetEnabled(t *testing.T) {
+	ctx := context.Background()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	s := NewRuleService(ctx, mockRepo)
+
+	rules := []*entities.Rule{
+		{ID: 1, Command: "build", On: entities.RunSucceeded, Target: "test", Enabled: true},
+		{ID: 2, Command: "test", On: entities.RunFailed, Target: "build", Enabled: false},
+	}
+
+	mockRepo.EXPECT().SetRuleEnabled(gomock.Any(), 1, false).Return(nil).Times(1)
+	require.NoError(t, s.SetEnabled(ctx, 1, false))
+
+	mockRepo.EXPECT().GetRuleByID(gomock.Any(), 2).Return(rules[1], nil).Times(1)
+	mockRepo.EXPECT().GetAllRules(gomock.Any()).Return(rules, nil).Times(1)
+	require.ErrorIs(t, s.SetEnabled(ctx, 2, true), errs.ErrRuleCycle)
+
+	mockRepo.EXPECT().GetRuleByID(gomock.Any(), 3).Return(nil, errs.ErrRuleNotFound).Times(1)
+	require.ErrorIs(t, s.SetEnabled(ctx, 3, true), errs.ErrRuleNotFound)
+}
+
+func TestRuleService_Match(t *testing.T) {
+	ctx := context.Background()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	s := NewRuleService(ctx, mockRepo)
+
+	want := []*entities.Rule{{ID: 1, Command: "build", On: entities.RunFailed, Target: "notify", Enabled: true}}
+	mockRepo.EXPECT().GetEnabledRules(gomock.Any(), "build", entities.RunFailed).Return(want, nil).Times(1)
+
+	got, err := s.Match(ctx, &entities.Run{Name: "build", Status: entities.RunFailed})
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+
+	got, err = s.Match(ctx, &entities.Run{Name: "build", Status: entities.RunSkipped})
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
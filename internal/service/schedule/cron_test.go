@@ -0,0 +1,188 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		timezone string
+		wantErr  bool
+	}{
+		{
+			name: "every_minute",
+			expr: "* * * * *",
+		},
+		{
+			name: "lists_ranges_steps",
+			expr: "0,30 9-18/2 1-15 */3 1-5",
+		},
+		{
+			name: "names",
+			expr: "0 12 * jan-jun mon,fri",
+		},
+		{
+			name: "macro",
+			expr: "@daily",
+		},
+		{
+			name:     "timezone",
+			expr:     "0 9 * * *",
+			timezone: "Europe/Moscow",
+		},
+		{
+			name:    "wrong_fields_number",
+			expr:    "* * * *",
+			wantErr: true,
+		},
+		{
+			name:    "out_of_range",
+			expr:    "60 * * * *",
+			wantErr: true,
+		},
+		{
+			name:    "wrong_step",
+			expr:    "*/0 * * * *",
+			wantErr: true,
+		},
+		{
+			name:    "unknown_name",
+			expr:    "0 0 * * foo",
+			wantErr: true,
+		},
+		{
+			name:     "unknown_timezone",
+			expr:     "* * * * *",
+			timezone: "Mars/Olympus",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCron(tt.expr, tt.timezone)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrScheduleIncorrect)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	from := time.Date(2024, time.April, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     string
+		timezone string
+		from     time.Time
+		want     time.Time
+	}{
+		{
+			name: "every_minute",
+			expr: "* * * * *",
+			want: time.Date(2024, time.April, 15, 10, 8, 0, 0, time.UTC),
+		},
+		{
+			name: "every_fifteen_minutes",
+			expr: "*/15 * * * *",
+			want: time.Date(2024, time.April, 15, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name: "next_day",
+			expr: "0 9 * * *",
+			want: time.Date(2024, time.April, 16, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "sunday_as_seven",
+			expr: "0 0 * * 7",
+			want: time.Date(2024, time.April, 21, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "day_of_month_or_day_of_week",
+			expr: "0 0 20 * fri",
+			want: time.Date(2024, time.April, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "leap_day",
+			expr: "0 0 29 2 *",
+			want: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "timezone",
+			expr:     "0 12 * * *",
+			timezone: "Europe/Moscow",
+			want:     time.Date(2024, time.April, 16, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "fall_back",
+			expr:     "0 3 * * *",
+			timezone: "America/New_York",
+			from:     time.Date(2024, time.November, 3, 4, 30, 0, 0, time.UTC),
+			want:     time.Date(2024, time.November, 3, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "fall_back_repeated_hour",
+			expr:     "0 0 * * *",
+			timezone: "America/New_York",
+			from:     time.Date(2024, time.November, 3, 5, 30, 0, 0, time.UTC),
+			want:     time.Date(2024, time.November, 4, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "fall_back_daily",
+			expr:     "@daily",
+			timezone: "America/New_York",
+			from:     time.Date(2024, time.November, 3, 4, 30, 0, 0, time.UTC),
+			want:     time.Date(2024, time.November, 4, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "spring_forward",
+			expr:     "0 3 * * *",
+			timezone: "America/New_York",
+			from:     time.Date(2024, time.March, 10, 6, 30, 0, 0, time.UTC),
+			want:     time.Date(2024, time.March, 10, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "spring_forward_skipped_hour",
+			expr:     "30 2 * * *",
+			timezone: "America/New_York",
+			from:     time.Date(2024, time.March, 10, 6, 30, 0, 0, time.UTC),
+			want:     time.Date(2024, time.March, 11, 6, 30, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr, tt.timezone)
+			require.NoError(t, err)
+
+			start := from
+			if !tt.from.IsZero() {
+				start = tt.from
+			}
+
+			got := c.Next(start)
+			require.True(t, tt.want.Equal(got), "Next() = %v, want %v", got, tt.want)
+		})
+	}
+}
+
+func TestCron_NextN(t *testing.T) {
+	from := time.Date(2024, time.April, 15, 10, 7, 30, 0, time.UTC)
+
+	c, err := ParseCron("0 */6 * * *", "")
+	require.NoError(t, err)
+
+	got := c.NextN(from, 3)
+	require.Equal(t, []time.Time{
+		time.Date(2024, time.April, 15, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, time.April, 15, 18, 0, 0, 0, time.UTC),
+		time.Date(2024, time.April, 16, 0, 0, 0, 0, time.UTC),
+	}, got)
+}
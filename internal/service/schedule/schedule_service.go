@@ -0,0 +1,130 @@
+// Package schedule contains schedule service object and methods for interaction
+// between handlers and repositories, cron expressions parser and the scheduler
+// running the commands by their schedules.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	repo "github.com/pavlegich/scripts-hub/internal/repository"
+)
+
+// Service describes methods for communication between
+// handlers and repositories for the schedules.
+//
+//go:generate mockgen -destination=../../mocks/mock_ScheduleService.go -package=mocks -mock_names=Service=MockScheduleService github.com/pavlegich/scripts-hub/internal/service/schedule Service
+type Service interface {
+	Create(ctx context.Context, schedule *entities.Schedule) (int, error)
+	List(ctx context.Context, count int) ([]*entities.Schedule, error)
+	Delete(ctx context.Context, name string) error
+	Due(ctx context.Context, now time.Time) ([]*entities.Schedule, error)
+	Claim(ctx context.Context, schedule *entities.Schedule, now time.Time) (bool, error)
+}
+
+// ScheduleService contains objects for schedule service.
+type ScheduleService struct {
+	repo repo.Repository
+}
+
+// NewScheduleService returns new schedule service.
+func NewScheduleService(ctx context.Context, repo repo.Repository) *ScheduleService {
+	return &ScheduleService{
+		repo: repo,
+	}
+}
+
+// Create validates the schedule, calculates its first run time
+// and requests repository to put it into the storage.
+func (s *ScheduleService) Create(ctx context.Context, sch *entities.Schedule) (int, error) {
+	if sch.Timezone == "" {
+		sch.Timezone = "UTC"
+	}
+	if sch.Overlap == "" {
+		sch.Overlap = entities.OverlapSkip
+	}
+
+	switch sch.Overlap {
+	case entities.OverlapSkip, entities.OverlapQueue, entities.OverlapCancel:
+	default:
+		return -1, fmt.Errorf("Create: unknown overlap policy %s %w", sch.Overlap, errs.ErrScheduleIncorrect)
+	}
+
+	cron, err := ParseCron(sch.Cron, sch.Timezone)
+	if err != nil {
+		return -1, fmt.Errorf("Create: parse cron failed %w", err)
+	}
+
+	sch.NextRunAt = cron.Next(time.Now())
+	if sch.NextRunAt.IsZero() {
+		return -1, fmt.Errorf("Create: schedule never fires %w", errs.ErrScheduleIncorrect)
+	}
+
+	created, err := s.repo.CreateSchedule(ctx, sch)
+	if err != nil {
+		return -1, fmt.Errorf("Create: create schedule failed %w", err)
+	}
+
+	return created.ID, nil
+}
+
+// List returns the stored schedules with the specified number of their next run times.
+func (s *ScheduleService) List(ctx context.Context, count int) ([]*entities.Schedule, error) {
+	schedules, err := s.repo.GetAllSchedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("List: get schedules list failed %w", err)
+	}
+
+	for _, sch := range schedules {
+		cron, err := ParseCron(sch.Cron, sch.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("List: parse cron of schedule %s failed %w", sch.Name, err)
+		}
+
+		sch.NextRuns = append([]time.Time{sch.NextRunAt.In(cron.loc)},
+			cron.NextN(sch.NextRunAt, count-1)...)
+	}
+
+	return schedules, nil
+}
+
+// Delete deletes the schedule of the command from the storage.
+func (s *ScheduleService) Delete(ctx context.Context, name string) error {
+	err := s.repo.DeleteScheduleByName(ctx, name)
+	if err != nil {
+		return fmt.Errorf("Delete: delete schedule failed %w", err)
+	}
+
+	return nil
+}
+
+// Due returns the schedules which next run time has come.
+func (s *ScheduleService) Due(ctx context.Context, now time.Time) ([]*entities.Schedule, error) {
+	schedules, err := s.repo.GetDueSchedules(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("Due: get due schedules failed %w", err)
+	}
+
+	return schedules, nil
+}
+
+// Claim moves the next run time of the due schedule after now. It returns false
+// if the schedule was already claimed by someone else, so the schedule
+// fires only once even if several servers share the storage.
+// The run times missed while the server was down are not run.
+func (s *ScheduleService) Claim(ctx context.Context, sch *entities.Schedule, now time.Time) (bool, error) {
+	cron, err := ParseCron(sch.Cron, sch.Timezone)
+	if err != nil {
+		return false, fmt.Errorf("Claim: parse cron failed %w", err)
+	}
+
+	ok, err := s.repo.MoveScheduleNextRun(ctx, sch, cron.Next(now))
+	if err != nil {
+		return false, fmt.Errorf("Claim: move schedule next run failed %w", err)
+	}
+
+	return ok, nil
+}
@@ -0,0 +1,81 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"go.uber.org/zap"
+)
+
+// Submitter describes method for running the saved command.
+type Submitter interface {
+	Submit(ctx context.Context, name string, opts entities.SubmitOptions) (*entities.Run, error)
+}
+
+// Scheduler contains objects for running the commands by their schedules.
+type Scheduler struct {
+	service   Service
+	submitter Submitter
+	interval  time.Duration
+}
+
+// NewScheduler returns new scheduler checking the due schedules every interval.
+func NewScheduler(ctx context.Context, service Service, submitter Submitter, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		service:   service,
+		submitter: submitter,
+		interval:  interval,
+	}
+}
+
+// Run checks the due schedules until the context is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.Fire(ctx, now)
+		}
+	}
+}
+
+// Fire submits the runs of the commands which schedules are due at now.
+func (s *Scheduler) Fire(ctx context.Context, now time.Time) {
+	schedules, err := s.service.Due(ctx, now)
+	if err != nil {
+		logger.Log.Error("Fire: get due schedules failed",
+			zap.Error(err))
+		return
+	}
+
+	for _, sch := range schedules {
+		ok, err := s.service.Claim(ctx, sch, now)
+		if err != nil {
+			logger.Log.With(zap.String("cmd_name", sch.Name)).Error("Fire: claim schedule failed",
+				zap.Error(err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		run, err := s.submitter.Submit(ctx, sch.Name, entities.SubmitOptions{
+			Trigger: entities.TriggerSchedule,
+			Overlap: sch.Overlap,
+		})
+		if err != nil {
+			logger.Log.With(zap.String("cmd_name", sch.Name)).Error("Fire: submit scheduled run failed",
+				zap.Error(err))
+			continue
+		}
+
+		logger.Log.With(zap.String("cmd_name", sch.Name)).Info("Fire: scheduled run submitted",
+			zap.Int("run_id", run.ID), zap.String("status", run.Status))
+	}
+}
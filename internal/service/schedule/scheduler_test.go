@@ -0,0 +1,81 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// submitterStub remembers the submitted commands.
+type submitterStub struct {
+	submitted []string
+	opts      []entities.SubmitOptions
+}
+
+func (s *submitterStub) Submit(ctx context.Context, name string, opts entities.SubmitOptions) (*entities.Run, error) {
+	s.submitted = append(s.submitted, name)
+	s.opts = append(s.opts, opts)
+	return &entities.Run{ID: len(s.submitted), Name: name, Status: entities.RunQueued}, nil
+}
+
+func TestScheduler_Fire(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, time.April, 15, 10, 0, 0, 0, time.UTC)
+
+	due := []*entities.Schedule{
+		{ID: 1, Name: "first", Cron: "* * * * *", Overlap: entities.OverlapSkip},
+		{ID: 2, Name: "second", Cron: "* * * * *", Overlap: entities.OverlapQueue},
+	}
+
+	tests := []struct {
+		name    string
+		dueErr  error
+		claimed []bool
+		want    []string
+	}{
+		{
+			name:    "all_claimed",
+			claimed: []bool{true, true},
+			want:    []string{"first", "second"},
+		},
+		{
+			name:    "claimed_by_other_server",
+			claimed: []bool{false, true},
+			want:    []string{"second"},
+		},
+		{
+			name:   "due_failed",
+			dueErr: errors.New("db is down"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockService := mocks.NewMockScheduleService(mockCtrl)
+
+			if tt.dueErr != nil {
+				mockService.EXPECT().Due(gomock.Any(), now).Return(nil, tt.dueErr).Times(1)
+			} else {
+				mockService.EXPECT().Due(gomock.Any(), now).Return(due, nil).Times(1)
+			}
+			for i, ok := range tt.claimed {
+				mockService.EXPECT().Claim(gomock.Any(), due[i], now).Return(ok, nil).Times(1)
+			}
+
+			sub := &submitterStub{}
+			NewScheduler(ctx, mockService, sub, time.Second).Fire(ctx, now)
+
+			require.Equal(t, tt.want, sub.submitted)
+			for i, opts := range sub.opts {
+				require.Equal(t, entities.TriggerSchedule, opts.Trigger)
+				require.NotEmpty(t, opts.Overlap, "submit %d", i)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+//go:build linux
+
+package trigger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// inotifyMask contains the events of the files written or moved into the directory.
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO
+
+// notifyDir sends the names of the files written or moved into the directory
+// until the context is done. The returned channel is closed when the watch stops.
+func notifyDir(ctx context.Context, dir string, events chan<- string) (<-chan struct{}, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("notifyDir: inotify init failed %w", err)
+	}
+
+	_, err = syscall.InotifyAddWatch(fd, dir, inotifyMask)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("notifyDir: add watch failed %w", err)
+	}
+
+	// The non-blocking descriptor is read through the runtime poller,
+	// so closing the file interrupts the blocked read.
+	f := os.NewFile(uintptr(fd), "inotify")
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+
+			for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+				event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+				nameStart := offset + syscall.SizeofInotifyEvent
+				offset = nameStart + int(event.Len)
+
+				if event.Mask&syscall.IN_IGNORED != 0 {
+					return
+				}
+				if event.Mask&syscall.IN_ISDIR != 0 || event.Len == 0 {
+					continue
+				}
+
+				name := string(bytes.TrimRight(buf[nameStart:offset], "\x00"))
+				select {
+				case events <- name:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return stopped, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package trigger
+
+import (
+	"context"
+	"errors"
+)
+
+// notifyDir is not supported without inotify, the directories are polled instead.
+func notifyDir(ctx context.Context, dir string, events chan<- string) (<-chan struct{}, error) {
+	return nil, errors.New("notifyDir: inotify is supported only on linux")
+}
@@ -0,0 +1,161 @@
+package trigger
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"go.uber.org/zap"
+)
+
+// Listener contains objects for running the commands of the notify triggers
+// on the notifications of their database channels.
+type Listener struct {
+	service  Service
+	runner   Runner
+	interval time.Duration
+	triggers []*entities.Trigger
+}
+
+// NewListener returns new listener reloading the notify triggers every interval.
+func NewListener(ctx context.Context, service Service, runner Runner, interval time.Duration) *Listener {
+	return &Listener{
+		service:  service,
+		runner:   runner,
+		interval: interval,
+	}
+}
+
+// Run listens the channels of the notify triggers until the context is done.
+// The channels are listened again when the set of the channels changes,
+// the listening fails or another server stops holding the listener lock.
+func (l *Listener) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		channels := l.reload(ctx)
+		if len(channels) > 0 {
+			l.listen(ctx, channels, ticker)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// listen receives the notifications of the channels until the context is done,
+// the listening stops or the set of the channels of the reloaded triggers changes.
+func (l *Listener) listen(ctx context.Context, channels []string, ticker *time.Ticker) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	notifications := make(chan *entities.Notification)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ok, err := l.service.Listen(ctx, channels, notifications)
+		if err != nil {
+			logger.Log.Error("listen: listen channels failed",
+				zap.Error(err), zap.Strings("channels", channels))
+			return
+		}
+		if !ok {
+			logger.Log.Debug("listen: channels are listened by another server",
+				zap.Strings("channels", channels))
+		}
+	}()
+
+	for {
+		select {
+		case <-stopped:
+			return
+		case n := <-notifications:
+			l.Fire(ctx, n)
+		case <-ticker.C:
+			if !equalChannels(channels, l.reload(ctx)) {
+				cancel()
+				<-stopped
+				return
+			}
+		}
+	}
+}
+
+// reload gets the notify triggers and returns the sorted unique names of their channels.
+// The previous triggers are kept if they cannot be loaded.
+func (l *Listener) reload(ctx context.Context) []string {
+	triggers, err := l.service.Notifies(ctx)
+	if err != nil {
+		logger.Log.Error("reload: get notify triggers failed",
+			zap.Error(err))
+	} else {
+		l.triggers = triggers
+	}
+
+	seen := make(map[string]struct{}, len(l.triggers))
+	channels := make([]string, 0, len(l.triggers))
+	for _, t := range l.triggers {
+		if _, ok := seen[t.Channel]; ok {
+			continue
+		}
+		seen[t.Channel] = struct{}{}
+		channels = append(channels, t.Channel)
+	}
+	sort.Strings(channels)
+
+	return channels
+}
+
+// Fire submits the runs of the commands which triggers listen the channel of the notification.
+func (l *Listener) Fire(ctx context.Context, n *entities.Notification) {
+	for _, t := range l.triggers {
+		if t.Channel != n.Channel {
+			continue
+		}
+
+		opts := entities.SubmitOptions{
+			Trigger:   entities.TriggerNotify,
+			TriggerID: &t.ID,
+			Overlap:   entities.OverlapQueue,
+			Env:       []string{entities.NotifyChannelEnv + "=" + n.Channel},
+		}
+		switch t.Pass {
+		case entities.PassArg:
+			opts.Args = []string{n.Payload}
+		case entities.PassStdin:
+			opts.Input = []byte(n.Payload)
+		default:
+			opts.Env = append(opts.Env, entities.NotifyPayloadEnv+"="+n.Payload)
+		}
+
+		run, err := l.runner.Submit(ctx, t.Name, opts)
+		if err != nil {
+			logger.Log.With(zap.String("cmd_name", t.Name)).Error("Fire: submit notify run failed",
+				zap.Error(err), zap.String("channel", n.Channel), zap.Int("trigger_id", t.ID))
+			continue
+		}
+
+		logger.Log.With(zap.String("cmd_name", t.Name)).Info("Fire: notify run submitted",
+			zap.Int("run_id", run.ID), zap.String("channel", n.Channel), zap.Int("trigger_id", t.ID))
+	}
+}
+
+// equalChannels reports whether the sorted channel lists are equal.
+func equalChannels(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
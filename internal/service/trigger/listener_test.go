@@ -0,0 +1,90 @@
+package trigger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListener_Fire(t *testing.T) {
+	ctx := context.Background()
+
+	l := NewListener(ctx, nil, nil, time.Second)
+	l.triggers = []*entities.Trigger{
+		{ID: 1, Name: "vacuum", Type: entities.TriggerTypeNotify, Channel: "maintenance", Pass: entities.PassEnv},
+		{ID: 2, Name: "reindex", Type: entities.TriggerTypeNotify, Channel: "maintenance", Pass: entities.PassStdin},
+		{ID: 3, Name: "archive", Type: entities.TriggerTypeNotify, Channel: "maintenance", Pass: entities.PassArg},
+		{ID: 4, Name: "report", Type: entities.TriggerTypeNotify, Channel: "reports", Pass: entities.PassEnv},
+	}
+	runner := &runnerStub{fired: make(chan struct{}, 10)}
+	l.runner = runner
+
+	l.Fire(ctx, &entities.Notification{Channel: "maintenance", Payload: `{"table":"runs"}`})
+
+	want := []entities.SubmitOptions{
+		{
+			Trigger: entities.TriggerNotify,
+			Overlap: entities.OverlapQueue,
+			Env:     []string{"SCRIPTS_HUB_CHANNEL=maintenance", `SCRIPTS_HUB_PAYLOAD={"table":"runs"}`},
+		},
+		{
+			Trigger: entities.TriggerNotify,
+			Overlap: entities.OverlapQueue,
+			Env:     []string{"SCRIPTS_HUB_CHANNEL=maintenance"},
+			Input:   []byte(`{"table":"runs"}`),
+		},
+		{
+			Trigger: entities.TriggerNotify,
+			Overlap: entities.OverlapQueue,
+			Env:     []string{"SCRIPTS_HUB_CHANNEL=maintenance"},
+			Args:    []string{`{"table":"runs"}`},
+		},
+	}
+	require.Len(t, runner.submitted, len(want))
+	for i, opts := range runner.submitted {
+		require.Equal(t, i+1, *opts.TriggerID)
+		opts.TriggerID = nil
+		require.Equal(t, want[i], opts)
+	}
+}
+
+func TestListener_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockCtrl := gomock.NewController(t)
+	mockService := mocks.NewMockTriggerService(mockCtrl)
+
+	triggers := []*entities.Trigger{
+		{ID: 1, Name: "vacuum", Type: entities.TriggerTypeNotify, Channel: "maintenance", Pass: entities.PassEnv},
+		{ID: 2, Name: "report", Type: entities.TriggerTypeNotify, Channel: "reports", Pass: entities.PassEnv},
+		{ID: 3, Name: "cleanup", Type: entities.TriggerTypeNotify, Channel: "maintenance", Pass: entities.PassEnv},
+	}
+	mockService.EXPECT().Notifies(gomock.Any()).Return(triggers, nil).AnyTimes()
+	mockService.EXPECT().Listen(gomock.Any(), []string{"maintenance", "reports"}, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, channels []string, notifications chan<- *entities.Notification) (bool, error) {
+			notifications <- &entities.Notification{Channel: "reports", Payload: "daily"}
+			<-ctx.Done()
+			return true, nil
+		}).MinTimes(1)
+
+	runner := &runnerStub{fired: make(chan struct{}, 10)}
+	go NewListener(ctx, mockService, runner, time.Hour).Run(ctx)
+
+	select {
+	case <-runner.fired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notify trigger is not fired")
+	}
+
+	runner.mu.Lock()
+	defer runner.mu.Unlock()
+	require.Len(t, runner.submitted, 1)
+	require.Equal(t, 2, *runner.submitted[0].TriggerID)
+	require.Contains(t, runner.submitted[0].Env, "SCRIPTS_HUB_PAYLOAD=daily")
+}
@@ -0,0 +1,299 @@
+// Package trigger contains trigger service object and methods for interaction
+// between handlers and repositories, generating and checking the secret
+// tokens of the trigger URLs, the watcher running the commands of the watch
+// triggers on the file changes and the listener running the commands of the notify
+// triggers on the database notifications.
+package trigger
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	repo "github.com/pavlegich/scripts-hub/internal/repository"
+)
+
+// tokenSize is the number of the random bytes of the trigger token.
+const tokenSize = 32
+
+// channelPattern matches the names of the database channels of the notify triggers.
+var channelPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// Watch trigger limits and defaults.
+const (
+	DefaultGlob     = "*"
+	DefaultDebounce = time.Second
+	MaxConcurrency  = 100
+)
+
+// Service describes methods for communication between
+// handlers and repositories for the triggers.
+//
+//go:generate mockgen -destination=../../mocks/mock_TriggerService.go -package=mocks -mock_names=Service=MockTriggerService github.com/pavlegich/scripts-hub/internal/service/trigger Service
+type Service interface {
+	Create(ctx context.Context, trigger *entities.Trigger) (*entities.Trigger, error)
+	List(ctx context.Context, name string) ([]*entities.Trigger, error)
+	Rotate(ctx context.Context, id int) (*entities.Trigger, error)
+	Revoke(ctx context.Context, id int) error
+	Authorize(ctx context.Context, id int, token string) (*entities.Trigger, error)
+	Watches(ctx context.Context) ([]*entities.Trigger, error)
+	Notifies(ctx context.Context) ([]*entities.Trigger, error)
+	Listen(ctx context.Context, channels []string, notifications chan<- *entities.Notification) (bool, error)
+}
+
+// TriggerService contains objects for trigger service.
+type TriggerService struct {
+	repo repo.Repository
+}
+
+// NewTriggerService returns new trigger service.
+func NewTriggerService(ctx context.Context, repo repo.Repository) *TriggerService {
+	return &TriggerService{
+		repo: repo,
+	}
+}
+
+// Create validates the trigger, generates the token of the URL trigger and requests
+// repository to put the trigger with the token hash into the storage.
+func (s *TriggerService) Create(ctx context.Context, t *entities.Trigger) (*entities.Trigger, error) {
+	if t.Type == "" {
+		t.Type = entities.TriggerTypeURL
+	}
+
+	switch t.Type {
+	case entities.TriggerTypeURL:
+		if t.Input == "" {
+			t.Input = entities.InputNone
+		}
+
+		switch t.Input {
+		case entities.InputNone, entities.InputStdin, entities.InputParams:
+		default:
+			return nil, fmt.Errorf("Create: unknown input mode %s %w", t.Input, errs.ErrTriggerIncorrect)
+		}
+
+		token, err := newToken()
+		if err != nil {
+			return nil, fmt.Errorf("Create: %w", err)
+		}
+		t.Token = token
+		t.TokenHash = hashToken(token)
+	case entities.TriggerTypeWatch:
+		err := ValidateWatch(t)
+		if err != nil {
+			return nil, fmt.Errorf("Create: %w", err)
+		}
+	case entities.TriggerTypeNotify:
+		err := ValidateNotify(t)
+		if err != nil {
+			return nil, fmt.Errorf("Create: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("Create: unknown trigger type %s %w", t.Type, errs.ErrTriggerIncorrect)
+	}
+
+	created, err := s.repo.CreateTrigger(ctx, t)
+	if err != nil {
+		return nil, fmt.Errorf("Create: create trigger failed %w", err)
+	}
+
+	return created, nil
+}
+
+// List returns the triggers of the command.
+func (s *TriggerService) List(ctx context.Context, name string) ([]*entities.Trigger, error) {
+	triggers, err := s.repo.GetTriggersByCommandName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("List: get triggers failed %w", err)
+	}
+
+	return triggers, nil
+}
+
+// Rotate replaces the token of the trigger with the new one,
+// the previous token stops working immediately.
+func (s *TriggerService) Rotate(ctx context.Context, id int) (*entities.Trigger, error) {
+	t, err := s.repo.GetTriggerByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("Rotate: get trigger failed %w", err)
+	}
+	if t.RevokedAt != nil {
+		return nil, fmt.Errorf("Rotate: trigger is revoked %w", errs.ErrTriggerNotFound)
+	}
+	if t.Type != entities.TriggerTypeURL {
+		return nil, fmt.Errorf("Rotate: %s trigger has no token %w", t.Type, errs.ErrTriggerIncorrect)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, fmt.Errorf("Rotate: %w", err)
+	}
+	now := time.Now()
+	t.Token = token
+	t.TokenHash = hashToken(token)
+	t.RotatedAt = &now
+
+	err = s.repo.RotateTriggerToken(ctx, t)
+	if err != nil {
+		return nil, fmt.Errorf("Rotate: rotate token failed %w", err)
+	}
+
+	return t, nil
+}
+
+// Revoke disables the trigger, its runs stay attributed to it.
+func (s *TriggerService) Revoke(ctx context.Context, id int) error {
+	err := s.repo.RevokeTrigger(ctx, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("Revoke: revoke trigger failed %w", err)
+	}
+
+	return nil
+}
+
+// Authorize returns the trigger if it is not revoked and the token matches its hash.
+func (s *TriggerService) Authorize(ctx context.Context, id int, token string) (*entities.Trigger, error) {
+	t, err := s.repo.GetTriggerByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("Authorize: get trigger failed %w", err)
+	}
+	if t.RevokedAt != nil {
+		return nil, fmt.Errorf("Authorize: trigger is revoked %w", errs.ErrTriggerNotFound)
+	}
+	if t.Type != entities.TriggerTypeURL {
+		return nil, fmt.Errorf("Authorize: %s trigger has no URL %w", t.Type, errs.ErrTriggerNotFound)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(t.TokenHash)) != 1 {
+		return nil, fmt.Errorf("Authorize: %w", errs.ErrTriggerUnauthorized)
+	}
+
+	return t, nil
+}
+
+// Watches returns the watch triggers which are not revoked.
+func (s *TriggerService) Watches(ctx context.Context) ([]*entities.Trigger, error) {
+	triggers, err := s.repo.GetActiveTriggers(ctx, entities.TriggerTypeWatch)
+	if err != nil {
+		return nil, fmt.Errorf("Watches: get watch triggers failed %w", err)
+	}
+
+	return triggers, nil
+}
+
+// Notifies returns the notify triggers which are not revoked.
+func (s *TriggerService) Notifies(ctx context.Context) ([]*entities.Trigger, error) {
+	triggers, err := s.repo.GetActiveTriggers(ctx, entities.TriggerTypeNotify)
+	if err != nil {
+		return nil, fmt.Errorf("Notifies: get notify triggers failed %w", err)
+	}
+
+	return triggers, nil
+}
+
+// Listen requests repository to listen the database channels and send their
+// notifications until the context is done. It returns false if the channels
+// are listened by another server.
+func (s *TriggerService) Listen(ctx context.Context, channels []string, notifications chan<- *entities.Notification) (bool, error) {
+	ok, err := s.repo.Listen(ctx, channels, notifications)
+	if err != nil {
+		return ok, fmt.Errorf("Listen: %w", err)
+	}
+
+	return ok, nil
+}
+
+// ValidateWatch checks the settings of the watch trigger and fills
+// the empty ones with the default values.
+func ValidateWatch(t *entities.Trigger) error {
+	if !filepath.IsAbs(t.Path) {
+		return fmt.Errorf("ValidateWatch: path %q is not absolute %w", t.Path, errs.ErrTriggerIncorrect)
+	}
+	info, err := os.Stat(t.Path)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("ValidateWatch: path %q is not directory %w", t.Path, errs.ErrTriggerIncorrect)
+	}
+
+	if t.Glob == "" {
+		t.Glob = DefaultGlob
+	}
+	_, err = filepath.Match(t.Glob, "")
+	if err != nil || strings.ContainsRune(t.Glob, filepath.Separator) {
+		return fmt.Errorf("ValidateWatch: incorrect glob %q %w", t.Glob, errs.ErrTriggerIncorrect)
+	}
+
+	if t.Debounce == "" {
+		t.Debounce = DefaultDebounce.String()
+	}
+	debounce, err := time.ParseDuration(t.Debounce)
+	if err != nil || debounce < 0 {
+		return fmt.Errorf("ValidateWatch: incorrect debounce %q %w", t.Debounce, errs.ErrTriggerIncorrect)
+	}
+
+	if t.Concurrency == 0 {
+		t.Concurrency = 1
+	}
+	if t.Concurrency < 1 || t.Concurrency > MaxConcurrency {
+		return fmt.Errorf("ValidateWatch: concurrency must be from 1 to %d %w", MaxConcurrency, errs.ErrTriggerIncorrect)
+	}
+
+	if t.Pass == "" {
+		t.Pass = entities.PassEnv
+	}
+	switch t.Pass {
+	case entities.PassEnv, entities.PassArg:
+	default:
+		return fmt.Errorf("ValidateWatch: unknown path passing %s %w", t.Pass, errs.ErrTriggerIncorrect)
+	}
+
+	t.Input = ""
+
+	return nil
+}
+
+// ValidateNotify checks the settings of the notify trigger and fills
+// the empty ones with the default values.
+func ValidateNotify(t *entities.Trigger) error {
+	if !channelPattern.MatchString(t.Channel) {
+		return fmt.Errorf("ValidateNotify: incorrect channel %q %w", t.Channel, errs.ErrTriggerIncorrect)
+	}
+
+	if t.Pass == "" {
+		t.Pass = entities.PassEnv
+	}
+	switch t.Pass {
+	case entities.PassEnv, entities.PassArg, entities.PassStdin:
+	default:
+		return fmt.Errorf("ValidateNotify: unknown payload passing %s %w", t.Pass, errs.ErrTriggerIncorrect)
+	}
+
+	t.Input = ""
+
+	return nil
+}
+
+// newToken returns new random trigger token.
+func newToken() (string, error) {
+	b := make([]byte, tokenSize)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("newToken: read random bytes failed %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken returns the hex encoded SHA-256 hash of the token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
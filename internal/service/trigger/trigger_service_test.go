@@ -0,0 +1,215 @@
+package trigger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerService_Rotate(t *testing.T) {
+	ctx := context.Background()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	s := NewTriggerService(ctx, mockRepo)
+
+	oldToken := "old-token"
+	revokedAt := time.Date(2024, time.April, 28, 10, 0, 0, 0, time.UTC)
+
+	mockRepo.EXPECT().GetTriggerByID(gomock.Any(), 7).
+		Return(&entities.Trigger{ID: 7, Type: entities.TriggerTypeURL, Name: "deploy", TokenHash: hashToken(oldToken)}, nil).Times(1)
+	mockRepo.EXPECT().RotateTriggerToken(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, tr *entities.Trigger) error {
+			require.Equal(t, hashToken(tr.Token), tr.TokenHash)
+			return nil
+		}).Times(1)
+
+	rotated, err := s.Rotate(ctx, 7)
+	require.NoError(t, err)
+	require.NotEqual(t, oldToken, rotated.Token)
+	require.NotEqual(t, hashToken(oldToken), rotated.TokenHash)
+	require.NotNil(t, rotated.RotatedAt)
+
+	mockRepo.EXPECT().GetTriggerByID(gomock.Any(), 8).
+		Return(&entities.Trigger{ID: 8, Type: entities.TriggerTypeURL, Name: "deploy", RevokedAt: &revokedAt}, nil).Times(1)
+
+	_, err = s.Rotate(ctx, 8)
+	require.ErrorIs(t, err, errs.ErrTriggerNotFound)
+}
+
+func TestTriggerService_Authorize(t *testing.T) {
+	ctx := context.Background()
+
+	token := "secret"
+	revokedAt := time.Date(2024, time.April, 28, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		trigger *entities.Trigger
+		token   string
+		wantErr error
+	}{
+		{
+			name:    "authorized",
+			trigger: &entities.Trigger{ID: 1, Type: entities.TriggerTypeURL, TokenHash: hashToken(token)},
+			token:   token,
+		},
+		{
+			name:    "wrong_token",
+			trigger: &entities.Trigger{ID: 1, Type: entities.TriggerTypeURL, TokenHash: hashToken(token)},
+			token:   "guess",
+			wantErr: errs.ErrTriggerUnauthorized,
+		},
+		{
+			name:    "empty_token",
+			trigger: &entities.Trigger{ID: 1, Type: entities.TriggerTypeURL, TokenHash: hashToken(token)},
+			wantErr: errs.ErrTriggerUnauthorized,
+		},
+		{
+			name:    "revoked",
+			trigger: &entities.Trigger{ID: 1, Type: entities.TriggerTypeURL, TokenHash: hashToken(token), RevokedAt: &revokedAt},
+			token:   token,
+			wantErr: errs.ErrTriggerNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockRepo := mocks.NewMockRepository(mockCtrl)
+			mockRepo.EXPECT().GetTriggerByID(gomock.Any(), 1).Return(tt.trigger, nil).Times(1)
+
+			got, err := NewTriggerService(ctx, mockRepo).Authorize(ctx, 1, tt.token)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.trigger, got)
+		})
+	}
+}
+
+func TestValidateWatch(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		trigger *entities.Trigger
+		want    *entities.Trigger
+		wantErr bool
+	}{
+		{
+			name:    "defaults",
+			trigger: &entities.Trigger{Path: dir},
+			want: &entities.Trigger{Path: dir, Glob: "*", Debounce: "1s",
+				Concurrency: 1, Pass: entities.PassEnv},
+		},
+		{
+			name: "custom",
+			trigger: &entities.Trigger{Path: dir, Glob: "*.csv", Debounce: "500ms",
+				Concurrency: 3, Pass: entities.PassArg},
+			want: &entities.Trigger{Path: dir, Glob: "*.csv", Debounce: "500ms",
+				Concurrency: 3, Pass: entities.PassArg},
+		},
+		{
+			name:    "relative_path",
+			trigger: &entities.Trigger{Path: "incoming"},
+			wantErr: true,
+		},
+		{
+			name:    "not_directory",
+			trigger: &entities.Trigger{Path: dir + "/missing"},
+			wantErr: true,
+		},
+		{
+			name:    "incorrect_glob",
+			trigger: &entities.Trigger{Path: dir, Glob: "[a-"},
+			wantErr: true,
+		},
+		{
+			name:    "glob_with_directory",
+			trigger: &entities.Trigger{Path: dir, Glob: "sub/*.csv"},
+			wantErr: true,
+		},
+		{
+			name:    "incorrect_debounce",
+			trigger: &entities.Trigger{Path: dir, Debounce: "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "too_many_runs",
+			trigger: &entities.Trigger{Path: dir, Concurrency: 101},
+			wantErr: true,
+		},
+		{
+			name:    "unknown_pass",
+			trigger: &entities.Trigger{Path: dir, Pass: "stdin"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWatch(tt.trigger)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrTriggerIncorrect)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, tt.trigger)
+		})
+	}
+}
+
+func TestValidateNotify(t *testing.T) {
+	tests := []struct {
+		name     string
+		trigger  *entities.Trigger
+		wantPass string
+		wantErr  bool
+	}{
+		{
+			name:     "defaults",
+			trigger:  &entities.Trigger{Channel: "maintenance"},
+			wantPass: entities.PassEnv,
+		},
+		{
+			name:     "payload_in_stdin",
+			trigger:  &entities.Trigger{Channel: "orders_created", Pass: entities.PassStdin},
+			wantPass: entities.PassStdin,
+		},
+		{
+			name:    "empty_channel",
+			trigger: &entities.Trigger{},
+			wantErr: true,
+		},
+		{
+			name:    "incorrect_channel",
+			trigger: &entities.Trigger{Channel: "jobs; DROP TABLE runs"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown_pass",
+			trigger: &entities.Trigger{Channel: "maintenance", Pass: "file"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNotify(tt.trigger)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrTriggerIncorrect)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantPass, tt.trigger.Pass)
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package trigger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"go.uber.org/zap"
+)
+
+// Runner describes methods for running the saved command
+// and waiting for the completion of its run.
+type Runner interface {
+	Submit(ctx context.Context, name string, opts entities.SubmitOptions) (*entities.Run, error)
+	Wait(ctx context.Context, id int) (*entities.Run, error)
+}
+
+// Watcher contains objects for running the commands of the watch triggers
+// when the files matching their globs appear or change.
+type Watcher struct {
+	service  Service
+	runner   Runner
+	interval time.Duration
+	watches  map[int]*watch
+}
+
+// NewWatcher returns new watcher reloading the watch triggers every interval.
+// The interval is also used for polling the directories which cannot be
+// watched with inotify.
+func NewWatcher(ctx context.Context, service Service, runner Runner, interval time.Duration) *Watcher {
+	return &Watcher{
+		service:  service,
+		runner:   runner,
+		interval: interval,
+		watches:  make(map[int]*watch),
+	}
+}
+
+// Run watches the directories of the watch triggers until the context is done.
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.Reload(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Reload(ctx)
+		}
+	}
+}
+
+// Reload starts watching the directories of the new watch triggers,
+// restarts the stopped watches and stops the watches of the revoked triggers.
+func (w *Watcher) Reload(ctx context.Context) {
+	triggers, err := w.service.Watches(ctx)
+	if err != nil {
+		logger.Log.Error("Reload: get watch triggers failed",
+			zap.Error(err))
+		return
+	}
+
+	active := make(map[int]struct{}, len(triggers))
+	for _, t := range triggers {
+		active[t.ID] = struct{}{}
+
+		if wt, ok := w.watches[t.ID]; ok && !wt.stopped() {
+			continue
+		}
+
+		wt, err := newWatch(ctx, t, w.runner)
+		if err != nil {
+			logger.Log.With(zap.String("cmd_name", t.Name)).Error("Reload: incorrect watch trigger",
+				zap.Error(err), zap.Int("trigger_id", t.ID))
+			continue
+		}
+		w.watches[t.ID] = wt
+		go wt.run(w.interval)
+	}
+
+	for id, wt := range w.watches {
+		if _, ok := active[id]; !ok {
+			wt.cancel()
+			delete(w.watches, id)
+		}
+	}
+}
+
+// watch contains the state of the single watch trigger.
+type watch struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	trigger  *entities.Trigger
+	runner   Runner
+	debounce time.Duration
+	slots    chan struct{}
+
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+// newWatch returns new watch of the trigger.
+func newWatch(ctx context.Context, t *entities.Trigger, runner Runner) (*watch, error) {
+	debounce, err := time.ParseDuration(t.Debounce)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := t.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &watch{
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		trigger:  t,
+		runner:   runner,
+		debounce: debounce,
+		slots:    make(chan struct{}, concurrency),
+		timers:   make(map[string]*time.Timer),
+	}, nil
+}
+
+// stopped reports whether the watch is finished.
+func (wt *watch) stopped() bool {
+	select {
+	case <-wt.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// run receives the names of the changed files of the directory until
+// the context of the watch is done. The directory is watched with inotify
+// and polled every interval if inotify is not available.
+func (wt *watch) run(interval time.Duration) {
+	defer close(wt.done)
+	defer wt.stopTimers()
+	defer wt.cancel()
+
+	log := logger.Log.With(zap.String("cmd_name", wt.trigger.Name))
+
+	events := make(chan string)
+	stopped, err := notifyDir(wt.ctx, wt.trigger.Path, events)
+	if err != nil {
+		log.Warn("run: inotify unavailable, polling directory",
+			zap.Error(err), zap.String("path", wt.trigger.Path))
+
+		polling := make(chan struct{})
+		go func() {
+			defer close(polling)
+			pollDir(wt.ctx, wt.trigger.Path, interval, events)
+		}()
+		stopped = polling
+	}
+
+	for {
+		select {
+		case <-stopped:
+			if wt.ctx.Err() == nil {
+				log.Warn("run: directory watch stopped",
+					zap.String("path", wt.trigger.Path), zap.Int("trigger_id", wt.trigger.ID))
+			}
+			return
+		case name := <-events:
+			ok, _ := filepath.Match(wt.trigger.Glob, name)
+			if ok {
+				wt.schedule(filepath.Join(wt.trigger.Path, name))
+			}
+		}
+	}
+}
+
+// schedule fires the trigger for the path after the debounce period
+// passes without the new changes of the path.
+func (wt *watch) schedule(path string) {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+
+	if timer, ok := wt.timers[path]; ok {
+		timer.Reset(wt.debounce)
+		return
+	}
+
+	wt.timers[path] = time.AfterFunc(wt.debounce, func() {
+		wt.mu.Lock()
+		delete(wt.timers, path)
+		wt.mu.Unlock()
+
+		wt.fire(path)
+	})
+}
+
+// stopTimers cancels the debounced fires of the trigger.
+func (wt *watch) stopTimers() {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+
+	for path, timer := range wt.timers {
+		timer.Stop()
+		delete(wt.timers, path)
+	}
+}
+
+// fire runs the command of the trigger for the changed path
+// when the number of its active runs is below the concurrency limit.
+func (wt *watch) fire(path string) {
+	log := logger.Log.With(zap.String("cmd_name", wt.trigger.Name))
+
+	select {
+	case wt.slots <- struct{}{}:
+	case <-wt.ctx.Done():
+		return
+	}
+	defer func() { <-wt.slots }()
+
+	opts := entities.SubmitOptions{
+		Trigger:   entities.TriggerWatch,
+		TriggerID: &wt.trigger.ID,
+		Overlap:   entities.OverlapParallel,
+	}
+	switch wt.trigger.Pass {
+	case entities.PassArg:
+		opts.Args = []string{path}
+	default:
+		opts.Env = []string{entities.WatchPathEnv + "=" + path}
+	}
+
+	run, err := wt.runner.Submit(wt.ctx, wt.trigger.Name, opts)
+	if err != nil {
+		log.Error("fire: submit watch run failed",
+			zap.Error(err), zap.String("path", path), zap.Int("trigger_id", wt.trigger.ID))
+		return
+	}
+
+	log.Info("fire: watch run submitted",
+		zap.Int("run_id", run.ID), zap.String("path", path), zap.Int("trigger_id", wt.trigger.ID))
+
+	_, err = wt.runner.Wait(wt.ctx, run.ID)
+	if err != nil {
+		log.Error("fire: wait watch run failed",
+			zap.Error(err), zap.Int("run_id", run.ID))
+	}
+}
+
+// fileState contains the state of the polled file.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// pollDir sends the names of the files which appear or change in the directory
+// every interval until the context is done. The files existing when the polling
+// starts are not sent.
+func pollDir(ctx context.Context, dir string, interval time.Duration, events chan<- string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	states := scanDir(dir)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := scanDir(dir)
+		for name, st := range current {
+			if prev, ok := states[name]; ok && prev == st {
+				continue
+			}
+			select {
+			case events <- name:
+			case <-ctx.Done():
+				return
+			}
+		}
+		states = current
+	}
+}
+
+// scanDir returns the states of the regular files of the directory.
+func scanDir(dir string) map[string]fileState {
+	states := make(map[string]fileState)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return states
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		states[e.Name()] = fileState{size: info.Size(), modTime: info.ModTime()}
+	}
+
+	return states
+}
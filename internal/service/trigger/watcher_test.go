@@ -0,0 +1,230 @@
+package trigger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// runnerStub remembers the submitted runs.
+type runnerStub struct {
+	mu        sync.Mutex
+	submitted []entities.SubmitOptions
+	fired     chan struct{}
+}
+
+func (r *runnerStub) Submit(ctx context.Context, name string, opts entities.SubmitOptions) (*entities.Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.submitted = append(r.submitted, opts)
+	r.fired <- struct{}{}
+	return &entities.Run{ID: len(r.submitted), Name: name, Status: entities.RunQueued}, nil
+}
+
+func (r *runnerStub) Wait(ctx context.Context, id int) (*entities.Run, error) {
+	return &entities.Run{ID: id, Status: entities.RunSucceeded}, nil
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		pass     string
+		wantEnv  []string
+		wantArgs []string
+	}{
+		{
+			name:    "path_in_env",
+			pass:    entities.PassEnv,
+			wantEnv: []string{entities.WatchPathEnv + "=" + filepath.Join(dir, "report.csv")},
+		},
+		{
+			name:     "path_in_arg",
+			pass:     entities.PassArg,
+			wantArgs: []string{filepath.Join(dir, "report.csv")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockService := mocks.NewMockTriggerService(mockCtrl)
+
+			trigger := &entities.Trigger{ID: 3, Name: "import", Type: entities.TriggerTypeWatch,
+				Path: dir, Glob: "*.csv", Debounce: "50ms", Concurrency: 1, Pass: tt.pass}
+			gomock.InOrder(
+				mockService.EXPECT().Watches(gomock.Any()).Return([]*entities.Trigger{trigger}, nil).Times(1),
+				mockService.EXPECT().Watches(gomock.Any()).Return([]*entities.Trigger{}, nil).Times(1),
+			)
+
+			runner := &runnerStub{fired: make(chan struct{}, 10)}
+			w := NewWatcher(ctx, mockService, runner, 20*time.Millisecond)
+			w.Reload(ctx)
+
+			// Give the watch time to start before changing the files.
+			time.Sleep(100 * time.Millisecond)
+
+			path := filepath.Join(dir, "report.csv")
+			for i := 0; i < 3; i++ {
+				require.NoError(t, os.WriteFile(path, []byte("a,b\n"), 0o644))
+			}
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("skip"), 0o644))
+
+			select {
+			case <-runner.fired:
+			case <-time.After(5 * time.Second):
+				t.Fatal("trigger is not fired")
+			}
+
+			// The writes within the debounce period fire the trigger once.
+			time.Sleep(200 * time.Millisecond)
+
+			runner.mu.Lock()
+			require.Len(t, runner.submitted, 1)
+			opts := runner.submitted[0]
+			runner.mu.Unlock()
+
+			require.Equal(t, entities.TriggerWatch, opts.Trigger)
+			require.Equal(t, 3, *opts.TriggerID)
+			require.Equal(t, tt.wantEnv, opts.Env)
+			require.Equal(t, tt.wantArgs, opts.Args)
+
+			// The revoked trigger stops watching.
+			wt := w.watches[trigger.ID]
+			w.Reload(ctx)
+			require.Empty(t, w.watches)
+			select {
+			case <-wt.done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("watch is not stopped")
+			}
+
+			require.NoError(t, os.Remove(path))
+			require.NoError(t, os.Remove(filepath.Join(dir, "notes.txt")))
+		})
+	}
+}
+
+// blockingRunner holds the submitted runs active until they are released.
+type blockingRunner struct {
+	mu        sync.Mutex
+	submitted []entities.SubmitOptions
+	active    int
+	maxActive int
+	release   chan struct{}
+}
+
+func (r *blockingRunner) Submit(ctx context.Context, name string, opts entities.SubmitOptions) (*entities.Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.submitted = append(r.submitted, opts)
+	r.active++
+	if r.active > r.maxActive {
+		r.maxActive = r.active
+	}
+	return &entities.Run{ID: len(r.submitted), Name: name, Status: entities.RunQueued}, nil
+}
+
+func (r *blockingRunner) Wait(ctx context.Context, id int) (*entities.Run, error) {
+	select {
+	case <-r.release:
+	case <-ctx.Done():
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.active--
+	return &entities.Run{ID: id, Status: entities.RunSucceeded}, nil
+}
+
+func TestWatcher_Concurrency(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+
+	mockCtrl := gomock.NewController(t)
+	mockService := mocks.NewMockTriggerService(mockCtrl)
+
+	trigger := &entities.Trigger{ID: 4, Name: "import", Type: entities.TriggerTypeWatch,
+		Path: dir, Glob: "*.csv", Debounce: "20ms", Concurrency: 2, Pass: entities.PassArg}
+	mockService.EXPECT().Watches(gomock.Any()).Return([]*entities.Trigger{trigger}, nil).Times(1)
+
+	runner := &blockingRunner{release: make(chan struct{})}
+	defer close(runner.release)
+
+	w := NewWatcher(ctx, mockService, runner, 20*time.Millisecond)
+	w.Reload(ctx)
+
+	// Give the watch time to start before changing the files.
+	time.Sleep(100 * time.Millisecond)
+
+	for _, name := range []string{"a.csv", "b.csv", "c.csv"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("a,b\n"), 0o644))
+	}
+
+	// Two runs are active at once, the third one waits for the free slot.
+	require.Eventually(t, func() bool {
+		runner.mu.Lock()
+		defer runner.mu.Unlock()
+		return runner.active == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(200 * time.Millisecond)
+
+	runner.mu.Lock()
+	require.Len(t, runner.submitted, 2)
+	require.Equal(t, 2, runner.maxActive)
+	for _, opts := range runner.submitted {
+		require.Equal(t, entities.OverlapParallel, opts.Overlap)
+	}
+	runner.mu.Unlock()
+
+	runner.release <- struct{}{}
+	require.Eventually(t, func() bool {
+		runner.mu.Lock()
+		defer runner.mu.Unlock()
+		return len(runner.submitted) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPollDir(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("old"), 0o644))
+
+	events := make(chan string)
+	go pollDir(ctx, dir, 10*time.Millisecond, events)
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0o644))
+	select {
+	case name := <-events:
+		require.Equal(t, "new.txt", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("new file is not polled")
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("changed"), 0o644))
+	select {
+	case name := <-events:
+		require.Equal(t, "existing.txt", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("changed file is not polled")
+	}
+}
@@ -0,0 +1,144 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"go.uber.org/zap"
+)
+
+// Delivery request headers.
+const (
+	HeaderEvent     = "X-Scripts-Hub-Event"
+	HeaderDelivery  = "X-Scripts-Hub-Delivery"
+	HeaderSignature = "X-Scripts-Hub-Signature-256"
+)
+
+// Dispatcher limits.
+const (
+	dispatchBatch  = 100
+	requestTimeout = 10 * time.Second
+	claimLease     = time.Minute
+)
+
+// Dispatcher contains objects for delivering the events from the outbox to the webhooks.
+type Dispatcher struct {
+	service  Service
+	client   *http.Client
+	secret   string
+	interval time.Duration
+}
+
+// NewDispatcher returns new dispatcher sending the due deliveries every interval
+// with the payloads signed by the secret.
+func NewDispatcher(ctx context.Context, service Service, secret string, interval time.Duration) *Dispatcher {
+	return &Dispatcher{
+		service:  service,
+		client:   &http.Client{Timeout: requestTimeout},
+		secret:   secret,
+		interval: interval,
+	}
+}
+
+// Run sends the due deliveries until the context is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			d.Dispatch(ctx, now)
+		}
+	}
+}
+
+// Dispatch sends the deliveries which attempt time has come at now in parallel
+// and stores the results of the attempts.
+func (d *Dispatcher) Dispatch(ctx context.Context, now time.Time) {
+	deliveries, err := d.service.Claim(ctx, now, claimLease, dispatchBatch)
+	if err != nil {
+		logger.Log.Error("Dispatch: claim due deliveries failed",
+			zap.Error(err))
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, dl := range deliveries {
+		wg.Add(1)
+		go func(dl *entities.Delivery) {
+			defer wg.Done()
+			d.deliver(ctx, dl)
+		}(dl)
+	}
+	wg.Wait()
+}
+
+// deliver sends the delivery and stores the result of the attempt.
+func (d *Dispatcher) deliver(ctx context.Context, dl *entities.Delivery) {
+	err := d.send(ctx, dl)
+	if err != nil {
+		logger.Log.Warn("deliver: webhook delivery attempt failed",
+			zap.Error(err), zap.Int("delivery_id", dl.ID), zap.String("url", dl.URL))
+
+		err = d.service.Failed(context.Background(), dl, err, time.Now())
+		if err != nil {
+			logger.Log.Error("deliver: store failed attempt failed",
+				zap.Error(err), zap.Int("delivery_id", dl.ID))
+		}
+		return
+	}
+
+	err = d.service.Delivered(context.Background(), dl, time.Now())
+	if err != nil {
+		logger.Log.Error("deliver: store delivered attempt failed",
+			zap.Error(err), zap.Int("delivery_id", dl.ID))
+	}
+}
+
+// send posts the signed payload of the delivery to its URL,
+// only the successful response status means the delivery.
+func (d *Dispatcher) send(ctx context.Context, dl *entities.Delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.URL, bytes.NewReader(dl.Payload))
+	if err != nil {
+		return fmt.Errorf("send: create request failed %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, dl.Event)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(dl.ID))
+	req.Header.Set(HeaderSignature, Sign(d.secret, dl.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send: post payload failed %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("send: unexpected response status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign returns the HMAC-SHA256 signature of the payload with the secret
+// in the form "sha256=<hex>".
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	got := Sign("secret", []byte(`{"type":"run.succeeded"}`))
+	require.Equal(t, "sha256=434f02c4e504f50d36cb18b6c04df0acd87ebc2ea48a9133c96f3d6861f243a9", got)
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, time.April, 26, 10, 0, 0, 0, time.UTC)
+	payload := []byte(`{"type":"run.succeeded","run_id":1}`)
+
+	tests := []struct {
+		name       string
+		respCode   int
+		wantStatus string
+	}{
+		{
+			name:       "delivered",
+			respCode:   http.StatusNoContent,
+			wantStatus: entities.DeliveryDelivered,
+		},
+		{
+			name:       "receiver_failed",
+			respCode:   http.StatusBadGateway,
+			wantStatus: entities.DeliveryPending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				require.Equal(t, payload, body)
+				require.Equal(t, "run.succeeded", r.Header.Get(HeaderEvent))
+				require.Equal(t, "1", r.Header.Get(HeaderDelivery))
+				require.Equal(t, Sign("secret", body), r.Header.Get(HeaderSignature))
+
+				w.WriteHeader(tt.respCode)
+			}))
+			defer srv.Close()
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockRepo := mocks.NewMockRepository(mockCtrl)
+
+			mockRepo.EXPECT().ClaimDueDeliveries(gomock.Any(), now, gomock.Any(), gomock.Any()).
+				Return([]*entities.Delivery{
+					{ID: 1, URL: srv.URL, Event: "run.succeeded", Payload: payload,
+						Status: entities.DeliveryPending, NextAttemptAt: now},
+				}, nil).Times(1)
+
+			var got *entities.Delivery
+			mockRepo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, d *entities.Delivery) error {
+					got = d
+					return nil
+				}).Times(1)
+
+			s := NewWebhookService(ctx, mockRepo, nil, 0)
+			NewDispatcher(ctx, s, "secret", time.Second).Dispatch(ctx, now)
+
+			require.NotNil(t, got)
+			require.Equal(t, tt.wantStatus, got.Status)
+			require.Equal(t, 1, got.Attempts)
+		})
+	}
+}
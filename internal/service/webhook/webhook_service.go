@@ -0,0 +1,205 @@
+// Package webhook contains webhook service object and methods for storing
+// the run events into the outbox and the dispatcher delivering them
+// to the webhook URLs.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	repo "github.com/pavlegich/scripts-hub/internal/repository"
+)
+
+// Delivery retry limits and defaults.
+const (
+	DefaultMaxAttempts = 5
+	DeliveryBackoff    = 5 * time.Second
+	DeliveryMaxBackoff = time.Hour
+)
+
+// Service describes methods for communication between
+// handlers and repositories for the webhooks.
+//
+//go:generate mockgen -destination=../../mocks/mock_WebhookService.go -package=mocks -mock_names=Service=MockWebhookService github.com/pavlegich/scripts-hub/internal/service/webhook Service
+type Service interface {
+	Notify(ctx context.Context, run *entities.Run, urls []string) error
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.Delivery, error)
+	Delivered(ctx context.Context, delivery *entities.Delivery, now time.Time) error
+	Failed(ctx context.Context, delivery *entities.Delivery, reason error, now time.Time) error
+	ListDead(ctx context.Context) ([]*entities.Delivery, error)
+	Replay(ctx context.Context, id int) error
+}
+
+// WebhookService contains objects for webhook service.
+type WebhookService struct {
+	repo        repo.Repository
+	urls        []string
+	maxAttempts int
+}
+
+// NewWebhookService returns new webhook service sending the events of all the commands
+// to the specified URLs in addition to the webhooks of the command.
+func NewWebhookService(ctx context.Context, repo repo.Repository, urls []string, maxAttempts int) *WebhookService {
+	if maxAttempts < 1 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	return &WebhookService{
+		repo:        repo,
+		urls:        urls,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// ValidateURL checks that the webhook URL is the absolute HTTP or HTTPS URL.
+func ValidateURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("ValidateURL: parse url failed %s %w", err, errs.ErrWebhookIncorrect)
+	}
+
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("ValidateURL: url %q is not absolute http url %w", u, errs.ErrWebhookIncorrect)
+	}
+
+	return nil
+}
+
+// Notify stores the event of the run state change into the outbox
+// for every webhook of the command and every global webhook.
+func (s *WebhookService) Notify(ctx context.Context, run *entities.Run, urls []string) error {
+	targets := make([]string, 0, len(urls)+len(s.urls))
+	seen := make(map[string]bool)
+	for _, u := range append(append([]string{}, urls...), s.urls...) {
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		targets = append(targets, u)
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	event := &entities.Event{
+		Type:      "run." + run.Status,
+		RunID:     run.ID,
+		Command:   run.Name,
+		Trigger:   run.Trigger,
+		Status:    run.Status,
+		Attempt:   run.Attempt,
+		ExitCode:  run.ExitCode,
+		Timestamp: now,
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("Notify: marshal event failed %w", err)
+	}
+
+	deliveries := make([]*entities.Delivery, 0, len(targets))
+	for _, u := range targets {
+		deliveries = append(deliveries, &entities.Delivery{
+			URL:           u,
+			Event:         event.Type,
+			Payload:       payload,
+			Status:        entities.DeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+
+	err = s.repo.CreateDeliveries(ctx, deliveries)
+	if err != nil {
+		return fmt.Errorf("Notify: create deliveries failed %w", err)
+	}
+
+	return nil
+}
+
+// Claim returns the pending deliveries which attempt time has come at now.
+// The claimed deliveries are not returned again until the lease expires.
+func (s *WebhookService) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.Delivery, error) {
+	deliveries, err := s.repo.ClaimDueDeliveries(ctx, now, lease, limit)
+	if err != nil {
+		return nil, fmt.Errorf("Claim: claim due deliveries failed %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Delivered stores the successful delivery attempt.
+func (s *WebhookService) Delivered(ctx context.Context, d *entities.Delivery, now time.Time) error {
+	d.Attempts++
+	d.Status = entities.DeliveryDelivered
+	d.LastError = ""
+	d.DeliveredAt = &now
+
+	err := s.repo.UpdateDelivery(ctx, d)
+	if err != nil {
+		return fmt.Errorf("Delivered: update delivery failed %w", err)
+	}
+
+	return nil
+}
+
+// Failed stores the failed delivery attempt. The delivery is attempted again
+// after the exponential backoff or moves to the dead letters
+// when the attempts are exhausted.
+func (s *WebhookService) Failed(ctx context.Context, d *entities.Delivery, reason error, now time.Time) error {
+	d.Attempts++
+	d.LastError = reason.Error()
+	if d.Attempts >= s.maxAttempts {
+		d.Status = entities.DeliveryDead
+	} else {
+		d.NextAttemptAt = now.Add(backoff(d.Attempts))
+	}
+
+	err := s.repo.UpdateDelivery(ctx, d)
+	if err != nil {
+		return fmt.Errorf("Failed: update delivery failed %w", err)
+	}
+
+	return nil
+}
+
+// ListDead returns the deliveries which attempts are exhausted.
+func (s *WebhookService) ListDead(ctx context.Context) ([]*entities.Delivery, error) {
+	deliveries, err := s.repo.GetDeadDeliveries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ListDead: get dead deliveries failed %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Replay moves the dead delivery back into the outbox to be attempted again.
+func (s *WebhookService) Replay(ctx context.Context, id int) error {
+	ok, err := s.repo.ReplayDelivery(ctx, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("Replay: replay delivery failed %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("Replay: dead delivery not found %w", errs.ErrDeliveryNotFound)
+	}
+
+	return nil
+}
+
+// backoff returns the delay before the attempt following the failed one,
+// it doubles with every attempt up to the max backoff.
+func backoff(attempt int) time.Duration {
+	delay := DeliveryBackoff
+	for i := 1; i < attempt && delay < DeliveryMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > DeliveryMaxBackoff {
+		delay = DeliveryMaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,166 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "https", url: "https://ci.local/hooks/scripts"},
+		{name: "http_with_port", url: "http://localhost:9000/hook"},
+		{name: "relative", url: "/hook", wantErr: true},
+		{name: "unsupported_scheme", url: "ftp://files.local/hook", wantErr: true},
+		{name: "incorrect", url: "http://%zz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateURL(tt.url)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrWebhookIncorrect)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWebhookService_Notify(t *testing.T) {
+	ctx := context.Background()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	exitCode := 1
+	run := &entities.Run{ID: 7, Name: "backup", Trigger: entities.TriggerAPI,
+		Status: entities.RunFailed, Attempt: 1, ExitCode: &exitCode}
+
+	tests := []struct {
+		name     string
+		global   []string
+		hooks    []string
+		wantURLs []string
+	}{
+		{
+			name: "no_webhooks",
+		},
+		{
+			name:     "command_and_global_webhooks",
+			global:   []string{"https://ops.local/hook", "https://ci.local/hook"},
+			hooks:    []string{"https://ci.local/hook"},
+			wantURLs: []string{"https://ci.local/hook", "https://ops.local/hook"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.wantURLs) > 0 {
+				mockRepo.EXPECT().CreateDeliveries(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, deliveries []*entities.Delivery) error {
+						urls := make([]string, 0, len(deliveries))
+						for _, d := range deliveries {
+							urls = append(urls, d.URL)
+							require.Equal(t, "run.failed", d.Event)
+							require.Equal(t, entities.DeliveryPending, d.Status)
+
+							var event entities.Event
+							require.NoError(t, json.Unmarshal(d.Payload, &event))
+							require.Equal(t, 7, event.RunID)
+							require.Equal(t, "backup", event.Command)
+							require.Equal(t, &exitCode, event.ExitCode)
+						}
+						require.Equal(t, tt.wantURLs, urls)
+						return nil
+					}).Times(1)
+			}
+
+			s := NewWebhookService(ctx, mockRepo, tt.global, 0)
+			err := s.Notify(ctx, run, tt.hooks)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWebhookService_Failed(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, time.April, 26, 10, 0, 0, 0, time.UTC)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	tests := []struct {
+		name       string
+		attempts   int
+		wantStatus string
+		wantNext   time.Time
+	}{
+		{
+			name:       "first_attempt_retried",
+			attempts:   0,
+			wantStatus: entities.DeliveryPending,
+			wantNext:   now.Add(DeliveryBackoff),
+		},
+		{
+			name:       "third_attempt_retried_with_backoff",
+			attempts:   2,
+			wantStatus: entities.DeliveryPending,
+			wantNext:   now.Add(4 * DeliveryBackoff),
+		},
+		{
+			name:       "attempts_exhausted",
+			attempts:   2,
+			wantStatus: entities.DeliveryDead,
+			wantNext:   now,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxAttempts := 5
+			if tt.wantStatus == entities.DeliveryDead {
+				maxAttempts = tt.attempts + 1
+			}
+
+			mockRepo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+			d := &entities.Delivery{ID: i + 1, Status: entities.DeliveryPending,
+				Attempts: tt.attempts, NextAttemptAt: now}
+			s := NewWebhookService(ctx, mockRepo, nil, maxAttempts)
+
+			err := s.Failed(ctx, d, errors.New("connection refused"), now)
+			require.NoError(t, err)
+			require.Equal(t, tt.attempts+1, d.Attempts)
+			require.Equal(t, tt.wantStatus, d.Status)
+			require.Equal(t, tt.wantNext, d.NextAttemptAt)
+			require.Equal(t, "connection refused", d.LastError)
+		})
+	}
+}
+
+func TestWebhookService_Replay(t *testing.T) {
+	ctx := context.Background()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockRepo := mocks.NewMockRepository(mockCtrl)
+
+	mockRepo.EXPECT().ReplayDelivery(gomock.Any(), 1, gomock.Any()).Return(true, nil).Times(1)
+	mockRepo.EXPECT().ReplayDelivery(gomock.Any(), 2, gomock.Any()).Return(false, nil).Times(1)
+
+	s := NewWebhookService(ctx, mockRepo, nil, 0)
+	require.NoError(t, s.Replay(ctx, 1))
+	require.ErrorIs(t, s.Replay(ctx, 2), errs.ErrDeliveryNotFound)
+}
@@ -0,0 +1,113 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+)
+
+// Validate checks that the workflow is the acyclic graph of the uniquely named nodes
+// with the known dependencies and fills the empty dependency conditions with "success".
+func Validate(wf *entities.Workflow) error {
+	if wf.Name == "" || len(wf.Nodes) == 0 {
+		return fmt.Errorf("Validate: workflow name or nodes empty %w", errs.ErrWorkflowIncorrect)
+	}
+
+	nodes := make(map[string]*entities.WorkflowNode, len(wf.Nodes))
+	for _, node := range wf.Nodes {
+		if node.Name == "" || node.Command == "" {
+			return fmt.Errorf("Validate: node name or command empty %w", errs.ErrWorkflowIncorrect)
+		}
+		if _, ok := nodes[node.Name]; ok {
+			return fmt.Errorf("Validate: duplicate node %s %w", node.Name, errs.ErrWorkflowIncorrect)
+		}
+		nodes[node.Name] = node
+	}
+
+	for _, node := range wf.Nodes {
+		for _, dep := range node.Needs {
+			if _, ok := nodes[dep.Node]; !ok || dep.Node == node.Name {
+				return fmt.Errorf("Validate: node %s depends on unknown node %s %w",
+					node.Name, dep.Node, errs.ErrWorkflowIncorrect)
+			}
+
+			if dep.On == "" {
+				dep.On = entities.OnSuccess
+			}
+			switch dep.On {
+			case entities.OnSuccess, entities.OnFailure, entities.OnAlways:
+			default:
+				return fmt.Errorf("Validate: node %s has unknown condition %s %w",
+					node.Name, dep.On, errs.ErrWorkflowIncorrect)
+			}
+		}
+	}
+
+	if len(order(wf)) != len(wf.Nodes) {
+		return fmt.Errorf("Validate: workflow has dependency cycle %w", errs.ErrWorkflowIncorrect)
+	}
+
+	return nil
+}
+
+// order returns the nodes of the workflow in the topological order,
+// the nodes of the dependency cycles are not returned.
+func order(wf *entities.Workflow) []*entities.WorkflowNode {
+	indegree := make(map[string]int, len(wf.Nodes))
+	dependents := make(map[string][]*entities.WorkflowNode, len(wf.Nodes))
+	for _, node := range wf.Nodes {
+		for _, dep := range node.Needs {
+			indegree[node.Name]++
+			dependents[dep.Node] = append(dependents[dep.Node], node)
+		}
+	}
+
+	sorted := make([]*entities.WorkflowNode, 0, len(wf.Nodes))
+	for _, node := range wf.Nodes {
+		if indegree[node.Name] == 0 {
+			sorted = append(sorted, node)
+		}
+	}
+
+	for i := 0; i < len(sorted); i++ {
+		for _, next := range dependents[sorted[i].Name] {
+			indegree[next.Name]--
+			if indegree[next.Name] == 0 {
+				sorted = append(sorted, next)
+			}
+		}
+	}
+
+	return sorted
+}
+
+// conditionsMet reports whether the finished dependencies of the node
+// allow to run the node.
+func conditionsMet(node *entities.WorkflowNode, statuses map[string]string) bool {
+	for _, dep := range node.Needs {
+		status := statuses[dep.Node]
+		switch dep.On {
+		case entities.OnAlways:
+		case entities.OnFailure:
+			if status != entities.RunFailed {
+				return false
+			}
+		default:
+			if status != entities.RunSucceeded {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// dependenciesFinished reports whether all the dependencies of the node are finished.
+func dependenciesFinished(node *entities.WorkflowNode, statuses map[string]string) bool {
+	for _, dep := range node.Needs {
+		if _, ok := statuses[dep.Node]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	errs "github.com/pavlegich/scripts-hub/internal/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	node := func(name string, needs ...*entities.Dependency) *entities.WorkflowNode {
+		return &entities.WorkflowNode{Name: name, Command: name, Needs: needs}
+	}
+	dep := func(node, on string) *entities.Dependency {
+		return &entities.Dependency{Node: node, On: on}
+	}
+
+	tests := []struct {
+		name    string
+		wf      *entities.Workflow
+		wantErr bool
+	}{
+		{
+			name: "deploy",
+			wf: &entities.Workflow{Name: "deploy", Nodes: []*entities.WorkflowNode{
+				node("build"),
+				node("migrate", dep("build", "")),
+				node("restart", dep("migrate", entities.OnSuccess)),
+				node("rollback", dep("restart", entities.OnFailure)),
+				node("notify", dep("restart", entities.OnAlways)),
+			}},
+		},
+		{
+			name:    "no_nodes",
+			wf:      &entities.Workflow{Name: "empty"},
+			wantErr: true,
+		},
+		{
+			name: "duplicate_node",
+			wf: &entities.Workflow{Name: "dup", Nodes: []*entities.WorkflowNode{
+				node("build"), node("build"),
+			}},
+			wantErr: true,
+		},
+		{
+			name: "unknown_dependency",
+			wf: &entities.Workflow{Name: "unknown", Nodes: []*entities.WorkflowNode{
+				node("migrate", dep("build", "")),
+			}},
+			wantErr: true,
+		},
+		{
+			name: "unknown_condition",
+			wf: &entities.Workflow{Name: "condition", Nodes: []*entities.WorkflowNode{
+				node("build"), node("migrate", dep("build", "sometimes")),
+			}},
+			wantErr: true,
+		},
+		{
+			name: "cycle",
+			wf: &entities.Workflow{Name: "cycle", Nodes: []*entities.WorkflowNode{
+				node("build"),
+				node("first", dep("build", ""), dep("second", "")),
+				node("second", dep("first", "")),
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.wf)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrWorkflowIncorrect)
+				return
+			}
+			require.NoError(t, err)
+			for _, n := range tt.wf.Nodes {
+				for _, d := range n.Needs {
+					require.NotEmpty(t, d.On)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/infra/logger"
+	"go.uber.org/zap"
+)
+
+// Runner describes methods for running the saved commands on the worker pool
+// and waiting for their completion.
+type Runner interface {
+	Submit(ctx context.Context, name string, opts entities.SubmitOptions) (*entities.Run, error)
+	Wait(ctx context.Context, id int) (*entities.Run, error)
+}
+
+// Executor contains objects for running the workflows.
+type Executor struct {
+	ctx     context.Context
+	service Service
+	runner  Runner
+}
+
+// nodeResult contains the final status of the workflow node.
+type nodeResult struct {
+	node   string
+	status string
+}
+
+// NewExecutor returns new executor running the workflows until the context is done.
+func NewExecutor(ctx context.Context, service Service, runner Runner) *Executor {
+	return &Executor{
+		ctx:     ctx,
+		service: service,
+		runner:  runner,
+	}
+}
+
+// Start creates new run of the workflow and executes it in background.
+func (e *Executor) Start(ctx context.Context, wf *entities.Workflow) (*entities.WorkflowRun, error) {
+	run, err := e.service.CreateRun(ctx, wf)
+	if err != nil {
+		return nil, fmt.Errorf("Start: %w", err)
+	}
+
+	go e.Execute(e.ctx, wf, run)
+
+	return run, nil
+}
+
+// Execute runs the nodes of the workflow as soon as their dependencies are finished,
+// the independent nodes run in parallel. The node which dependency conditions
+// are not met is skipped. The workflow run fails if any of its nodes fails.
+func (e *Executor) Execute(ctx context.Context, wf *entities.Workflow, run *entities.WorkflowRun) {
+	nodeRuns := make(map[string]*entities.NodeRun, len(run.Nodes))
+	for _, nr := range run.Nodes {
+		nodeRuns[nr.Node] = nr
+	}
+
+	statuses := make(map[string]string, len(wf.Nodes))
+	started := make(map[string]bool, len(wf.Nodes))
+	results := make(chan nodeResult)
+	running := 0
+
+	for {
+		for progress := true; progress; {
+			progress = false
+			for _, node := range wf.Nodes {
+				if started[node.Name] || !dependenciesFinished(node, statuses) {
+					continue
+				}
+				started[node.Name] = true
+
+				if !conditionsMet(node, statuses) {
+					statuses[node.Name] = entities.RunSkipped
+					e.updateNode(ctx, run, nodeRuns[node.Name], entities.RunSkipped, nil)
+					progress = true
+					continue
+				}
+
+				running++
+				go func(node *entities.WorkflowNode) {
+					results <- nodeResult{
+						node:   node.Name,
+						status: e.runNode(ctx, run, nodeRuns[node.Name]),
+					}
+				}(node)
+			}
+		}
+
+		if running == 0 {
+			break
+		}
+
+		res := <-results
+		running--
+		statuses[res.node] = res.status
+	}
+
+	run.Status = entities.RunSucceeded
+	for _, status := range statuses {
+		if status == entities.RunFailed || status == entities.RunCancelled {
+			run.Status = entities.RunFailed
+		}
+	}
+
+	err := e.service.FinishRun(context.Background(), run)
+	if err != nil {
+		logger.Log.With(zap.String("workflow", wf.Name)).Error("Execute: finish workflow run failed",
+			zap.Error(err), zap.Int("workflow_run_id", run.ID))
+	}
+}
+
+// runNode runs the command of the node on the worker pool, waits for its completion
+// and returns the final status of the node.
+func (e *Executor) runNode(ctx context.Context, run *entities.WorkflowRun, nr *entities.NodeRun) string {
+	cmdRun, err := e.runner.Submit(ctx, nr.Command, entities.SubmitOptions{
+		Trigger: entities.TriggerWorkflow,
+		Overlap: entities.OverlapQueue,
+	})
+	if err != nil {
+		logger.Log.With(zap.String("workflow", run.Name)).Error("runNode: submit node command failed",
+			zap.Error(err), zap.String("node", nr.Node), zap.String("cmd_name", nr.Command))
+
+		e.updateNode(ctx, run, nr, entities.RunFailed, nil)
+		return entities.RunFailed
+	}
+	e.updateNode(ctx, run, nr, entities.RunRunning, &cmdRun.ID)
+
+	cmdRun, err = e.runner.Wait(ctx, cmdRun.ID)
+	if err != nil {
+		logger.Log.With(zap.String("workflow", run.Name)).Error("runNode: wait node command failed",
+			zap.Error(err), zap.String("node", nr.Node), zap.String("cmd_name", nr.Command))
+
+		e.updateNode(ctx, run, nr, entities.RunCancelled, nr.RunID)
+		return entities.RunCancelled
+	}
+
+	status := cmdRun.Status
+	switch status {
+	case entities.RunSucceeded, entities.RunFailed, entities.RunCancelled:
+	default:
+		status = entities.RunFailed
+	}
+	e.updateNode(ctx, run, nr, status, nr.RunID)
+
+	return status
+}
+
+// updateNode stores the status and the command run of the node in the workflow run.
+func (e *Executor) updateNode(ctx context.Context, run *entities.WorkflowRun, nr *entities.NodeRun,
+	status string, runID *int) {
+	nr.Status = status
+	nr.RunID = runID
+
+	err := e.service.UpdateNode(context.Background(), run, nr)
+	if err != nil {
+		logger.Log.With(zap.String("workflow", run.Name)).Error("updateNode: update node failed",
+			zap.Error(err), zap.String("node", nr.Node))
+	}
+}
@@ -0,0 +1,121 @@
+package workflow
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	"github.com/pavlegich/scripts-hub/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// runnerStub finishes the runs of the commands with the predefined statuses.
+type runnerStub struct {
+	mu        sync.Mutex
+	statuses  map[string]string
+	submitted []string
+	runs      map[int]*entities.Run
+}
+
+func (r *runnerStub) Submit(ctx context.Context, name string, opts entities.SubmitOptions) (*entities.Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.submitted = append(r.submitted, name)
+	run := &entities.Run{ID: len(r.submitted), Name: name, Trigger: opts.Trigger, Status: r.statuses[name]}
+	if run.Status == "" {
+		run.Status = entities.RunSucceeded
+	}
+	r.runs[run.ID] = run
+
+	return &entities.Run{ID: run.ID, Name: name, Status: entities.RunQueued}, nil
+}
+
+func (r *runnerStub) Wait(ctx context.Context, id int) (*entities.Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.runs[id], nil
+}
+
+func TestExecutor_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	wf := &entities.Workflow{ID: 1, Name: "deploy", Nodes: []*entities.WorkflowNode{
+		{Name: "build", Command: "build"},
+		{Name: "migrate", Command: "migrate", Needs: []*entities.Dependency{{Node: "build", On: entities.OnSuccess}}},
+		{Name: "lint", Command: "lint", Needs: []*entities.Dependency{{Node: "build", On: entities.OnSuccess}}},
+		{Name: "restart", Command: "restart", Needs: []*entities.Dependency{
+			{Node: "migrate", On: entities.OnSuccess}, {Node: "lint", On: entities.OnSuccess}}},
+		{Name: "rollback", Command: "rollback", Needs: []*entities.Dependency{{Node: "restart", On: entities.OnFailure}}},
+		{Name: "notify", Command: "notify", Needs: []*entities.Dependency{{Node: "restart", On: entities.OnAlways}}},
+	}}
+
+	tests := []struct {
+		name       string
+		statuses   map[string]string
+		wantNodes  map[string]string
+		wantStatus string
+	}{
+		{
+			name: "success",
+			wantNodes: map[string]string{
+				"build": entities.RunSucceeded, "migrate": entities.RunSucceeded, "lint": entities.RunSucceeded,
+				"restart": entities.RunSucceeded, "rollback": entities.RunSkipped, "notify": entities.RunSucceeded,
+			},
+			wantStatus: entities.RunSucceeded,
+		},
+		{
+			name:     "restart_failed",
+			statuses: map[string]string{"restart": entities.RunFailed},
+			wantNodes: map[string]string{
+				"build": entities.RunSucceeded, "migrate": entities.RunSucceeded, "lint": entities.RunSucceeded,
+				"restart": entities.RunFailed, "rollback": entities.RunSucceeded, "notify": entities.RunSucceeded,
+			},
+			wantStatus: entities.RunFailed,
+		},
+		{
+			name:     "build_failed",
+			statuses: map[string]string{"build": entities.RunFailed},
+			wantNodes: map[string]string{
+				"build": entities.RunFailed, "migrate": entities.RunSkipped, "lint": entities.RunSkipped,
+				"restart": entities.RunSkipped, "rollback": entities.RunSkipped, "notify": entities.RunSucceeded,
+			},
+			wantStatus: entities.RunFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockService := mocks.NewMockWorkflowService(mockCtrl)
+
+			mockService.EXPECT().UpdateNode(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).AnyTimes()
+			mockService.EXPECT().FinishRun(gomock.Any(), gomock.Any()).
+				Return(nil).Times(1)
+
+			runner := &runnerStub{statuses: tt.statuses, runs: make(map[int]*entities.Run)}
+			e := NewExecutor(ctx, mockService, runner)
+
+			run := &entities.WorkflowRun{ID: 1, WorkflowID: wf.ID, Name: wf.Name, Status: entities.RunRunning}
+			for _, n := range wf.Nodes {
+				run.Nodes = append(run.Nodes, &entities.NodeRun{Node: n.Name, Command: n.Command, Status: entities.NodeWaiting})
+			}
+
+			e.Execute(ctx, wf, run)
+
+			got := make(map[string]string, len(run.Nodes))
+			for _, nr := range run.Nodes {
+				got[nr.Node] = nr.Status
+				if nr.Status != entities.RunSkipped {
+					require.NotNil(t, nr.RunID, nr.Node)
+				}
+			}
+			require.Equal(t, tt.wantNodes, got)
+			require.Equal(t, tt.wantStatus, run.Status)
+			require.Equal(t, "build", runner.submitted[0])
+		})
+	}
+}
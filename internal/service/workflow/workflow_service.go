@@ -0,0 +1,151 @@
+// Package workflow contains workflow service object and methods for interaction
+// between handlers and repositories, validation of the workflow graphs
+// and the executor running the workflows on the worker pool.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pavlegich/scripts-hub/internal/entities"
+	repo "github.com/pavlegich/scripts-hub/internal/repository"
+)
+
+// Service describes methods for communication between
+// handlers and repositories for the workflows.
+//
+//go:generate mockgen -destination=../../mocks/mock_WorkflowService.go -package=mocks -mock_names=Service=MockWorkflowService github.com/pavlegich/scripts-hub/internal/service/workflow Service
+type Service interface {
+	Create(ctx context.Context, workflow *entities.Workflow) (int, error)
+	List(ctx context.Context) ([]*entities.Workflow, error)
+	Unload(ctx context.Context, name string) (*entities.Workflow, error)
+	Delete(ctx context.Context, name string) error
+
+	CreateRun(ctx context.Context, workflow *entities.Workflow) (*entities.WorkflowRun, error)
+	UpdateNode(ctx context.Context, run *entities.WorkflowRun, node *entities.NodeRun) error
+	FinishRun(ctx context.Context, run *entities.WorkflowRun) error
+	UnloadRun(ctx context.Context, id int) (*entities.WorkflowRun, error)
+}
+
+// WorkflowService contains objects for workflow service.
+type WorkflowService struct {
+	repo repo.Repository
+}
+
+// NewWorkflowService returns new workflow service.
+func NewWorkflowService(ctx context.Context, repo repo.Repository) *WorkflowService {
+	return &WorkflowService{
+		repo: repo,
+	}
+}
+
+// Create validates the workflow graph, checks that its commands exist
+// and requests repository to put it into the storage.
+func (s *WorkflowService) Create(ctx context.Context, wf *entities.Workflow) (int, error) {
+	err := Validate(wf)
+	if err != nil {
+		return -1, fmt.Errorf("Create: %w", err)
+	}
+
+	for _, node := range wf.Nodes {
+		_, err = s.repo.GetCommandByName(ctx, node.Command)
+		if err != nil {
+			return -1, fmt.Errorf("Create: get command of node %s failed %w", node.Name, err)
+		}
+	}
+
+	created, err := s.repo.CreateWorkflow(ctx, wf)
+	if err != nil {
+		return -1, fmt.Errorf("Create: create workflow failed %w", err)
+	}
+
+	return created.ID, nil
+}
+
+// List returns list of the stored workflows.
+func (s *WorkflowService) List(ctx context.Context) ([]*entities.Workflow, error) {
+	workflows, err := s.repo.GetAllWorkflows(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("List: get workflows list failed %w", err)
+	}
+
+	return workflows, nil
+}
+
+// Unload gets workflow by its name and returns it.
+func (s *WorkflowService) Unload(ctx context.Context, name string) (*entities.Workflow, error) {
+	wf, err := s.repo.GetWorkflowByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("Unload: get workflow failed %w", err)
+	}
+
+	return wf, nil
+}
+
+// Delete deletes the workflow with its runs from the storage.
+func (s *WorkflowService) Delete(ctx context.Context, name string) error {
+	err := s.repo.DeleteWorkflowByName(ctx, name)
+	if err != nil {
+		return fmt.Errorf("Delete: delete workflow failed %w", err)
+	}
+
+	return nil
+}
+
+// CreateRun creates new running workflow run with all its nodes waiting.
+func (s *WorkflowService) CreateRun(ctx context.Context, wf *entities.Workflow) (*entities.WorkflowRun, error) {
+	run := &entities.WorkflowRun{
+		WorkflowID: wf.ID,
+		Name:       wf.Name,
+		Status:     entities.RunRunning,
+		Nodes:      make([]*entities.NodeRun, 0, len(wf.Nodes)),
+	}
+	for _, node := range wf.Nodes {
+		run.Nodes = append(run.Nodes, &entities.NodeRun{
+			Node:    node.Name,
+			Command: node.Command,
+			Status:  entities.NodeWaiting,
+		})
+	}
+
+	run, err := s.repo.CreateWorkflowRun(ctx, run)
+	if err != nil {
+		return nil, fmt.Errorf("CreateRun: create workflow run failed %w", err)
+	}
+
+	return run, nil
+}
+
+// UpdateNode stores the status and the command run of the node in the workflow run.
+func (s *WorkflowService) UpdateNode(ctx context.Context, run *entities.WorkflowRun, node *entities.NodeRun) error {
+	err := s.repo.UpdateWorkflowNodeRun(ctx, run.ID, node)
+	if err != nil {
+		return fmt.Errorf("UpdateNode: update node run failed %w", err)
+	}
+
+	return nil
+}
+
+// FinishRun stores the final status of the workflow run finished at the current time.
+func (s *WorkflowService) FinishRun(ctx context.Context, run *entities.WorkflowRun) error {
+	now := time.Now()
+	run.FinishedAt = &now
+
+	err := s.repo.FinishWorkflowRun(ctx, run)
+	if err != nil {
+		return fmt.Errorf("FinishRun: finish workflow run failed %w", err)
+	}
+
+	return nil
+}
+
+// UnloadRun gets workflow run with the statuses of its nodes by its identifier and returns it.
+func (s *WorkflowService) UnloadRun(ctx context.Context, id int) (*entities.WorkflowRun, error) {
+	run, err := s.repo.GetWorkflowRunByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("UnloadRun: get workflow run failed %w", err)
+	}
+
+	return run, nil
+}